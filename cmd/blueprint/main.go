@@ -3,11 +3,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	"github.com/ale/blueprint/internal/cli"
+	"github.com/ale/blueprint/internal/config"
+	"github.com/ale/blueprint/internal/declarative"
+	"github.com/ale/blueprint/internal/doctor"
+	"github.com/ale/blueprint/internal/history"
 	"github.com/ale/blueprint/internal/module"
 	"github.com/ale/blueprint/internal/modules/bluefin_update"
 	"github.com/ale/blueprint/internal/modules/cedilla"
@@ -15,16 +23,17 @@ import (
 	"github.com/ale/blueprint/internal/modules/devbox"
 	"github.com/ale/blueprint/internal/modules/devcontainers"
 	"github.com/ale/blueprint/internal/modules/gnome_focus"
-	"github.com/ale/blueprint/internal/modules/tiling_shell"
 	"github.com/ale/blueprint/internal/modules/passwordless"
 	"github.com/ale/blueprint/internal/modules/starship"
+	"github.com/ale/blueprint/internal/modules/tiling_shell"
 	"github.com/ale/blueprint/internal/modules/usb_audio"
+	"github.com/ale/blueprint/internal/plugin"
 	"github.com/ale/blueprint/internal/system"
 )
 
 func main() {
 	// Descobre o diretorio do repo (onde o binario esta ou diretorio de trabalho)
-	repoDir := discoverRepoDir()
+	repoDir, repoSource := discoverRepoDir()
 
 	// Cria o sistema real
 	sys := system.NewReal()
@@ -49,28 +58,86 @@ func main() {
 	devboxScript := filepath.Join(repoDir, "configs", "devbox", "setup-dev.sh")
 	must(reg.Register(devbox.New(devboxScript)))
 
+	// Modulos declarativos (TOML) do repo e do usuario, e plugins externos
+	// (blueprint-module-* em ~/.config/blueprint/plugins ou no PATH). Um
+	// arquivo invalido ou plugin quebrado e pulado com aviso (e listado no
+	// doctor): nao pode impedir o uso da CLI.
+	ignored := declarative.Register(reg, sys, declarative.Discover(
+		filepath.Join(repoDir, "configs", "modules"),
+		filepath.Join(config.Dir(sys), "modules"),
+	))
+	ignored = append(ignored, plugin.Register(context.Background(), reg, sys, plugin.Discover(
+		[]string{filepath.Join(config.Dir(sys), "plugins")},
+		sys.Env("PATH"),
+	))...)
+	for _, err := range ignored {
+		fmt.Fprintf(os.Stderr, "Aviso: modulo ignorado: %v\n", err)
+	}
+
+	// Valida dependencias entre modulos (nomes desconhecidos e ciclos)
+	must(reg.Validate())
+
+	// Configuracao do usuario: perfil padrao, modulos e opcoes
+	cfg, err := config.Load(sys, config.DefaultPath(sys))
+	must(err)
+	must(cfg.RegisterProfiles())
+	must(cfg.Validate(reg))
+	must(cfg.Configure(reg))
+
 	// Configura a app
 	app := &cli.App{
 		Registry:  reg,
 		System:    sys,
 		Options:   &cli.Options{},
 		ConfigDir: filepath.Join(repoDir, "configs"),
+		Repo:      doctor.Repo{Dir: repoDir, Source: repoSource},
+		StateDir:  history.DefaultDir(sys),
+		Config:    cfg,
+		Ignored:   ignored,
 	}
 
 	// Executa
+	// Ctrl+C/SIGTERM cancelam o contexto: o modulo em andamento e
+	// interrompido (com o grupo de processos) e o resumo parcial e exibido.
+	// Depois do primeiro sinal, o comportamento padrao volta: um segundo
+	// Ctrl+C encerra na hora.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	cmd := cli.NewRootCmd(app)
-	if err := cmd.Execute(); err != nil {
+	err = cmd.ExecuteContext(ctx)
+
+	// Codigos especificos (status/apply): drift, modulos com erro etc.
+	code := 0
+	var exitErr *cli.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		code, err = exitErr.Code, exitErr.Err
+	case err != nil:
+		code = 1
+	}
+
+	// Registra o fim da execucao no log (codigo de saida e duracao)
+	if app.Log != nil {
+		app.Log.Close(code, err)
+	}
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
-		os.Exit(1)
 	}
+	os.Exit(code)
 }
 
-// discoverRepoDir tenta encontrar o diretorio raiz do repositorio.
+// discoverRepoDir tenta encontrar o diretorio raiz do repositorio e diz de
+// onde ele veio (exibido pelo "blueprint doctor").
 // Prioridade: BLUEPRINT_DIR env > diretorio do executavel > ~/blueprint > diretorio atual.
-func discoverRepoDir() string {
+func discoverRepoDir() (string, string) {
 	// 1. Variavel de ambiente
 	if dir := os.Getenv("BLUEPRINT_DIR"); dir != "" {
-		return dir
+		return dir, doctor.RepoFromEnv
 	}
 
 	// 2. Diretorio do executavel
@@ -81,11 +148,11 @@ func discoverRepoDir() string {
 		if filepath.Base(exeDir) == "bin" {
 			parent := filepath.Dir(exeDir)
 			if isRepoDir(parent) {
-				return parent
+				return parent, doctor.RepoFromExe
 			}
 		}
 		if isRepoDir(exeDir) {
-			return exeDir
+			return exeDir, doctor.RepoFromExe
 		}
 	}
 
@@ -94,13 +161,13 @@ func discoverRepoDir() string {
 	if home != "" {
 		blueprintDir := filepath.Join(home, "blueprint")
 		if isRepoDir(blueprintDir) {
-			return blueprintDir
+			return blueprintDir, doctor.RepoFromHome
 		}
 	}
 
 	// 4. Diretorio atual
 	cwd, _ := os.Getwd()
-	return cwd
+	return cwd, doctor.RepoFromCwd
 }
 
 // isRepoDir verifica se o diretorio parece ser a raiz do repo.
@@ -1,11 +1,17 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/ale/blueprint/internal/history"
+	"github.com/ale/blueprint/internal/module"
 	"github.com/ale/blueprint/internal/orchestrator"
 	"github.com/ale/blueprint/internal/profile"
-	"github.com/ale/blueprint/internal/system"
+	"github.com/ale/blueprint/internal/report"
 	"github.com/ale/blueprint/internal/tui"
 	"github.com/spf13/cobra"
 )
@@ -14,78 +20,170 @@ func newApplyCmd(app *App) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "apply [perfil]",
 		Short: "Aplicar configuracoes do perfil selecionado",
-		Long:  "Aplica todos os modulos do perfil. Sem argumentos, detecta o perfil automaticamente.",
-		Args:  cobra.MaximumNArgs(1),
+		Long: "Aplica todos os modulos do perfil. Sem argumentos, detecta o perfil automaticamente.\n\n" +
+			"Codigos de saida (headless): 0 sucesso, 1 falha do blueprint, 2 modulo ausente ou parcial apos o apply, " +
+			"3 modulo com erro, 4 todos os modulos pulados. --fail-on escolhe quais condicoes contam (padrao: error).",
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Perfil via argumento tem prioridade
-			if len(args) > 0 {
-				app.Options.Profile = args[0]
-			}
-
-			// Resolve perfil: auto-detecta ou usa o explicito
-			autoDetected := false
-			var prof profile.Profile
-			if app.Options.Profile == "auto" {
-				prof = profile.Detect(app.System)
-				autoDetected = true
-			} else {
-				var err error
-				prof, err = profile.ByName(app.Options.Profile)
-				if err != nil {
+			format, err := app.outputFormat()
+			if err != nil {
+				return err
+			}
+			failOn, err := report.ParseFailOn(app.Options.FailOn)
+			if err != nil {
+				return err
+			}
+
+			// --resume retoma os modulos adiados pela fase anterior
+			var resumed *resumedRun
+			if app.Options.Resume {
+				if resumed, err = app.loadResume(cmd.Context(), args); err != nil {
 					return err
 				}
+				args = []string{resumed.state.Profile}
+			}
+
+			// --set vale sobre o config.toml
+			if err := app.applySetFlags(); err != nil {
+				return err
+			}
+
+			// Perfil via argumento tem prioridade; "auto" detecta
+			prof, autoDetected, err := app.selectProfile(args)
+			if err != nil {
+				return err
 			}
 
-			modules := profile.Resolve(prof, app.Registry)
+			modules, err := app.resolveOnly(prof)
+			if err != nil {
+				return err
+			}
 
 			if len(modules) == 0 {
+				if format != report.FormatText {
+					return report.Write(os.Stdout, format, report.New("apply", app.System, prof.Name, autoDetected, hostname(), nil))
+				}
 				fmt.Println("Nenhum modulo encontrado para o perfil:", prof.Name)
 				return nil
 			}
 
-			// Sudo interativo: pede senha antes de iniciar TUI/headless
-			if !app.Options.DryRun && !app.System.IsContainer() && hasSystemModules(modules) {
-				ensureSudo()
+			// Configura dry-run se necessario
+			sys := app.runSystem()
+
+			// Acesso root: pede a senha antes de iniciar TUI/headless e a
+			// mantem valida ate o fim (ex: apos um bluefin-update demorado)
+			if !app.System.IsContainer() && hasSystemModules(modules) {
+				defer app.ensurePrivileges(cmd.Context(), sys)()
 			}
 
-			// Configura dry-run se necessario
-			sys := app.System
-			if app.Options.DryRun {
-				sys = system.NewDryRun(app.System, func(msg string) {
-					fmt.Println(msg)
-				})
+			// Historico: registra cada modulo finalizado e faz backup dos
+			// arquivos alterados (exceto em dry-run)
+			run := app.startRun(history.ActionApply, prof.Name)
+			if sys, err = app.withBackup(sys, run); err != nil {
+				return err
+			}
+			defer app.saveRun(run, sys)
+			var observers []orchestrator.Observer
+			if run != nil {
+				observers = append(observers, run.Observer())
 			}
 
-			mode := DetectMode(app.Options.Headless)
+			// --output json|yaml implica headless
+			mode := DetectMode(app.Options.Headless || format != report.FormatText)
 
 			if mode == Interactive {
-				return tui.Run(app.Registry, sys, prof, autoDetected)
+				// Guarda os resultados para a retomada e o --reboot/--logout ao sair do TUI
+				var results []orchestrator.Result
+				observers = append(observers, func(ev orchestrator.Event) {
+					if ev.Kind == orchestrator.ModuleFinished {
+						results = append(results, ev.Result)
+					}
+				})
+				opts := tui.Options{
+					Jobs:      app.Options.Jobs,
+					Observers: observers,
+					Resolve:   app.resolveSelected,
+					Timeout:   app.Options.Timeout,
+					Logger:    app.logger(),
+					LogPath:   app.logPath(),
+					Pending:   app.pendingSession,
+				}
+				if resumed != nil {
+					opts.Previous = resumed.previous
+				}
+				app.muteLog()
+				// Run so retorna depois que a execucao termina (mesmo num
+				// segundo Ctrl+C): nenhum observer escreve mais em results
+				err := tui.Run(cmd.Context(), app.Registry, sys, prof, autoDetected, opts)
+				app.saveResume(cmd.Context(), resumed, prof.Name, run, results)
+				if err != nil && !errors.Is(err, tui.ErrFailed) {
+					return tuiExit(err)
+				}
+				// Mesmo contrato de saida do headless: o codigo vem dos resultados
+				code := report.ExitCode(results, failOn)
+				if code != report.ExitOK {
+					return exitWith(code, applyExitError(code, results))
+				}
+				return app.finishSession(cmd.Context(), app.sessionActions(cmd.Context(), results), code)
 			}
 
-			// Modo headless
-			reporter := tui.NewHeadlessReporter()
-			orch := orchestrator.New(sys, reporter)
+			// Modo headless (texto vai para o stderr se o stdout e do report)
+			out := app.humanOut()
+			reporter := tui.NewHeadlessReporterTo(out)
+			orch := app.newOrchestrator(sys, reporter)
+			orch.SetJobs(app.Options.Jobs)
+			for _, obs := range observers {
+				orch.Observe(obs)
+			}
 
-			fmt.Printf("Aplicando perfil: %s (%d modulos)\n", prof.Name, len(modules))
-			fmt.Println()
+			resumed.printPrevious(out)
+			fmt.Fprintf(out, "Aplicando perfil: %s (%d modulos)\n", prof.Name, len(modules))
+			fmt.Fprintln(out)
 
 			results := orch.Run(cmd.Context(), modules)
+			code := report.ExitCode(results, failOn)
+			reqs := app.sessionActions(cmd.Context(), results)
 
-			// Resumo
+			if format != report.FormatText {
+				phases := app.saveResume(cmd.Context(), resumed, prof.Name, run, results)
+				rep := report.New("apply", app.System, prof.Name, autoDetected, hostname(), results)
+				rep.DryRun = app.Options.DryRun
+				rep.ExitCode = code
+				rep.LogFile = app.logPath()
+				rep.Session = report.SessionActions(reqs)
+				rep.Resume = phases
+				if err := report.Write(os.Stdout, format, rep); err != nil {
+					return err
+				}
+				if code != report.ExitOK {
+					return exitWith(code, applyExitError(code, results))
+				}
+				return app.finishSession(cmd.Context(), reqs, code)
+			}
+
+			// Resumo (parcial se a execucao foi cancelada)
 			fmt.Println()
-			fmt.Println("=== Resumo ===")
-			var errs int
+			printSummaryHeader(cmd.Context())
 			for _, r := range results {
 				icon := "OK"
-				if r.Skipped {
+				if r.Cancelled {
+					icon = "CANCELADO"
+				} else if r.Deferred {
+					icon = "ADIADO"
+				} else if r.TimedOut {
+					icon = "TIMEOUT"
+				} else if r.Skipped {
 					icon = "SKIP"
 				} else if r.Err != nil {
 					icon = "ERRO"
-					errs++
 				} else if r.Applied {
 					icon = "APLICADO"
 				}
-				fmt.Printf("  [%s] %s\n", icon, r.Module.Name())
+				if n := len(r.Retries); n > 0 {
+					fmt.Printf("  [%s] %s (%d nova(s) tentativa(s))\n", icon, r.Module.Name(), n)
+				} else {
+					fmt.Printf("  [%s] %s\n", icon, r.Module.Name())
+				}
 			}
 
 			// Notas pos-apply (instrucoes importantes para o usuario)
@@ -100,16 +198,147 @@ func newApplyCmd(app *App) *cobra.Command {
 					fmt.Printf("  %s\n", note)
 				}
 			}
+			printSession(reqs)
+			app.saveResume(cmd.Context(), resumed, prof.Name, run, results)
+			app.printLogPath(os.Stdout)
 
-			if errs > 0 {
-				return fmt.Errorf("%d modulo(s) com erro", errs)
+			if code != report.ExitOK {
+				return exitWith(code, applyExitError(code, results))
 			}
 
 			fmt.Println()
 			fmt.Println("Concluido!")
-			return nil
+			return app.finishSession(cmd.Context(), reqs, code)
 		},
 	}
 
+	cmd.Flags().StringVarP(&app.Options.Output, "output", "o", "text", "Formato da saida: text, json ou yaml (json/yaml implicam --headless)")
+	cmd.Flags().StringSliceVar(&app.Options.FailOn, "fail-on", []string{report.FailError},
+		"Condicoes que geram codigo de saida diferente de zero: partial, missing, error ou none")
+	cmd.Flags().StringSliceVar(&app.Options.Only, "only", nil, "Aplicar apenas estes modulos do perfil (separados por virgula)")
+	cmd.Flags().IntVarP(&app.Options.Jobs, "jobs", "j", 1, "Numero de modulos executados em paralelo (respeita dependencias e recursos exclusivos)")
+	cmd.Flags().BoolVar(&app.Options.Reboot, "reboot", false, "Reiniciar ao final se algum modulo ou um deployment rpm-ostree pendente exigir")
+	cmd.Flags().BoolVar(&app.Options.Resume, "resume", false, "Continuar um apply adiado pelo reboot (modulos que dependiam de um modulo que pediu reboot)")
+	cmd.Flags().BoolVar(&app.Options.Logout, "logout", false, "Fazer logout ao final se algum modulo exigir logout/login")
+	cmd.Flags().StringArrayVar(&app.Options.Set, "set", nil, "Opcao de modulo no formato modulo.chave=valor (repetivel, sobrepoe o config.toml)")
+
 	return cmd
 }
+
+// resolveOnly resolve os modulos do perfil e, com --only, mantem apenas os
+// pedidos. Nomes desconhecidos ou fora do perfil sao erro.
+func (app *App) resolveOnly(prof profile.Profile) ([]module.Module, error) {
+	modules := app.resolve(prof)
+	if len(app.Options.Only) == 0 {
+		return modules, nil
+	}
+	inProfile := make(map[string]bool)
+	for _, m := range modules {
+		inProfile[m.Name()] = true
+	}
+	for _, name := range app.Options.Only {
+		if _, ok := app.Registry.ByName(name); !ok {
+			return nil, fmt.Errorf("modulo desconhecido: %s", name)
+		}
+		if !inProfile[name] {
+			return nil, fmt.Errorf("modulo %s nao faz parte do perfil %s", name, prof.Name)
+		}
+	}
+	return app.resolveSelected(prof), nil
+}
+
+// resolveSelected e app.resolve filtrado por --only (usado tambem pelo TUI).
+func (app *App) resolveSelected(prof profile.Profile) []module.Module {
+	modules := app.resolve(prof)
+	if len(app.Options.Only) == 0 {
+		return modules
+	}
+	only := make(map[string]bool)
+	for _, name := range app.Options.Only {
+		only[name] = true
+	}
+	var selected []module.Module
+	for _, m := range modules {
+		if only[m.Name()] {
+			selected = append(selected, m)
+		}
+	}
+	return selected
+}
+
+// applyExitError descreve o motivo de um codigo de saida do apply.
+func applyExitError(code int, results []orchestrator.Result) error {
+	switch code {
+	case report.ExitModuleError:
+		var errs int
+		for _, r := range results {
+			if !r.Skipped && r.Err != nil {
+				errs++
+			}
+		}
+		return fmt.Errorf("%d modulo(s) com erro", errs)
+	case report.ExitDrift:
+		return fmt.Errorf("modulo(s) ausentes ou parciais apos o apply")
+	case report.ExitSkippedOnly:
+		return fmt.Errorf("todos os modulos foram pulados")
+	case report.ExitCancelled:
+		return tui.ErrCancelled
+	default:
+		return nil
+	}
+}
+
+// printSummaryHeader imprime o titulo do resumo, marcando-o como parcial
+// quando a execucao foi cancelada (Ctrl+C/SIGTERM).
+func printSummaryHeader(ctx context.Context) {
+	if ctx.Err() != nil {
+		fmt.Println("=== Resumo (parcial: execucao cancelada) ===")
+		return
+	}
+	fmt.Println("=== Resumo ===")
+}
+
+// applySetFlags aplica as opcoes passadas via --set modulo.chave=valor,
+// validando cada valor pelo schema do modulo.
+func (app *App) applySetFlags() error {
+	raw := make(map[string]map[string]any)
+	var order []string
+	for _, s := range app.Options.Set {
+		name, key, value, err := parseSetFlag(s)
+		if err != nil {
+			return err
+		}
+		if raw[name] == nil {
+			raw[name] = make(map[string]any)
+			order = append(order, name)
+		}
+		raw[name][key] = value
+	}
+
+	for _, name := range order {
+		m, ok := app.Registry.ByName(name)
+		if !ok {
+			return fmt.Errorf("--set: modulo desconhecido: %s", name)
+		}
+		configurable, ok := m.(module.Configurable)
+		if !ok {
+			return fmt.Errorf("--set: modulo %s nao aceita opcoes", name)
+		}
+		if err := module.SetOptions(configurable, raw[name]); err != nil {
+			return fmt.Errorf("--set %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// parseSetFlag separa "modulo.chave=valor". O valor pode conter "=" e ".".
+func parseSetFlag(s string) (name, key, value string, err error) {
+	left, value, ok := strings.Cut(s, "=")
+	if ok {
+		name, key, ok = strings.Cut(left, ".")
+	}
+	if !ok || name == "" || key == "" {
+		return "", "", "", fmt.Errorf("--set %q: formato esperado modulo.chave=valor", s)
+	}
+	return name, key, value, nil
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Consultar a configuracao do usuario (config.toml)",
+	}
+	cmd.AddCommand(newConfigShowCmd(app))
+	return cmd
+}
+
+func newConfigShowCmd(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Mostrar a configuracao efetiva (config.toml mesclado com os padroes)",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			eff := app.Config.Effective(app.Registry)
+
+			if path := app.Config.Path(); path != "" {
+				fmt.Printf("# fonte: %s\n", path)
+			} else {
+				fmt.Println("# nenhum config.toml encontrado — valores padrao")
+			}
+			fmt.Println()
+
+			out, err := eff.Encode()
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ale/blueprint/internal/doctor"
+	"github.com/ale/blueprint/internal/report"
+	"github.com/spf13/cobra"
+)
+
+func newDoctorCmd(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnosticar o ambiente (sudo, GNOME, containers, rede, diretorios)",
+		Long: "Verifica tudo de que os modulos dependem e sugere o que fazer em cada problema. " +
+			"Sai com codigo 1 se alguma verificacao falhar (avisos nao contam).",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			findings := doctor.Run(cmd.Context(), app.System, doctor.Options{
+				Repo:     app.Repo,
+				StateDir: app.StateDir,
+				Ignored:  app.Ignored,
+			})
+
+			fmt.Printf("\n%s%s blueprint doctor%s\n", colorBold, colorCyan, colorReset)
+			fmt.Printf("%s─────────────────────────────────────%s\n", colorDim, colorReset)
+
+			var warns, fails int
+			for _, f := range findings {
+				icon, color := doctorStyle(f.Severity)
+				fmt.Printf("  %s%s%s  %s%-*s%s  %s\n", color, icon, colorReset, colorBold, maxNameWidth, f.Check, colorReset, f.Message)
+				if f.Hint != "" {
+					fmt.Printf("     %s→ %s%s\n", colorDim, f.Hint, colorReset)
+				}
+				switch f.Severity {
+				case doctor.Warn:
+					warns++
+				case doctor.Fail:
+					fails++
+				}
+			}
+
+			fmt.Println()
+			switch {
+			case fails > 0:
+				fmt.Printf("  %s%d falha(s), %d aviso(s)%s\n\n", colorRed, fails, warns, colorReset)
+				return exitWith(report.ExitFailure, fmt.Errorf("%d verificacao(oes) falharam", fails))
+			case warns > 0:
+				fmt.Printf("  %s%d aviso(s) — alguns modulos podem ser pulados%s\n\n", colorYellow, warns, colorReset)
+			default:
+				fmt.Printf("  %sTudo certo.%s\n\n", colorGreen, colorReset)
+			}
+			return nil
+		},
+	}
+}
+
+func doctorStyle(s doctor.Severity) (string, string) {
+	switch s {
+	case doctor.OK:
+		return "✔", colorGreen
+	case doctor.Warn:
+		return "◐", colorYellow
+	default:
+		return "✘", colorRed
+	}
+}
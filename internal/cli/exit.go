@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ale/blueprint/internal/report"
+	"github.com/ale/blueprint/internal/tui"
+)
+
+// ExitError encerra o blueprint com um codigo de saida especifico (ver
+// report.Exit*). Err nil significa sair sem mensagem: a saida do comando
+// ja explicou o resultado.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("codigo de saida %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// exitWith retorna um ExitError para code, ou nil se code e zero.
+func exitWith(code int, err error) error {
+	if code == 0 {
+		return nil
+	}
+	return &ExitError{Code: code, Err: err}
+}
+
+// tuiExit converte o cancelamento do TUI no codigo de saida correspondente.
+func tuiExit(err error) error {
+	if errors.Is(err, tui.ErrCancelled) {
+		return &ExitError{Code: report.ExitCancelled, Err: err}
+	}
+	return err
+}
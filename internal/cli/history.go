@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ale/blueprint/internal/history"
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/system"
+	"github.com/spf13/cobra"
+)
+
+func newHistoryCmd(app *App) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Listar execucoes anteriores de apply/remove",
+		Long:  "Lista as execucoes registradas em $XDG_STATE_HOME/blueprint/history.jsonl. Use 'history show <id>' para ver o detalhe de uma execucao.",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			runs, err := app.history().List()
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				fmt.Println("Nenhuma execucao registrada.")
+				return nil
+			}
+
+			// Mais recentes primeiro
+			if limit > 0 && len(runs) > limit {
+				runs = runs[len(runs)-limit:]
+			}
+			for i := len(runs) - 1; i >= 0; i-- {
+				r := runs[i]
+				icon, color := "✔", colorGreen
+				if r.Failed() > 0 {
+					icon, color = "✘", colorRed
+				}
+				fmt.Printf("  %s%s%s  %s%s%s  %-6s  %-8s  %s  %s%s%s\n",
+					color, icon, colorReset,
+					colorBold, r.ID, colorReset,
+					r.Action, r.Profile,
+					summarizeRun(r),
+					colorDim, r.Version, colorReset)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Numero maximo de execucoes listadas (0 = todas)")
+	cmd.AddCommand(newHistoryShowCmd(app))
+
+	return cmd
+}
+
+func newHistoryShowCmd(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show [id]",
+		Short: "Mostrar o detalhe de uma execucao (padrao: a mais recente)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			id := "last"
+			if len(args) > 0 {
+				id = args[0]
+			}
+			r, err := app.history().Get(id)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("\n%s%s execucao %s%s\n", colorBold, colorCyan, r.ID, colorReset)
+			fmt.Printf("%s─────────────────────────────────────%s\n", colorDim, colorReset)
+			fmt.Printf("  Acao:      %s\n", r.Action)
+			if r.Profile != "" {
+				fmt.Printf("  Perfil:    %s\n", r.Profile)
+			}
+			fmt.Printf("  Versao:    %s (commit: %s)\n", r.Version, r.Commit)
+			fmt.Printf("  Inicio:    %s\n", r.StartedAt.Local().Format(time.DateTime))
+			fmt.Printf("  Duracao:   %s\n", r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond))
+			if r.Backups > 0 {
+				fmt.Printf("  Backups:   %d arquivo(s) — desfazer com: blueprint rollback %s\n", r.Backups, r.ID)
+			}
+			fmt.Println()
+
+			for _, e := range r.Entries {
+				icon, color, detail := entryStyle(e)
+				fmt.Printf("  %s%s%s  %s%-*s%s  %s  %s%s%s\n",
+					color, icon, colorReset,
+					colorBold, maxNameWidth, e.Module, colorReset,
+					detail,
+					colorDim, e.Duration.Round(time.Millisecond), colorReset)
+				if e.Before != nil {
+					fmt.Printf("      antes:  %s\n", formatStatus(e.Before))
+				}
+				if e.After != nil {
+					fmt.Printf("      depois: %s\n", formatStatus(e.After))
+				}
+				for _, rt := range e.Retries {
+					fmt.Printf("      %snova tentativa: %s (tentativa %d): %s%s\n", colorYellow, rt.Op, rt.Attempt, rt.Error, colorReset)
+				}
+				for _, note := range e.Notes {
+					fmt.Printf("      %s%s%s\n", colorDim, note, colorReset)
+				}
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+}
+
+// history retorna o journal de execucoes no diretorio de estado.
+func (app *App) history() *history.Store {
+	return history.NewStore(app.System, app.StateDir)
+}
+
+// startRun inicia o registro de uma execucao; nil em dry-run (nada e alterado).
+func (app *App) startRun(action, profile string) *history.Run {
+	if app.Options.DryRun {
+		return nil
+	}
+	return history.NewRun(action, profile)
+}
+
+// withBackup envolve sys com backup automatico ligado ao ID da execucao
+// (ver blueprint rollback). Sem execucao registrada (dry-run), retorna sys.
+func (app *App) withBackup(sys module.System, run *history.Run) (module.System, error) {
+	if run == nil {
+		return sys, nil
+	}
+	b, err := system.NewBackup(sys, app.backupDir(run.ID))
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// backupDir retorna o diretorio de backup de uma execucao.
+func (app *App) backupDir(runID string) string {
+	return filepath.Join(app.StateDir, "backups", runID)
+}
+
+// saveRun grava a execucao no historico. Falhas viram aviso: o apply ja
+// terminou e nao deve falhar por causa do registro.
+func (app *App) saveRun(run *history.Run, sys module.System) {
+	if run == nil || len(run.Entries) == 0 {
+		return
+	}
+	if b, ok := sys.(*system.Backup); ok {
+		run.Backups = len(b.Entries())
+	}
+	run.Finish()
+	if err := app.history().Append(*run); err != nil {
+		fmt.Fprintf(os.Stderr, "Aviso: %v\n", err)
+	}
+}
+
+// summarizeRun resume os resultados de uma execucao em uma linha.
+func summarizeRun(r history.Run) string {
+	var changed, skipped, deferred, cancelled int
+	for _, e := range r.Entries {
+		switch {
+		case e.Cancelled:
+			cancelled++
+		case e.Deferred:
+			deferred++
+		case e.Skipped:
+			skipped++
+		case e.Applied, e.Reverted:
+			changed++
+		}
+	}
+	parts := []string{fmt.Sprintf("%d modulos", len(r.Entries))}
+	if changed > 0 {
+		parts = append(parts, fmt.Sprintf("%d alterados", changed))
+	}
+	if skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d pulados", skipped))
+	}
+	if deferred > 0 {
+		parts = append(parts, fmt.Sprintf("%d adiados", deferred))
+	}
+	if failed := r.Failed(); failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d com erro", failed))
+	}
+	if cancelled > 0 {
+		parts = append(parts, fmt.Sprintf("%d cancelados", cancelled))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func entryStyle(e history.Entry) (icon, color, detail string) {
+	switch {
+	case e.TimedOut:
+		return "⏱", colorRed, e.Outcome()
+	case e.Error != "":
+		return "✘", colorRed, e.Outcome()
+	case e.Cancelled:
+		return "■", colorYellow, e.Outcome()
+	case e.Deferred:
+		return "↻", colorYellow, e.Outcome()
+	case e.Skipped:
+		return "⊘", colorDim, e.Outcome()
+	default:
+		return "✔", colorGreen, e.Outcome()
+	}
+}
+
+func formatStatus(s *history.Status) string {
+	if s.Message == "" {
+		return s.Kind
+	}
+	return fmt.Sprintf("%s (%s)", s.Kind, s.Message)
+}
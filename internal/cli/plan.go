@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ale/blueprint/internal/diff"
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+func newPlanCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "plan [perfil]",
+		Aliases: []string{"diff"},
+		Short:   "Mostrar o que o apply faria, modulo a modulo",
+		Long: "Verifica o estado real de cada modulo (somente leitura) e lista as acoes que o apply " +
+			"executaria: arquivos escritos, linhas adicionadas, comandos, chaves dconf e extensoes. " +
+			"Nada e alterado.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := app.applySetFlags(); err != nil {
+				return err
+			}
+			prof, _, err := app.selectProfile(args)
+			if err != nil {
+				return err
+			}
+			modules := app.resolve(prof)
+
+			// Usa o System real: o plano depende de leituras de verdade
+			plans := app.newOrchestrator(app.System, nil).Plan(cmd.Context(), modules)
+
+			fmt.Printf("\n%s%s blueprint plan%s  %sperfil: %s%s\n", colorBold, colorCyan, colorReset, colorDim, prof.Name, colorReset)
+			fmt.Printf("%s─────────────────────────────────────%s\n", colorDim, colorReset)
+
+			var actions, changed, unknown int
+			for _, p := range plans {
+				printPlan(app.System, p)
+				actions += len(p.Actions)
+				if len(p.Actions) > 0 {
+					changed++
+				}
+				if !p.Planned && !p.Skipped && p.Err == nil && p.Status.Kind != module.Installed {
+					unknown++
+				}
+			}
+
+			fmt.Println()
+			if actions == 0 && unknown == 0 {
+				fmt.Printf("  %sNada a fazer.%s\n\n", colorGreen, colorReset)
+				return nil
+			}
+			fmt.Printf("  %s%d acao(oes) em %d modulo(s)%s", colorBold, actions, changed, colorReset)
+			if unknown > 0 {
+				fmt.Printf("  %s(%d modulo(s) sem plano detalhado; use apply --dry-run)%s", colorDim, unknown, colorReset)
+			}
+			fmt.Println()
+			fmt.Println()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&app.Options.Set, "set", nil, "Opcao de modulo no formato modulo.chave=valor (repetivel, sobrepoe o config.toml)")
+
+	return cmd
+}
+
+// printPlan imprime o estado e as acoes planejadas de um modulo, com o diff
+// das acoes que alteram arquivos.
+func printPlan(sys module.System, p orchestrator.Plan) {
+	icon, color := statusStyle(p.Status.Kind)
+	name := fmt.Sprintf("%s%-*s%s", colorBold, maxNameWidth, p.Module.Name(), colorReset)
+
+	switch {
+	case p.Err != nil:
+		fmt.Printf("  %s✘%s  %s  %serro: %v%s\n", colorRed, colorReset, name, colorRed, p.Err, colorReset)
+	case p.Skipped:
+		fmt.Printf("  %s%s%s  %s  %spulado: %s%s\n", color, icon, colorReset, name, colorDim, p.Reason, colorReset)
+	case p.Status.Kind == module.Installed:
+		fmt.Printf("  %s%s%s  %s  %snada a fazer%s\n", color, icon, colorReset, name, colorDim, colorReset)
+	case !p.Planned:
+		fmt.Printf("  %s%s%s  %s  %s%s (sem plano detalhado)%s\n", color, icon, colorReset, name, colorDim, p.Status.Message, colorReset)
+	default:
+		fmt.Printf("  %s%s%s  %s  %s%s%s\n", color, icon, colorReset, name, colorDim, p.Status.Message, colorReset)
+		for _, a := range p.Actions {
+			fmt.Printf("       %s+%s %s\n", colorYellow, colorReset, a)
+			if text := planDiff(sys, a); text != "" {
+				for _, line := range strings.Split(strings.TrimSuffix(diff.Colorize(text), "\n"), "\n") {
+					fmt.Printf("         %s\n", line)
+				}
+			}
+		}
+	}
+}
+
+// planDiff retorna o diff unificado de uma acao que altera arquivo
+// (write-file ou append-line) contra o conteudo atual. Vazio para as demais.
+func planDiff(sys module.System, a module.Action) string {
+	if a.Kind != module.ActionWriteFile && a.Kind != module.ActionAppendLine {
+		return ""
+	}
+	oldName := a.Target
+	old, err := sys.ReadFile(a.Target)
+	if err != nil {
+		old, oldName = nil, "/dev/null"
+	}
+
+	content := a.Content
+	if a.Kind == module.ActionAppendLine {
+		s := string(old)
+		if len(s) > 0 && !strings.HasSuffix(s, "\n") {
+			s += "\n"
+		}
+		content = []byte(s + a.Value + "\n")
+	}
+	return diff.Unified(oldName, a.Target, old, content)
+}
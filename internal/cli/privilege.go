@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/system"
+)
+
+// ensurePrivileges autentica o backend de elevacao (--privilege) antes de
+// iniciar o TUI/headless — a senha do sudo e pedida uma vez, no terminal — e
+// renova a credencial ate o fim do comando. Retorna a funcao que para a
+// renovacao. Em dry-run nada e pedido: o DryRun so loga os comandos.
+func (app *App) ensurePrivileges(ctx context.Context, sys module.System) (stop func()) {
+	auth, ok := sys.(system.Authenticator)
+	if !ok {
+		return func() {}
+	}
+	if err := auth.Authenticate(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Aviso: acesso root indisponivel (%v) — modulos de sistema podem falhar\n\n", err)
+		return func() {}
+	}
+	return auth.KeepAlive(ctx)
+}
+
+// hasSystemModules retorna true se algum modulo tem a tag "system".
+func hasSystemModules(modules []module.Module) bool {
+	for _, m := range modules {
+		for _, tag := range m.Tags() {
+			if tag == "system" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ale/blueprint/internal/history"
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/report"
+	"github.com/ale/blueprint/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newRemoveCmd(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <modulo>...",
+		Short: "Desfazer as configuracoes de um ou mais modulos",
+		Long:  "Remove o que os modulos configuraram (arquivos, regras, links e linhas adicionadas), restaurando o estado anterior.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modules, err := modulesByName(app.Registry, args)
+			if err != nil {
+				return err
+			}
+
+			sys := app.runSystem()
+
+			// Acesso root: pede a senha antes de iniciar TUI/headless
+			if !app.System.IsContainer() && hasSystemModules(modules) {
+				defer app.ensurePrivileges(cmd.Context(), sys)()
+			}
+
+			// Historico: registra cada modulo finalizado e faz backup dos
+			// arquivos alterados (exceto em dry-run)
+			run := app.startRun(history.ActionRemove, "")
+			if sys, err = app.withBackup(sys, run); err != nil {
+				return err
+			}
+			defer app.saveRun(run, sys)
+			var observers []orchestrator.Observer
+			if run != nil {
+				observers = append(observers, run.Observer())
+			}
+
+			if DetectMode(app.Options.Headless) == Interactive {
+				app.muteLog()
+				return tuiExit(tui.RunRemove(cmd.Context(), modules, sys, tui.Options{
+					Observers: observers,
+					Timeout:   app.Options.Timeout,
+					Logger:    app.logger(),
+					LogPath:   app.logPath(),
+				}))
+			}
+
+			// Modo headless
+			reporter := tui.NewHeadlessReporter()
+			orch := app.newOrchestrator(sys, reporter)
+			for _, obs := range observers {
+				orch.Observe(obs)
+			}
+
+			fmt.Printf("Removendo %d modulo(s)\n", len(modules))
+			fmt.Println()
+
+			results := orch.Remove(cmd.Context(), modules)
+
+			// Resumo (parcial se a execucao foi cancelada)
+			fmt.Println()
+			printSummaryHeader(cmd.Context())
+			var errs int
+			for _, r := range results {
+				icon := "OK"
+				detail := "nada a remover"
+				switch {
+				case r.Cancelled:
+					icon, detail = "CANCELADO", r.Reason
+				case r.Skipped:
+					icon, detail = "SKIP", r.Reason
+				case r.TimedOut:
+					icon, detail = "TIMEOUT", r.Err.Error()
+					errs++
+				case r.Err != nil:
+					icon, detail = "ERRO", r.Err.Error()
+					errs++
+				case r.Reverted:
+					icon, detail = "REMOVIDO", "restaurado"
+				}
+				fmt.Printf("  [%s] %s — %s\n", icon, r.Module.Name(), detail)
+			}
+			app.printLogPath(os.Stdout)
+
+			if cmd.Context().Err() != nil {
+				return exitWith(report.ExitCancelled, tui.ErrCancelled)
+			}
+			if errs > 0 {
+				return fmt.Errorf("%d modulo(s) com erro", errs)
+			}
+
+			fmt.Println()
+			fmt.Println("Concluido!")
+			return nil
+		},
+	}
+}
+
+// modulesByName busca os modulos pelo nome no registry.
+// Retorna erro listando os modulos disponiveis se algum nome for desconhecido.
+func modulesByName(reg *module.Registry, names []string) ([]module.Module, error) {
+	var modules []module.Module
+	for _, name := range names {
+		m, ok := reg.ByName(name)
+		if !ok {
+			available := make([]string, 0, len(reg.All()))
+			for _, m := range reg.All() {
+				available = append(available, m.Name())
+			}
+			return nil, fmt.Errorf("modulo desconhecido: %q (disponiveis: %s)", name, strings.Join(available, ", "))
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ale/blueprint/internal/history"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/report"
+	"github.com/ale/blueprint/internal/resume"
+)
+
+// resumedRun e um apply retomado apos o reboot (apply --resume): a fila
+// gravada pela fase anterior e os resultados das fases ja executadas.
+type resumedRun struct {
+	state    resume.State
+	previous []history.Run
+}
+
+// resumeStore retorna o estado da retomada no diretorio de estado.
+func (app *App) resumeStore() *resume.Store {
+	return resume.NewStore(app.System, app.StateDir)
+}
+
+// loadResume carrega a fila gravada pela fase anterior e ajusta --only e
+// --set para retomar. Sem reboot desde a fase anterior (ex: logout/login),
+// a retomada e recusada e o gatilho continua armado para o proximo login.
+// Aceita, o gatilho e de uso unico: sai assim que o apply retomado comeca
+// (se ele for cancelado, basta rodar apply --resume de novo).
+func (app *App) loadResume(ctx context.Context, args []string) (*resumedRun, error) {
+	if len(args) > 0 || len(app.Options.Only) > 0 {
+		return nil, fmt.Errorf("--resume usa o perfil e os modulos gravados pela fase anterior; nao combine com perfil ou --only")
+	}
+	state, ok, err := app.resumeStore().Load()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("nenhum apply esperando o reboot para continuar")
+	}
+	if !state.Rebooted(app.System) {
+		return nil, fmt.Errorf("o reboot pedido pela fase %d ainda nao aconteceu: reinicie e o apply continua sozinho no proximo login (%s)",
+			state.Phase-1, strings.Join(state.Modules, ", "))
+	}
+
+	app.Options.Only = state.Modules
+	app.Options.Set = append(append([]string{}, state.Set...), app.Options.Set...)
+
+	r := &resumedRun{state: state}
+	for _, id := range state.Runs {
+		run, err := app.history().Get(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Aviso: fase anterior: %v\n", err)
+			continue
+		}
+		r.previous = append(r.previous, run)
+	}
+
+	if err := resume.Remove(ctx, app.runSystem()); err != nil {
+		fmt.Fprintf(os.Stderr, "Aviso: %v\n", err)
+	}
+	return r, nil
+}
+
+// phase retorna o numero da fase em execucao (1 sem retomada).
+func (r *resumedRun) phase() int {
+	if r == nil {
+		return 1
+	}
+	return r.state.Phase
+}
+
+// printPrevious imprime os resultados das fases anteriores no headless.
+func (r *resumedRun) printPrevious(out io.Writer) {
+	if r == nil {
+		return
+	}
+	for i, run := range r.previous {
+		fmt.Fprintf(out, "=== Fase %d (%s) ===\n", i+1, run.ID)
+		for _, e := range run.Entries {
+			fmt.Fprintf(out, "  %s — %s\n", e.Module, e.Outcome())
+		}
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintf(out, "Continuando apos o reboot (fase %d)\n", r.phase())
+}
+
+// saveResume fecha a fase atual: se algum modulo foi adiado ate o reboot,
+// grava a fila da proxima fase e instala o gatilho que a retoma no proximo
+// login; sem modulos adiados, encerra o apply retomado. Uma execucao
+// cancelada (ou que nem comecou, ex: saida do TUI na confirmacao) nao altera
+// nada. Falhas viram aviso, como no historico.
+// Retorna o resumo das fases para o report (nil sem varias fases).
+func (app *App) saveResume(ctx context.Context, prev *resumedRun, profile string, run *history.Run, results []orchestrator.Result) *report.Resume {
+	if ctx.Err() != nil || len(results) == 0 {
+		return nil
+	}
+	for _, r := range results {
+		if r.Cancelled {
+			return nil
+		}
+	}
+	out := app.humanOut()
+	deferred := resume.Deferred(results)
+
+	var info *report.Resume
+	if prev != nil || len(deferred) > 0 {
+		info = &report.Resume{Phase: prev.phase(), Pending: deferred}
+		if prev != nil {
+			info.Previous = prev.state.Runs
+		}
+	}
+
+	if len(deferred) == 0 {
+		if prev != nil && !app.Options.DryRun {
+			if err := app.resumeStore().Clear(); err != nil {
+				fmt.Fprintf(os.Stderr, "Aviso: %v\n", err)
+			}
+		}
+		return info
+	}
+
+	var prevState *resume.State
+	if prev != nil {
+		prevState = &prev.state
+	}
+	var runID string
+	if run != nil {
+		runID = run.ID
+	}
+	next := resume.Next(prevState, profile, app.Options.Set, runID, deferred)
+	next.BootID = resume.BootID(app.System)
+
+	fmt.Fprintln(out)
+	if app.Options.DryRun {
+		fmt.Fprintf(out, "[dry-run] Apos o reboot, a fase %d aplicaria: %s\n", next.Phase, strings.Join(deferred, ", "))
+		return info
+	}
+	if err := app.resumeStore().Save(next); err != nil {
+		fmt.Fprintf(os.Stderr, "Aviso: %v\n", err)
+		return info
+	}
+	spec, err := app.resumeSpec()
+	if err == nil {
+		err = resume.Install(ctx, app.System, spec)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Aviso: %v\n", err)
+		fmt.Fprintf(out, "Apos o reboot, continue com: blueprint apply --resume (fase %d: %s)\n", next.Phase, strings.Join(deferred, ", "))
+		return info
+	}
+	fmt.Fprintf(out, "Apos o reboot, o blueprint continua sozinho no proximo login (fase %d: %s)\n", next.Phase, strings.Join(deferred, ", "))
+	return info
+}
+
+// resumeSpec monta o gatilho da retomada: autostart com terminal quando ha
+// sessao grafica (o apply abre no TUI), unit systemd headless caso contrario.
+func (app *App) resumeSpec() (resume.Spec, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return resume.Spec{}, fmt.Errorf("erro ao localizar o binario: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return resume.Spec{}, fmt.Errorf("erro ao localizar o binario: %w", err)
+	}
+	graphical := app.System.Env("DISPLAY") != "" || app.System.Env("WAYLAND_DISPLAY") != ""
+	return resume.Spec{Exe: exe, RepoDir: app.Repo.Dir, Graphical: graphical}, nil
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ale/blueprint/internal/system"
+	"github.com/spf13/cobra"
+)
+
+func newRollbackCmd(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rollback [run-id]",
+		Short: "Restaurar os arquivos alterados por uma execucao (padrao: a mais recente)",
+		Long:  "Restaura os arquivos salvos antes de uma execucao de apply/remove: conteudo, symlinks e arquivos de sistema. Arquivos criados pela execucao sao apagados. Veja os IDs com 'blueprint history'.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := "last"
+			if len(args) > 0 {
+				id = args[0]
+			}
+			run, err := app.history().Get(id)
+			if err != nil {
+				return err
+			}
+
+			dir := app.backupDir(run.ID)
+			entries, err := system.LoadBackup(app.System, dir)
+			if err != nil {
+				return fmt.Errorf("execucao %s nao tem arquivos para restaurar: %w", run.ID, err)
+			}
+
+			privileged := false
+			for _, e := range entries {
+				privileged = privileged || e.Privileged
+			}
+			sys := app.runSystem()
+			if privileged {
+				defer app.ensurePrivileges(cmd.Context(), sys)()
+			}
+
+			fmt.Printf("Restaurando %d arquivo(s) da execucao %s (%s)\n\n", len(entries), run.ID, run.Action)
+
+			// Ordem inversa: desfaz as ultimas alteracoes primeiro
+			var errs int
+			for i := len(entries) - 1; i >= 0; i-- {
+				e := entries[i]
+				if err := system.Restore(cmd.Context(), sys, e); err != nil {
+					fmt.Printf("  [ERRO] %s — %v\n", e.Path, err)
+					errs++
+					continue
+				}
+				if e.Existed {
+					fmt.Printf("  [RESTAURADO] %s\n", e.Path)
+				} else {
+					fmt.Printf("  [REMOVIDO] %s\n", e.Path)
+				}
+			}
+
+			if errs > 0 {
+				return fmt.Errorf("%d arquivo(s) nao restaurado(s)", errs)
+			}
+
+			fmt.Println()
+			fmt.Println("Concluido!")
+			return nil
+		},
+	}
+}
@@ -1,8 +1,23 @@
 package cli
 
 import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/ale/blueprint/internal/config"
+	"github.com/ale/blueprint/internal/doctor"
+	"github.com/ale/blueprint/internal/logging"
 	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/profile"
+	"github.com/ale/blueprint/internal/report"
+	"github.com/ale/blueprint/internal/system"
+	"github.com/ale/blueprint/internal/version"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // Options armazena as flags globais da CLI.
@@ -11,6 +26,19 @@ type Options struct {
 	Profile  string
 	DryRun   bool
 	Verbose  bool
+	Jobs     int      // Modulos executados em paralelo no apply
+	Set      []string // Opcoes de modulo via --set modulo.chave=valor
+	Output   string   // Formato de saida de status/apply: text, json ou yaml
+	FailOn   []string // Condicoes que geram codigo de saida diferente de zero
+	Only     []string // Modulos do perfil aplicados pelo apply (vazio: todos)
+	Reboot   bool     // Reiniciar ao final do apply se algum modulo exigir
+	Logout   bool     // Fazer logout ao final do apply se algum modulo exigir
+	Resume   bool     // Continuar o apply adiado pelo reboot (ver resume.State)
+
+	Privilege string // Backend de elevacao: auto, sudo, pkexec ou run0
+
+	Timeout     time.Duration // Limite padrao de check e apply por modulo (0: sem limite)
+	IdleTimeout time.Duration // Limite sem saida para comandos longos (0: sem limite)
 }
 
 // App agrupa as dependencias necessarias para os comandos.
@@ -18,7 +46,122 @@ type App struct {
 	Registry  *module.Registry
 	System    module.System
 	Options   *Options
-	ConfigDir string // Caminho para o diretorio configs/ do repo
+	ConfigDir string      // Caminho para o diretorio configs/ do repo
+	Repo      doctor.Repo // Diretorio do repo e como foi encontrado
+	StateDir  string      // Diretorio de estado (historico de execucoes)
+	Config    *config.Config
+	Log       *logging.Log // Log da execucao (aberto no PersistentPreRun; nil em version/help)
+	Ignored   []error      // Modulos declarativos e plugins que falharam ao carregar (avisados e listados no doctor)
+}
+
+// logger retorna o logger da execucao (descarta tudo se o log nao foi aberto).
+func (app *App) logger() *slog.Logger {
+	if app.Log == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return app.Log.Logger
+}
+
+// logPath retorna o caminho do arquivo de log ("" se nao ha arquivo).
+func (app *App) logPath() string {
+	if app.Log == nil {
+		return ""
+	}
+	return app.Log.Path
+}
+
+// muteLog para de espelhar o log (-v) no terminal, que o TUI vai ocupar.
+func (app *App) muteLog() {
+	if app.Log != nil {
+		app.Log.MuteTerminal()
+	}
+}
+
+// printLogPath imprime o caminho do log da execucao no resumo.
+func (app *App) printLogPath(w io.Writer) {
+	if path := app.logPath(); path != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Log: %s\n", path)
+	}
+}
+
+// newOrchestrator cria um Orchestrator que registra seus eventos no log e
+// aplica --timeout.
+func (app *App) newOrchestrator(sys module.System, reporter module.Reporter) *orchestrator.Orchestrator {
+	orch := orchestrator.New(sys, reporter)
+	orch.SetLogger(app.logger())
+	orch.SetTimeout(app.Options.Timeout)
+	return orch
+}
+
+// openLog abre o log da execucao em <StateDir>/logs e liga o System real a
+// ele. Falhar ao criar o arquivo nao impede o comando, so gera um aviso.
+func (app *App) openLog(cmd *cobra.Command) {
+	switch cmd.Name() {
+	case "version", "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return
+	}
+	l, err := logging.Open(app.StateDir, cmd.Name(), app.Options.Verbose, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Aviso: log da execucao desativado: %v\n", err)
+	}
+	app.Log = l
+	if r, ok := app.System.(*system.Real); ok {
+		r.SetLogger(l.Logger)
+	}
+	l.Logger.Info("execucao iniciada", "comando", cmd.CommandPath(), "args", os.Args[1:], "versao", version.Version)
+}
+
+// resolve monta os modulos de um perfil aplicando enabled/disabled do config.toml.
+func (app *App) resolve(p profile.Profile) []module.Module {
+	modules := profile.Resolve(p, app.Registry)
+	if app.Config == nil {
+		return modules
+	}
+	return app.Config.Select(modules, app.Registry)
+}
+
+// selectProfile resolve o perfil da execucao: argumento posicional (se
+// houver), --profile/default_profile ou auto-deteccao. autoDetected indica
+// que o perfil veio de profile.Detect.
+func (app *App) selectProfile(args []string) (p profile.Profile, autoDetected bool, err error) {
+	if len(args) > 0 {
+		app.Options.Profile = args[0]
+	}
+	if app.Options.Profile == "auto" {
+		return profile.Detect(app.System), true, nil
+	}
+	p, err = profile.ByName(app.Options.Profile)
+	return p, false, err
+}
+
+// runSystem retorna o System usado para executar modulos: o real ou, com
+// --dry-run, um DryRun que imprime comandos e diffs (coloridos no terminal).
+func (app *App) runSystem() module.System {
+	if !app.Options.DryRun {
+		return app.System
+	}
+	out := app.humanOut()
+	dry := system.NewDryRun(app.System, func(msg string) {
+		fmt.Fprintln(out, msg)
+	})
+	f, ok := out.(*os.File)
+	dry.Color = ok && term.IsTerminal(int(f.Fd()))
+	return dry
+}
+
+// outputFormat valida --output.
+func (app *App) outputFormat() (report.Format, error) {
+	return report.ParseFormat(app.Options.Output)
+}
+
+// humanOut retorna onde imprimir texto para humanos: o stdout, ou o stderr
+// quando --output json|yaml reserva o stdout para o documento.
+func (app *App) humanOut() io.Writer {
+	if f, err := app.outputFormat(); err == nil && f != report.FormatText {
+		return os.Stderr
+	}
+	return os.Stdout
 }
 
 // NewRootCmd cria o comando raiz com todas as flags globais.
@@ -29,18 +172,44 @@ func NewRootCmd(app *App) *cobra.Command {
 		Long:          "CLI para configurar e manter seu ambiente Bluefin, com suporte a TUI interativo e modo headless.",
 		SilenceUsage:  true,
 		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			// default_profile do config.toml vale quando --profile nao foi passado
+			if app.Config != nil && app.Config.DefaultProfile != "" && !cmd.Flags().Changed("profile") {
+				app.Options.Profile = app.Config.DefaultProfile
+			}
+			priv, err := system.ParsePrivilege(app.Options.Privilege, app.System)
+			if err != nil {
+				return err
+			}
+			app.openLog(cmd)
+			if r, ok := app.System.(*system.Real); ok {
+				r.SetIdleTimeout(app.Options.IdleTimeout)
+				r.SetPrivilege(priv)
+			}
+			return nil
+		},
 	}
 
 	// Flags globais
 	cmd.PersistentFlags().BoolVar(&app.Options.Headless, "headless", false, "Modo headless (sem TUI)")
-	cmd.PersistentFlags().StringVarP(&app.Options.Profile, "profile", "p", "auto", "Perfil de instalacao (auto, full, minimal, server)")
+	cmd.PersistentFlags().StringVarP(&app.Options.Profile, "profile", "p", "auto", "Perfil de instalacao (auto, full, minimal, server, wsl ou perfil do config.toml)")
 	cmd.PersistentFlags().BoolVar(&app.Options.DryRun, "dry-run", false, "Mostrar o que seria feito sem executar")
-	cmd.PersistentFlags().BoolVarP(&app.Options.Verbose, "verbose", "v", false, "Saida detalhada")
+	cmd.PersistentFlags().DurationVar(&app.Options.Timeout, "timeout", 0, "Limite de tempo do check e do apply de cada modulo (ex: 10m; 0 = sem limite; modulos podem declarar o proprio)")
+	cmd.PersistentFlags().DurationVar(&app.Options.IdleTimeout, "idle-timeout", system.DefaultIdleTimeout, "Encerrar comandos que ficarem esse tempo sem imprimir nada (0 = sem limite)")
+	cmd.PersistentFlags().StringVar(&app.Options.Privilege, "privilege", "auto", "Como executar comandos como root ("+system.PrivilegeNames+")")
+	cmd.PersistentFlags().BoolVarP(&app.Options.Verbose, "verbose", "v", false, "Espelhar o log da execucao (comandos, duracao, stderr) no terminal")
 
 	// Subcomandos
 	cmd.AddCommand(
 		newApplyCmd(app),
+		newRemoveCmd(app),
 		newStatusCmd(app),
+		newPlanCmd(app),
+		newDoctorCmd(app),
+		newScheduleCmd(app),
+		newHistoryCmd(app),
+		newRollbackCmd(app),
+		newConfigCmd(app),
 		newUpdateCmd(app),
 		newVersionCmd(),
 	)
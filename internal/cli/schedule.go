@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ale/blueprint/internal/schedule"
+	"github.com/spf13/cobra"
+)
+
+func newScheduleCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Verificar drift periodicamente via timer systemd de usuario",
+		Long: "Instala um service + timer systemd de usuario que roda 'blueprint schedule run'. " +
+			"Se algum modulo aplicado antes deixou de estar instalado (ex: uma atualizacao do GNOME " +
+			"desativou uma extensao), uma notificacao oferece reaplicar os modulos afetados.",
+	}
+	cmd.AddCommand(newScheduleInstallCmd(app), newScheduleRemoveCmd(app), newScheduleRunCmd(app), newScheduleNotifyCmd(app))
+	return cmd
+}
+
+func newScheduleInstallCmd(app *App) *cobra.Command {
+	var onCalendar string
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Instalar e ativar o timer de verificacao",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			exe, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("erro ao localizar o binario: %w", err)
+			}
+			if exe, err = filepath.EvalSymlinks(exe); err != nil {
+				return fmt.Errorf("erro ao localizar o binario: %w", err)
+			}
+
+			// O perfil escolhido agora vale para as verificacoes
+			args := []string{"schedule", "run"}
+			if app.Options.Profile != "auto" {
+				args = append(args, "--profile", app.Options.Profile)
+			}
+
+			spec := schedule.Spec{Exe: exe, Args: args, RepoDir: app.Repo.Dir, OnCalendar: onCalendar}
+			if err := schedule.Install(cmd.Context(), app.runSystem(), spec); err != nil {
+				return err
+			}
+
+			dir := schedule.UnitDir(app.System)
+			fmt.Printf("Timer instalado: %s (%s)\n", filepath.Join(dir, schedule.TimerName), onCalendar)
+			fmt.Printf("Ver proxima execucao: systemctl --user list-timers %s\n", schedule.TimerName)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&onCalendar, "on-calendar", schedule.DefaultOnCalendar, "Frequencia no formato OnCalendar do systemd (ex: daily, hourly, Mon..Fri 09:00)")
+
+	return cmd
+}
+
+func newScheduleRemoveCmd(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove",
+		Short: "Desativar e remover o timer de verificacao",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := schedule.Remove(cmd.Context(), app.runSystem()); err != nil {
+				return err
+			}
+			fmt.Println("Timer removido.")
+			return nil
+		},
+	}
+}
+
+func newScheduleRunCmd(app *App) *cobra.Command {
+	var noNotify bool
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Verificar drift agora (executado pelo timer)",
+		Long: "Verifica os modulos do perfil e compara com o historico. Modulos parciais, ou ausentes " +
+			"depois de terem sido instalados, geram uma notificacao com a acao de reaplicar.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			prof, _, err := app.selectProfile(nil)
+			if err != nil {
+				return err
+			}
+			results := app.newOrchestrator(app.System, nil).CheckAll(ctx, app.resolve(prof))
+
+			runs, err := app.history().List()
+			if err != nil {
+				return err
+			}
+			drifted := schedule.Drifted(results, runs)
+			if len(drifted) == 0 {
+				fmt.Println("Sem drift.")
+				return nil
+			}
+
+			var names, lines []string
+			for _, r := range drifted {
+				names = append(names, r.Module.Name())
+				lines = append(lines, fmt.Sprintf("%s: %s", r.Module.Name(), r.Status.Message))
+				fmt.Printf("  drift: %s (%s) %s\n", r.Module.Name(), r.Status.Kind, r.Status.Message)
+			}
+			if noNotify {
+				return nil
+			}
+
+			exe, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("erro ao localizar o binario: %w", err)
+			}
+			applyCmd := []string{exe, "apply", "--profile", prof.Name, "--only", strings.Join(names, ",")}
+			title := fmt.Sprintf("blueprint: %d modulo(s) fora do lugar", len(drifted))
+			body := strings.Join(lines, "\n")
+
+			// A notificacao com acao espera o usuario: roda fora do service
+			// do timer. Sem systemd-run, notifica sem o botao Reaplicar.
+			notifyCmd := append([]string{exe, "schedule", "notify", "--title", title, "--body", body, "--"}, applyCmd...)
+			if err := schedule.Detach(ctx, app.System, notifyCmd); err != nil {
+				app.logger().Warn("notificacao sem acao", "erro", err)
+				_, err = schedule.Notify(ctx, app.System, schedule.Notification{Title: title, Body: body})
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&noNotify, "no-notify", false, "Apenas listar o drift, sem notificacao")
+
+	return cmd
+}
+
+// newScheduleNotifyCmd mostra a notificacao de drift e, se o usuario escolher
+// Reaplicar, abre um terminal com o comando recebido apos "--". Executado
+// pelo "schedule run" numa unit transitoria (schedule.Detach).
+func newScheduleNotifyCmd(app *App) *cobra.Command {
+	var title, body string
+
+	cmd := &cobra.Command{
+		Use:    "notify --title TITULO --body TEXTO -- COMANDO...",
+		Short:  "Mostrar a notificacao de drift (usado pelo schedule run)",
+		Hidden: true,
+		Args:   cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			clicked, err := schedule.Notify(ctx, app.System, schedule.Notification{
+				Title:       title,
+				Body:        body,
+				ActionLabel: "Reaplicar",
+			})
+			if err != nil {
+				return err
+			}
+			if clicked {
+				return schedule.OpenTerminal(ctx, app.System, args)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&title, "title", "", "Titulo da notificacao")
+	cmd.Flags().StringVar(&body, "body", "", "Texto da notificacao")
+
+	return cmd
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/report"
+	"github.com/ale/blueprint/internal/session"
+)
+
+// sessionDelay e a contagem regressiva antes do --reboot/--logout, para o
+// usuario ler o resumo (ou desistir com Ctrl+C).
+const sessionDelay = 10 * time.Second
+
+// sessionActions consolida as acoes de sessao pedidas pelos modulos e as
+// pendentes no sistema (deployment rpm-ostree). A deteccao usa o sistema
+// real mesmo em dry-run: so le o estado.
+func (app *App) sessionActions(ctx context.Context, results []orchestrator.Result) []session.Requirement {
+	return session.Collect(results, app.pendingSession(ctx))
+}
+
+// pendingSession detecta acoes de sessao pendentes fora dos modulos.
+func (app *App) pendingSession(ctx context.Context) []module.SessionRequest {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return session.Pending(ctx, app.System)
+}
+
+// printSession imprime a secao de acoes de sessao do resumo headless.
+func printSession(reqs []session.Requirement) {
+	if len(reqs) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("=== Acoes de sessao pendentes ===")
+	for _, r := range reqs {
+		fmt.Printf("  %s\n", r.Action)
+		for _, src := range r.Sources {
+			fmt.Printf("    %s\n", src)
+		}
+	}
+}
+
+// finishSession executa --reboot/--logout ao final do apply, se alguma
+// acao pendente pedir, apos uma contagem regressiva interrompivel por
+// Ctrl+C. Nada e executado se o apply terminou com erro ou foi cancelado.
+func (app *App) finishSession(ctx context.Context, reqs []session.Requirement, code int) error {
+	if !app.Options.Reboot && !app.Options.Logout {
+		return nil
+	}
+	out := app.humanOut()
+	action := session.Choose(reqs, app.Options.Reboot, app.Options.Logout)
+	if action == 0 {
+		fmt.Fprintln(out, "Nenhum reboot/logout necessario.")
+		return nil
+	}
+	if code != report.ExitOK {
+		fmt.Fprintf(out, "Nao vou %s: o apply terminou com pendencias (codigo %d).\n", action, code)
+		return nil
+	}
+
+	if !app.Options.DryRun {
+		fmt.Fprintf(out, "\nVou %s em %s (Ctrl+C cancela)...\n", action, sessionDelay)
+		timer := time.NewTimer(sessionDelay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(out, "Cancelado.")
+			return nil
+		case <-timer.C:
+		}
+	}
+	app.logger().Info("acao de sessao", "acao", action.Key())
+	return session.Perform(ctx, app.runSystem(), action)
+}
@@ -6,8 +6,7 @@ import (
 	"strings"
 
 	"github.com/ale/blueprint/internal/module"
-	"github.com/ale/blueprint/internal/orchestrator"
-	"github.com/ale/blueprint/internal/profile"
+	"github.com/ale/blueprint/internal/report"
 	"github.com/ale/blueprint/internal/tui"
 	"github.com/ale/blueprint/internal/version"
 	"github.com/spf13/cobra"
@@ -28,33 +27,50 @@ const (
 )
 
 func newStatusCmd(app *App) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Mostrar estado detalhado dos modulos",
+		Long: "Verifica cada modulo do perfil. Codigos de saida: 0 tudo instalado, 1 falha do blueprint, " +
+			"2 modulo ausente ou parcial, 3 erro ao verificar, 4 todos os modulos pulados. " +
+			"--fail-on escolhe quais condicoes contam (padrao: partial,missing,error).",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
 			sys := app.System
 
-			var prof profile.Profile
-			autoDetected := false
-			if app.Options.Profile == "auto" {
-				prof = profile.Detect(sys)
-				autoDetected = true
-			} else {
-				var err error
-				prof, err = profile.ByName(app.Options.Profile)
-				if err != nil {
-					return err
-				}
+			format, err := app.outputFormat()
+			if err != nil {
+				return err
+			}
+			failOn, err := report.ParseFailOn(app.Options.FailOn)
+			if err != nil {
+				return err
+			}
+
+			prof, autoDetected, err := app.selectProfile(nil)
+			if err != nil {
+				return err
 			}
-			modules := profile.Resolve(prof, app.Registry)
+			modules := app.resolve(prof)
 
-			reporter := tui.NewHeadlessReporter()
-			orch := orchestrator.New(sys, reporter)
+			reporter := tui.NewHeadlessReporterTo(app.humanOut())
+			orch := app.newOrchestrator(sys, reporter)
 			results := orch.CheckAll(ctx, modules)
+			outOfProfile := app.outOfProfile(modules)
+			code := report.ExitCode(results, failOn)
+
+			if format != report.FormatText {
+				rep := report.New("status", sys, prof.Name, autoDetected, hostname(), results)
+				rep.OutOfProfile = outOfProfile
+				rep.ExitCode = code
+				rep.LogFile = app.logPath()
+				if err := report.Write(os.Stdout, format, rep); err != nil {
+					return err
+				}
+				return exitWith(code, nil)
+			}
 
 			// ── Header ──────────────────────────────
-			hostname, _ := os.Hostname()
+			host := hostname()
 			fmt.Printf("\n%s%s blueprint status%s", colorBold, colorCyan, colorReset)
 			if version.Version != "" {
 				fmt.Printf("  %s%s%s", colorDim, version.Version, colorReset)
@@ -67,8 +83,8 @@ func newStatusCmd(app *App) *cobra.Command {
 				fmt.Printf("  %s(auto-detectado)%s", colorDim, colorReset)
 			}
 			fmt.Println()
-			if hostname != "" {
-				fmt.Printf("  Host:      %s\n", hostname)
+			if host != "" {
+				fmt.Printf("  Host:      %s\n", host)
 			}
 			if sys.IsContainer() {
 				fmt.Printf("  Ambiente:  %scontainer%s\n", colorYellow, colorReset)
@@ -83,7 +99,7 @@ func newStatusCmd(app *App) *cobra.Command {
 			// ── Modules ──────────────────────────────
 			installed, total := 0, len(results)
 			for _, r := range results {
-				if r.Status.Kind == module.Installed {
+				if r.Err == nil && !r.Unchecked && r.Status.Kind == module.Installed {
 					installed++
 				}
 			}
@@ -91,32 +107,54 @@ func newStatusCmd(app *App) *cobra.Command {
 
 			for _, r := range results {
 				icon, color := statusStyle(r.Status.Kind)
+				message := r.Status.Message
+				if r.Err != nil || r.Unchecked {
+					// Status de um Check que falhou e o valor zero (Installed)
+					icon, color = "!", colorRed
+					message = fmt.Sprintf("erro ao verificar: %v", r.Err)
+				}
 				fmt.Printf("  %s%s%s  %s%-*s%s  %s%s%s\n",
 					color, icon, colorReset,
 					colorBold, maxNameWidth, r.Module.Name(), colorReset,
-					colorDim, r.Status.Message, colorReset)
+					colorDim, message, colorReset)
 			}
 			fmt.Println()
 
 			// ── Skipped modules ──────────────────────
-			allModules := app.Registry.All()
-			resolved := make(map[string]bool)
-			for _, m := range modules {
-				resolved[m.Name()] = true
-			}
-			var skipped []string
-			for _, m := range allModules {
-				if !resolved[m.Name()] {
-					skipped = append(skipped, m.Name())
-				}
-			}
-			if len(skipped) > 0 {
-				fmt.Printf("  %sFora do perfil:%s %s\n\n", colorDim, colorReset, strings.Join(skipped, ", "))
+			if len(outOfProfile) > 0 {
+				fmt.Printf("  %sFora do perfil:%s %s\n\n", colorDim, colorReset, strings.Join(outOfProfile, ", "))
 			}
 
-			return nil
+			return exitWith(code, nil)
 		},
 	}
+
+	cmd.Flags().StringVarP(&app.Options.Output, "output", "o", "text", "Formato da saida: text, json ou yaml")
+	cmd.Flags().StringSliceVar(&app.Options.FailOn, "fail-on", []string{report.FailPartial, report.FailMissing, report.FailError},
+		"Condicoes que geram codigo de saida diferente de zero: partial, missing, error ou none")
+
+	return cmd
+}
+
+// outOfProfile lista os modulos registrados que nao estao em modules.
+func (app *App) outOfProfile(modules []module.Module) []string {
+	resolved := make(map[string]bool)
+	for _, m := range modules {
+		resolved[m.Name()] = true
+	}
+	var names []string
+	for _, m := range app.Registry.All() {
+		if !resolved[m.Name()] {
+			names = append(names, m.Name())
+		}
+	}
+	return names
+}
+
+// hostname retorna o nome da maquina (vazio se indisponivel).
+func hostname() string {
+	name, _ := os.Hostname()
+	return name
 }
 
 func statusStyle(kind module.StatusKind) (string, string) {
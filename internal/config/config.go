@@ -0,0 +1,263 @@
+// Package config carrega a configuracao do usuario (~/.config/blueprint/config.toml):
+// perfil padrao, perfis do usuario, modulos habilitados/desabilitados e opcoes por modulo.
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/profile"
+)
+
+// Config e o conteudo de config.toml. Arquivo ausente equivale a Config vazia.
+//
+//	default_profile = "full"
+//
+//	[modules.devbox]
+//	enabled = false
+//
+//	[modules.tiling-shell.options]
+//	inner_gap = 8
+//
+//	[profiles.backend-laptop]
+//	extends = "full"
+//	remove_tags = ["desktop"]
+type Config struct {
+	DefaultProfile string                  `toml:"default_profile"`
+	Modules        map[string]Module       `toml:"modules"`
+	Profiles       map[string]profile.Spec `toml:"profiles"`
+
+	path string // arquivo de origem (vazio se nao existia)
+}
+
+// Module e a configuracao de um modulo.
+type Module struct {
+	// Enabled forca o modulo dentro (true) ou fora (false) de qualquer perfil.
+	// nil mantem a decisao do perfil.
+	Enabled *bool          `toml:"enabled"`
+	Options map[string]any `toml:"options"`
+}
+
+// Dir retorna $XDG_CONFIG_HOME/blueprint (padrao: ~/.config/blueprint).
+func Dir(sys module.System) string {
+	dir := sys.Env("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = filepath.Join(sys.HomeDir(), ".config")
+	}
+	return filepath.Join(dir, "blueprint")
+}
+
+// DefaultPath retorna o config.toml dentro de Dir.
+func DefaultPath(sys module.System) string {
+	return filepath.Join(Dir(sys), "config.toml")
+}
+
+// Load le e decodifica o arquivo. Arquivo inexistente retorna Config vazia;
+// chaves desconhecidas sao erro (provavelmente erro de digitacao).
+func Load(sys module.System, path string) (*Config, error) {
+	cfg := &Config{}
+	if !sys.FileExists(path) {
+		return cfg, nil
+	}
+
+	data, err := sys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ler %s: %w", path, err)
+	}
+
+	md, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return nil, fmt.Errorf("%s: chave desconhecida: %s", path, strings.Join(keys, ", "))
+	}
+
+	cfg.path = path
+	return cfg, nil
+}
+
+// Path retorna o arquivo de origem, ou "" se a configuracao e a padrao.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// RegisterProfiles monta os perfis do usuario (extends, tags e modulos) e
+// os registra no pacote profile. Deve ser chamado antes de Validate.
+func (c *Config) RegisterProfiles() error {
+	if len(c.Profiles) == 0 {
+		return nil
+	}
+	for _, name := range sortedKeys(c.Profiles) {
+		if rule := c.Profiles[name].Detect; rule != nil {
+			if err := rule.Validate(); err != nil {
+				return c.errorf("profiles.%s.detect: %w", name, err)
+			}
+		}
+	}
+	profiles, err := profile.Compose(c.Profiles)
+	if err != nil {
+		return c.errorf("profiles: %w", err)
+	}
+	if err := profile.Register(profiles...); err != nil {
+		return c.errorf("profiles: %w", err)
+	}
+	return nil
+}
+
+// Validate confere a configuracao contra o registry: modulos desconhecidos,
+// perfil inexistente e opcoes para modulos que nao aceitam opcoes.
+func (c *Config) Validate(reg *module.Registry) error {
+	if c.DefaultProfile != "" && c.DefaultProfile != "auto" {
+		if _, err := profile.ByName(c.DefaultProfile); err != nil {
+			return c.errorf("default_profile: %w", err)
+		}
+	}
+
+	for _, name := range sortedKeys(c.Profiles) {
+		spec := c.Profiles[name]
+		for _, list := range [][]string{spec.Modules, spec.ExcludeModules} {
+			for _, m := range list {
+				if _, ok := reg.ByName(m); !ok {
+					return c.errorf("profiles.%s: modulo desconhecido: %q", name, m)
+				}
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(c.Modules) {
+		m, ok := reg.ByName(name)
+		if !ok {
+			return c.errorf("modules.%s: modulo desconhecido", name)
+		}
+		if opts := c.Modules[name].Options; len(opts) > 0 {
+			configurable, ok := m.(module.Configurable)
+			if !ok {
+				return c.errorf("modules.%s.options: modulo nao aceita opcoes", name)
+			}
+			if _, err := module.ParseOptions(configurable, opts); err != nil {
+				return c.errorf("modules.%s.options: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Configure repassa as opcoes de cada modulo via module.SetOptions, que
+// valida os valores pelo schema do modulo.
+func (c *Config) Configure(reg *module.Registry) error {
+	for _, name := range sortedKeys(c.Modules) {
+		opts := c.Modules[name].Options
+		if len(opts) == 0 {
+			continue
+		}
+		m, ok := reg.ByName(name)
+		if !ok {
+			return c.errorf("modules.%s: modulo desconhecido", name)
+		}
+		configurable, ok := m.(module.Configurable)
+		if !ok {
+			return c.errorf("modules.%s.options: modulo nao aceita opcoes", name)
+		}
+		if err := module.SetOptions(configurable, opts); err != nil {
+			return c.errorf("modules.%s.options: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Enabled retorna a escolha do usuario para o modulo (set=false se nao ha).
+func (c *Config) Enabled(name string) (enabled, set bool) {
+	mc, ok := c.Modules[name]
+	if !ok || mc.Enabled == nil {
+		return false, false
+	}
+	return *mc.Enabled, true
+}
+
+// Select aplica enabled/disabled sobre os modulos resolvidos pelo perfil:
+// remove os desabilitados e inclui os habilitados explicitamente, mantendo
+// a ordem de dependencias.
+func (c *Config) Select(modules []module.Module, reg *module.Registry) []module.Module {
+	var result []module.Module
+	included := make(map[string]bool, len(modules))
+	for _, m := range modules {
+		if enabled, set := c.Enabled(m.Name()); set && !enabled {
+			continue
+		}
+		result = append(result, m)
+		included[m.Name()] = true
+	}
+
+	for _, m := range reg.All() {
+		if enabled, _ := c.Enabled(m.Name()); enabled && !included[m.Name()] {
+			result = append(result, m)
+		}
+	}
+
+	return module.SortByDependencies(result)
+}
+
+// Effective monta a configuracao efetiva: perfil padrao, escolhas do usuario
+// e opcoes de todos os modulos configuraveis (padroes ja mesclados).
+func (c *Config) Effective(reg *module.Registry) *Config {
+	eff := &Config{
+		DefaultProfile: c.DefaultProfile,
+		Modules:        make(map[string]Module),
+		Profiles:       c.Profiles,
+		path:           c.path,
+	}
+	if eff.DefaultProfile == "" {
+		eff.DefaultProfile = "auto"
+	}
+
+	for _, m := range reg.All() {
+		mc := Module{Enabled: c.Modules[m.Name()].Enabled}
+		if configurable, ok := m.(module.Configurable); ok {
+			mc.Options = configurable.OptionValues()
+		}
+		if mc.Enabled != nil || len(mc.Options) > 0 {
+			eff.Modules[m.Name()] = mc
+		}
+	}
+	return eff
+}
+
+// Encode serializa a configuracao em TOML.
+func (c *Config) Encode() (string, error) {
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(c); err != nil {
+		return "", fmt.Errorf("serializar configuracao: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// sortedKeys retorna as chaves do mapa em ordem estavel.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// errorf prefixa o erro com o arquivo de origem.
+func (c *Config) errorf(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	if c.path == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", c.path, err)
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/system"
+)
+
+type stubModule struct {
+	name string
+	tags []string
+}
+
+func (s *stubModule) Name() string        { return s.name }
+func (s *stubModule) Description() string { return "modulo de teste" }
+func (s *stubModule) Tags() []string      { return s.tags }
+
+// optionsModule aceita a opcao inteira "size".
+type optionsModule struct {
+	stubModule
+	module.OptionSet
+}
+
+func (o *optionsModule) size() int { return o.Values().Int("size") }
+
+const path = "/home/test/.config/blueprint/config.toml"
+
+func newRegistry(t *testing.T) (*module.Registry, *optionsModule) {
+	t.Helper()
+	reg := module.NewRegistry()
+	opt := &optionsModule{
+		stubModule: stubModule{name: "b", tags: []string{"desktop"}},
+		OptionSet:  module.NewOptionSet(module.Option{Key: "size", Type: module.OptionInt, Default: 4}),
+	}
+	for _, m := range []module.Module{&stubModule{name: "a", tags: []string{"shell"}}, opt, &stubModule{name: "c", tags: []string{"shell"}}} {
+		if err := reg.Register(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return reg, opt
+}
+
+func load(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	mock := system.NewMock()
+	mock.Files[path] = []byte(content)
+	return Load(mock, path)
+}
+
+func TestDefaultPath(t *testing.T) {
+	mock := system.NewMock()
+	if got := DefaultPath(mock); got != path {
+		t.Errorf("DefaultPath() = %q", got)
+	}
+	mock.EnvVars["XDG_CONFIG_HOME"] = "/cfg"
+	if got := DefaultPath(mock); got != "/cfg/blueprint/config.toml" {
+		t.Errorf("DefaultPath() com XDG_CONFIG_HOME = %q", got)
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	cfg, err := Load(system.NewMock(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Path() != "" || cfg.DefaultProfile != "" || len(cfg.Modules) != 0 {
+		t.Errorf("esperava config vazia, obteve %+v", cfg)
+	}
+}
+
+func TestLoad_Full(t *testing.T) {
+	cfg, err := load(t, `
+default_profile = "minimal"
+
+[modules.a]
+enabled = false
+
+[modules.b.options]
+size = 8
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultProfile != "minimal" {
+		t.Errorf("DefaultProfile = %q", cfg.DefaultProfile)
+	}
+	if enabled, set := cfg.Enabled("a"); !set || enabled {
+		t.Errorf("Enabled(a) = %v, %v", enabled, set)
+	}
+	if _, set := cfg.Enabled("b"); set {
+		t.Error("b nao deveria ter enabled definido")
+	}
+}
+
+func TestLoad_UnknownKey(t *testing.T) {
+	_, err := load(t, "default_profle = \"full\"\n[modules.a]\nenable = true\n")
+	if err == nil {
+		t.Fatal("esperava erro para chave desconhecida")
+	}
+	for _, want := range []string{path, "default_profle", "modules.a.enable"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("erro %q deveria conter %q", err, want)
+		}
+	}
+}
+
+func TestLoad_InvalidTOML(t *testing.T) {
+	if _, err := load(t, "default_profile = \n"); err == nil {
+		t.Error("esperava erro de sintaxe")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	reg, _ := newRegistry(t)
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"valido", "default_profile = \"auto\"\n[modules.b.options]\nsize = 2\n", ""},
+		{"perfil desconhecido", "default_profile = \"gamer\"\n", "perfil desconhecido"},
+		{"modulo desconhecido", "[modules.zzz]\nenabled = true\n", "modules.zzz: modulo desconhecido"},
+		{"modulo sem opcoes", "[modules.a.options]\nx = 1\n", "modules.a.options: modulo nao aceita opcoes"},
+		{"opcao desconhecida", "[modules.b.options]\nwidth = 1\n", "opcao desconhecida: width"},
+		{"tipo invalido", "[modules.b.options]\nsize = true\n", "opcao size: esperava inteiro"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(t, tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = cfg.Validate(reg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("erro inesperado: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("erro = %v, esperava conter %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	reg, opt := newRegistry(t)
+
+	cfg, _ := load(t, "[modules.b.options]\nsize = 8\n")
+	if err := cfg.Configure(reg); err != nil {
+		t.Fatal(err)
+	}
+	if opt.size() != 8 {
+		t.Errorf("size = %d, esperava 8", opt.size())
+	}
+
+	cfg, _ = load(t, "[modules.b.options]\nsize = \"grande\"\n")
+	if err := cfg.Configure(reg); err == nil || !strings.Contains(err.Error(), "modules.b.options") {
+		t.Errorf("esperava erro de tipo com o caminho da opcao, obteve %v", err)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	reg, _ := newRegistry(t)
+	profileModules := []module.Module{mustModule(reg, "a"), mustModule(reg, "c")}
+
+	cfg, _ := load(t, "[modules.a]\nenabled = false\n[modules.b]\nenabled = true\n")
+	got := names(cfg.Select(profileModules, reg))
+
+	if strings.Join(got, ",") != "c,b" {
+		t.Errorf("Select() = %v, esperava [c b]", got)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	reg, _ := newRegistry(t)
+	cfg, _ := load(t, "[modules.a]\nenabled = false\n")
+
+	out, err := cfg.Effective(reg).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`default_profile = "auto"`, "[modules.a]", "enabled = false", "size = 4"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("saida deveria conter %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "[modules.c]") {
+		t.Errorf("modulo sem configuracao nao deveria aparecer:\n%s", out)
+	}
+}
+
+func mustModule(reg *module.Registry, name string) module.Module {
+	m, _ := reg.ByName(name)
+	return m
+}
+
+func names(modules []module.Module) []string {
+	var out []string
+	for _, m := range modules {
+		out = append(out, m.Name())
+	}
+	return out
+}
+
+func TestLoad_Profiles(t *testing.T) {
+	cfg, err := load(t, `
+[profiles.backend-laptop]
+extends = "full"
+remove_tags = ["desktop"]
+modules = ["b"]
+
+[profiles.backend-laptop.detect]
+hostname = "dev-*"
+env = { BLUEPRINT_TEAM = "backend" }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := cfg.Profiles["backend-laptop"]
+	if spec.Extends != "full" || spec.Detect == nil || spec.Detect.Env["BLUEPRINT_TEAM"] != "backend" {
+		t.Errorf("spec = %+v", spec)
+	}
+
+	reg, _ := newRegistry(t)
+	if err := cfg.Validate(reg); err != nil {
+		t.Errorf("erro inesperado: %v", err)
+	}
+}
+
+func TestLoad_ProfileUnknownKey(t *testing.T) {
+	_, err := load(t, "[profiles.x]\nextend = \"full\"\n")
+	if err == nil || !strings.Contains(err.Error(), "profiles.x.extend") {
+		t.Errorf("esperava erro com a chave profiles.x.extend, obteve %v", err)
+	}
+}
+
+func TestValidate_ProfileUnknownModule(t *testing.T) {
+	reg, _ := newRegistry(t)
+	cfg, _ := load(t, "[profiles.x]\nextends = \"full\"\nexclude_modules = [\"zzz\"]\n")
+
+	err := cfg.Validate(reg)
+	if err == nil || !strings.Contains(err.Error(), `profiles.x: modulo desconhecido: "zzz"`) {
+		t.Errorf("erro = %v", err)
+	}
+}
+
+func TestRegisterProfiles_Errors(t *testing.T) {
+	tests := map[string]string{
+		"[profiles.x]\nextends = \"gamer\"\n":                    "extends desconhecido",
+		"[profiles.x.detect]\ncontainer = \"podman\"\n":          "profiles.x.detect: container",
+		"[profiles.full]\ndescription = \"redefine built-in\"\n": `perfil "full" ja existe`,
+	}
+	for content, want := range tests {
+		cfg, err := load(t, content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cfg.RegisterProfiles(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("RegisterProfiles(%q) = %v, esperava conter %q", content, err, want)
+		}
+	}
+}
@@ -0,0 +1,481 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ale/blueprint/internal/gnome"
+	"github.com/ale/blueprint/internal/module"
+)
+
+// Tipos de acao aceitos em [[actions]].
+const (
+	ActionFile      = "file"      // escreve um arquivo (content ou source)
+	ActionLine      = "line"      // garante uma linha em um arquivo
+	ActionDconf     = "dconf"     // escreve uma chave dconf
+	ActionExtension = "extension" // instala e ativa uma extensao GNOME Shell
+	ActionCommand   = "command"   // executa um comando (com check/revert opcionais)
+)
+
+// ActionSpec e uma entrada de [[actions]]. Os campos usados dependem de Type.
+type ActionSpec struct {
+	Type string `toml:"type"`
+
+	// file e line
+	Path    string `toml:"path"`    // destino ("~/" = home do usuario)
+	Content string `toml:"content"` // file: conteudo literal
+	Source  string `toml:"source"`  // file: arquivo de origem (relativo ao .toml)
+	Mode    string `toml:"mode"`    // file: permissao em octal (padrao "0644")
+	Line    string `toml:"line"`    // line: linha a adicionar
+
+	// dconf
+	Key   string `toml:"key"`
+	Value string `toml:"value"` // valor em sintaxe GVariant (ex: "true", "'dark'")
+
+	// extension
+	UUID  string `toml:"uuid"`
+	Title string `toml:"title"` // nome exibido (padrao: uuid)
+
+	// command: argv, sem shell (use ["sh", "-c", "..."] se precisar)
+	Run    []string `toml:"run"`
+	Check  []string `toml:"check"`  // sucesso = ja aplicado; ausente = sempre executa
+	Revert []string `toml:"revert"` // ausente = sem remocao
+
+	// extension e command: novas tentativas em caso de falha (padrao: 2 para
+	// extension, 0 para command)
+	Retries *int `toml:"retries"`
+}
+
+// retry monta a politica de novas tentativas da acao: def se retries nao foi
+// informado, senao retries tentativas extras com o backoff de DefaultRetry.
+func (s ActionSpec) retry(def module.RetryPolicy) module.RetryPolicy {
+	if s.Retries == nil {
+		return def
+	}
+	p := module.DefaultRetry
+	p.Attempts = *s.Retries + 1
+	return p
+}
+
+// action e uma acao pronta para executar.
+type action interface {
+	describe() string
+	check(ctx context.Context, sys module.System) (bool, error)
+	apply(ctx context.Context, sys module.System, reporter module.Reporter) error
+	revert(ctx context.Context, sys module.System, reporter module.Reporter) (bool, error)
+	resources() []string
+
+	// plan descreve o que apply faria (chamado so quando check e false).
+	plan(ctx context.Context, sys module.System) ([]module.Action, error)
+}
+
+// build valida os campos obrigatorios do tipo e monta a acao.
+// baseDir e o diretorio do arquivo do modulo (para source relativo).
+func (s ActionSpec) build(baseDir string) (action, error) {
+	if s.Retries != nil {
+		if s.Type != ActionExtension && s.Type != ActionCommand {
+			return nil, fmt.Errorf("%s: retries so vale para %s e %s", s.Type, ActionExtension, ActionCommand)
+		}
+		if *s.Retries < 0 {
+			return nil, fmt.Errorf("%s: retries nao pode ser negativo", s.Type)
+		}
+	}
+
+	switch s.Type {
+	case ActionFile:
+		if s.Path == "" {
+			return nil, fmt.Errorf("file: path e obrigatorio")
+		}
+		if (s.Content == "") == (s.Source == "") {
+			return nil, fmt.Errorf("file: use content ou source (um dos dois)")
+		}
+		mode := os.FileMode(0o644)
+		if s.Mode != "" {
+			n, err := strconv.ParseUint(s.Mode, 8, 32)
+			if err != nil {
+				return nil, fmt.Errorf("file: mode invalido: %q", s.Mode)
+			}
+			mode = os.FileMode(n)
+		}
+		source := s.Source
+		if source != "" && !filepath.IsAbs(source) && !strings.HasPrefix(source, "~/") {
+			source = filepath.Join(baseDir, source)
+		}
+		return &fileAction{path: s.Path, content: s.Content, source: source, mode: mode}, nil
+	case ActionLine:
+		if s.Path == "" || s.Line == "" {
+			return nil, fmt.Errorf("line: path e line sao obrigatorios")
+		}
+		return &lineAction{path: s.Path, line: s.Line}, nil
+	case ActionDconf:
+		if !strings.HasPrefix(s.Key, "/") || s.Value == "" {
+			return nil, fmt.Errorf("dconf: key (absoluta) e value sao obrigatorios")
+		}
+		return &dconfAction{entry: gnome.DconfEntry{Path: s.Key, Value: s.Value}}, nil
+	case ActionExtension:
+		if s.UUID == "" {
+			return nil, fmt.Errorf("extension: uuid e obrigatorio")
+		}
+		title := s.Title
+		if title == "" {
+			title = s.UUID
+		}
+		return &extensionAction{uuid: s.UUID, title: title, retry: s.retry(module.DefaultRetry)}, nil
+	case ActionCommand:
+		if len(s.Run) == 0 {
+			return nil, fmt.Errorf("command: run e obrigatorio")
+		}
+		a := &commandAction{retry: s.retry(module.NoRetry)}
+		for _, c := range []struct {
+			field string
+			argv  []string
+			dst   *command
+		}{{"run", s.Run, &a.run}, {"check", s.Check, &a.checkCmd}, {"revert", s.Revert, &a.revertCmd}} {
+			cmd, err := parseCommand(c.argv)
+			if err != nil {
+				return nil, fmt.Errorf("command: %s: %w", c.field, err)
+			}
+			*c.dst = cmd
+		}
+		return a, nil
+	case "":
+		return nil, fmt.Errorf("type e obrigatorio")
+	default:
+		return nil, fmt.Errorf("tipo desconhecido: %q (aceitos: %s, %s, %s, %s, %s)",
+			s.Type, ActionFile, ActionLine, ActionDconf, ActionExtension, ActionCommand)
+	}
+}
+
+// expand troca o prefixo "~/" pelo home do usuario.
+func expand(sys module.System, path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(sys.HomeDir(), rest)
+	}
+	return path
+}
+
+// fileAction escreve um arquivo com conteudo fixo.
+type fileAction struct {
+	path    string
+	content string
+	source  string
+	mode    os.FileMode
+}
+
+func (a *fileAction) describe() string    { return "arquivo " + a.path }
+func (a *fileAction) resources() []string { return nil }
+
+func (a *fileAction) data(sys module.System) ([]byte, error) {
+	if a.source == "" {
+		return []byte(a.content), nil
+	}
+	data, err := sys.ReadFile(expand(sys, a.source))
+	if err != nil {
+		return nil, fmt.Errorf("ler source: %w", err)
+	}
+	return data, nil
+}
+
+func (a *fileAction) check(_ context.Context, sys module.System) (bool, error) {
+	want, err := a.data(sys)
+	if err != nil {
+		return false, err
+	}
+	got, err := sys.ReadFile(expand(sys, a.path))
+	if err != nil {
+		return false, nil
+	}
+	return string(got) == string(want), nil
+}
+
+func (a *fileAction) apply(_ context.Context, sys module.System, _ module.Reporter) error {
+	data, err := a.data(sys)
+	if err != nil {
+		return err
+	}
+	path := expand(sys, a.path)
+	if err := sys.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("criar diretorio: %w", err)
+	}
+	return sys.WriteFile(path, data, a.mode)
+}
+
+func (a *fileAction) plan(_ context.Context, sys module.System) ([]module.Action, error) {
+	data, err := a.data(sys)
+	if err != nil {
+		return nil, err
+	}
+	return []module.Action{{Kind: module.ActionWriteFile, Target: expand(sys, a.path), Content: data}}, nil
+}
+
+// revert apaga o arquivo, mas so se o conteudo ainda e o que a acao
+// escreve: um arquivo diferente pode ser do usuario (ja existia antes do
+// apply, ou foi editado depois) e e mantido, com aviso. O conteudo anterior
+// ao apply fica no backup daquela execucao (blueprint rollback <id>).
+func (a *fileAction) revert(_ context.Context, sys module.System, reporter module.Reporter) (bool, error) {
+	path := expand(sys, a.path)
+	if !sys.FileExists(path) {
+		return false, nil
+	}
+	want, err := a.data(sys)
+	if err != nil {
+		return false, err
+	}
+	got, err := sys.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("ler %s: %w", path, err)
+	}
+	if string(got) != string(want) {
+		reporter.Warn(fmt.Sprintf("%s mantido: o conteudo e diferente do que o blueprint escreve", path))
+		return false, nil
+	}
+	return true, sys.Remove(path)
+}
+
+// lineAction garante uma linha em um arquivo (ex: .bashrc).
+type lineAction struct {
+	path string
+	line string
+}
+
+func (a *lineAction) describe() string    { return fmt.Sprintf("linha em %s", a.path) }
+func (a *lineAction) resources() []string { return nil }
+
+func (a *lineAction) check(_ context.Context, sys module.System) (bool, error) {
+	data, err := sys.ReadFile(expand(sys, a.path))
+	if err != nil {
+		return false, nil
+	}
+	for _, l := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(l) == strings.TrimSpace(a.line) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (a *lineAction) apply(_ context.Context, sys module.System, _ module.Reporter) error {
+	_, err := sys.AppendToFileIfMissing(expand(sys, a.path), a.line)
+	return err
+}
+
+func (a *lineAction) plan(_ context.Context, sys module.System) ([]module.Action, error) {
+	return []module.Action{{Kind: module.ActionAppendLine, Target: expand(sys, a.path), Value: a.line}}, nil
+}
+
+func (a *lineAction) revert(_ context.Context, sys module.System, _ module.Reporter) (bool, error) {
+	return sys.RemoveLineFromFile(expand(sys, a.path), a.line)
+}
+
+// dconfAction escreve uma chave dconf.
+type dconfAction struct {
+	entry gnome.DconfEntry
+}
+
+func (a *dconfAction) describe() string    { return "dconf " + a.entry.Path }
+func (a *dconfAction) resources() []string { return []string{module.ResourceDconf} }
+
+func (a *dconfAction) check(ctx context.Context, sys module.System) (bool, error) {
+	out, err := sys.Exec(ctx, "dconf", "read", a.entry.Path)
+	if err != nil {
+		return false, nil
+	}
+	return strings.TrimSpace(out) == a.entry.Value, nil
+}
+
+func (a *dconfAction) apply(ctx context.Context, sys module.System, _ module.Reporter) error {
+	return gnome.ApplyDconf(ctx, sys, []gnome.DconfEntry{a.entry})
+}
+
+func (a *dconfAction) plan(_ context.Context, _ module.System) ([]module.Action, error) {
+	return []module.Action{{Kind: module.ActionDconfWrite, Target: a.entry.Path, Value: a.entry.Value}}, nil
+}
+
+func (a *dconfAction) revert(ctx context.Context, sys module.System, _ module.Reporter) (bool, error) {
+	return true, gnome.ResetDconf(ctx, sys, []gnome.DconfEntry{a.entry})
+}
+
+// extensionAction instala e ativa uma extensao do extensions.gnome.org.
+type extensionAction struct {
+	uuid  string
+	title string
+	retry module.RetryPolicy
+}
+
+func (a *extensionAction) describe() string    { return "extensao " + a.title }
+func (a *extensionAction) resources() []string { return nil }
+
+func (a *extensionAction) check(ctx context.Context, sys module.System) (bool, error) {
+	status, err := gnome.CheckExtension(ctx, sys, a.uuid, a.title)
+	return status.Kind == module.Installed, err
+}
+
+func (a *extensionAction) apply(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	out, _ := sys.Exec(ctx, "gnome-extensions", "show", a.uuid)
+	if !strings.Contains(out, a.uuid) {
+		gnomeVer, err := gnome.DetectVersion(ctx, sys)
+		if err != nil {
+			return err
+		}
+		reporter.Info(fmt.Sprintf("Baixando %s de extensions.gnome.org...", a.title))
+		if err := gnome.InstallWithRetry(ctx, sys, a.retry, reporter, a.uuid, gnomeVer, a.title); err != nil {
+			return err
+		}
+	}
+	if _, err := sys.Exec(ctx, "gnome-extensions", "enable", a.uuid); err != nil {
+		module.RequireSession(reporter, module.SessionRelogin, a.title+" sera ativado apos re-login")
+	}
+	return nil
+}
+
+func (a *extensionAction) plan(ctx context.Context, sys module.System) ([]module.Action, error) {
+	return gnome.PlanExtension(ctx, sys, a.uuid), nil
+}
+
+func (a *extensionAction) revert(ctx context.Context, sys module.System, _ module.Reporter) (bool, error) {
+	return true, gnome.UninstallExtension(ctx, sys, a.uuid)
+}
+
+// commandAction executa um comando arbitrario.
+type commandAction struct {
+	run       command
+	checkCmd  command
+	revertCmd command
+	retry     module.RetryPolicy
+}
+
+// command e um comando de uma acao. Um "sudo" no inicio (com opcoes como
+// -n) nao e executado: o comando roda como root pelo System.ExecPrivileged,
+// que respeita o --privilege (sudo, pkexec ou run0) e a senha pedida antes
+// da execucao; um sudo direto, sem terminal, travaria pedindo senha.
+type command struct {
+	argv       []string
+	privileged bool
+}
+
+// sudoFlags sao as opcoes do sudo aceitas (e descartadas) no inicio de um
+// comando. Opcoes que trocam o usuario ou o ambiente nao tem equivalente no
+// ExecPrivileged.
+var sudoFlags = map[string]bool{"-n": true, "-E": true, "-H": true, "-S": true, "--": true}
+
+// parseCommand separa o "sudo" inicial de argv.
+func parseCommand(argv []string) (command, error) {
+	if len(argv) == 0 || argv[0] != "sudo" {
+		return command{argv: argv}, nil
+	}
+	rest := argv[1:]
+	for len(rest) > 0 && strings.HasPrefix(rest[0], "-") {
+		if !sudoFlags[rest[0]] {
+			return command{}, fmt.Errorf("opcao do sudo nao suportada: %s (o comando roda como root pelo backend de --privilege)", rest[0])
+		}
+		rest = rest[1:]
+	}
+	if len(rest) == 0 {
+		return command{}, fmt.Errorf("sudo sem comando")
+	}
+	return command{argv: rest, privileged: true}, nil
+}
+
+func (c command) empty() bool { return len(c.argv) == 0 }
+
+func (c command) String() string { return strings.Join(c.argv, " ") }
+
+// exec executa o comando e retorna a saida combinada.
+func (c command) exec(ctx context.Context, sys module.System) (string, error) {
+	if c.privileged {
+		return sys.ExecPrivileged(ctx, c.argv[0], c.argv[1:]...)
+	}
+	return sys.Exec(ctx, c.argv[0], c.argv[1:]...)
+}
+
+func (a *commandAction) describe() string {
+	if a.run.privileged {
+		return "comando (root) " + a.run.String()
+	}
+	return "comando " + a.run.String()
+}
+
+// resources declara sudo se algum dos comandos roda como root.
+func (a *commandAction) resources() []string {
+	for _, c := range []command{a.run, a.checkCmd, a.revertCmd} {
+		if c.privileged {
+			return []string{module.ResourceSudo}
+		}
+	}
+	return nil
+}
+
+func (a *commandAction) check(ctx context.Context, sys module.System) (bool, error) {
+	if a.checkCmd.empty() {
+		return false, nil
+	}
+	_, err := a.checkCmd.exec(ctx, sys)
+	return err == nil, nil
+}
+
+// apply executa o comando. A saida vai so para o log da execucao (o
+// System.ExecStream registra cada linha): no reporter, cada linha viraria
+// uma nota no resumo. As ultimas linhas entram na mensagem de erro.
+func (a *commandAction) apply(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	return module.Retry(ctx, a.retry, reporter, a.describe(), func(ctx context.Context) error {
+		var out outputTail
+		var err error
+		if a.run.privileged {
+			var combined string
+			combined, err = a.run.exec(ctx, sys)
+			for _, line := range strings.Split(combined, "\n") {
+				out.add(line)
+			}
+		} else {
+			err = sys.ExecStream(ctx, out.add, a.run.argv[0], a.run.argv[1:]...)
+		}
+		if err != nil {
+			return out.wrap(err)
+		}
+		return nil
+	})
+}
+
+// tailLines e quantas linhas da saida de um comando com erro sao mantidas.
+const tailLines = 5
+
+// outputTail guarda as ultimas linhas da saida de um comando.
+type outputTail struct {
+	lines []string
+}
+
+func (t *outputTail) add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	t.lines = append(t.lines, line)
+	if len(t.lines) > tailLines {
+		t.lines = t.lines[1:]
+	}
+}
+
+// wrap acrescenta as ultimas linhas da saida ao erro do comando.
+func (t *outputTail) wrap(err error) error {
+	if len(t.lines) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w: %s", err, strings.Join(t.lines, "\n"))
+}
+
+func (a *commandAction) plan(_ context.Context, _ module.System) ([]module.Action, error) {
+	return []module.Action{{Kind: module.ActionRunCommand, Target: a.run.String(), Privileged: a.run.privileged}}, nil
+}
+
+func (a *commandAction) revert(ctx context.Context, sys module.System, _ module.Reporter) (bool, error) {
+	if a.revertCmd.empty() {
+		return false, nil
+	}
+	if out, err := a.revertCmd.exec(ctx, sys); err != nil {
+		return false, fmt.Errorf("%s: %w", strings.TrimSpace(out), err)
+	}
+	return true, nil
+}
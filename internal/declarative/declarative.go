@@ -0,0 +1,260 @@
+// Package declarative carrega modulos definidos em TOML (configs/modules/*.toml
+// e ~/.config/blueprint/modules/*.toml). Cada arquivo descreve um modulo com
+// uma lista de acoes tipadas (arquivo, linha, dconf, extensao GNOME, comando);
+// Guard, Check, Apply e Revert sao derivados das acoes.
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ale/blueprint/internal/gnome"
+	"github.com/ale/blueprint/internal/module"
+)
+
+// Spec e o conteudo de um arquivo de modulo.
+//
+//	name = "git-aliases"
+//	description = "Aliases do git no bashrc"
+//	tags = ["shell"]
+//
+//	[guard]
+//	commands = ["git"]
+//
+//	[[actions]]
+//	type = "line"
+//	path = "~/.bashrc"
+//	line = "alias gs='git status'"
+type Spec struct {
+	Name        string       `toml:"name"`
+	Description string       `toml:"description"`
+	Tags        []string     `toml:"tags"`
+	Requires    []string     `toml:"requires"`
+	Guard       GuardSpec    `toml:"guard"`
+	Actions     []ActionSpec `toml:"actions"`
+}
+
+// GuardSpec define quando o modulo deve ser pulado.
+type GuardSpec struct {
+	SkipContainer bool     `toml:"skip_container"` // pula dentro de containers
+	SkipWSL       bool     `toml:"skip_wsl"`       // pula no WSL
+	Desktop       bool     `toml:"desktop"`        // exige sessao GNOME (gnome.ShouldRunGuard)
+	Commands      []string `toml:"commands"`       // comandos que precisam existir no PATH
+}
+
+// Module e um modulo declarativo. Implementa Guard, Checker, Planner,
+// Applier, Reverter, Dependent e Exclusive a partir do Spec.
+type Module struct {
+	spec    Spec
+	source  string // arquivo de origem
+	actions []action
+}
+
+// Parse decodifica e valida um arquivo de modulo. source e o caminho do
+// arquivo, usado nas mensagens de erro e para resolver caminhos relativos.
+func Parse(data []byte, source string) (*Module, error) {
+	var spec Spec
+	md, err := toml.Decode(string(data), &spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return nil, fmt.Errorf("%s: chave desconhecida: %s", source, strings.Join(keys, ", "))
+	}
+
+	if spec.Name == "" {
+		return nil, fmt.Errorf("%s: name e obrigatorio", source)
+	}
+	if len(spec.Tags) == 0 {
+		return nil, fmt.Errorf("%s: modulo %s sem tags (nao entraria em nenhum perfil)", source, spec.Name)
+	}
+	if len(spec.Actions) == 0 {
+		return nil, fmt.Errorf("%s: modulo %s sem acoes", source, spec.Name)
+	}
+
+	m := &Module{spec: spec, source: source}
+	for i, a := range spec.Actions {
+		act, err := a.build(filepath.Dir(source))
+		if err != nil {
+			return nil, fmt.Errorf("%s: actions[%d]: %w", source, i, err)
+		}
+		m.actions = append(m.actions, act)
+	}
+	return m, nil
+}
+
+// Load le e decodifica um arquivo de modulo.
+func Load(sys module.System, path string) (*Module, error) {
+	data, err := sys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ler %s: %w", path, err)
+	}
+	return Parse(data, path)
+}
+
+// Discover lista os arquivos *.toml dos diretorios, em ordem alfabetica por
+// diretorio. Diretorios inexistentes sao ignorados.
+func Discover(dirs ...string) []string {
+	var paths []string
+	for _, dir := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.toml"))
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+// Register carrega os arquivos e registra os modulos. Um arquivo invalido
+// ou com nome repetido (inclusive de modulos Go) e pulado: os erros
+// retornados viram aviso e aparecem no doctor, sem impedir o uso da CLI.
+func Register(reg *module.Registry, sys module.System, paths []string) []error {
+	var errs []error
+	for _, path := range paths {
+		m, err := Load(sys, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := reg.Register(m); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return errs
+}
+
+// Source retorna o arquivo de onde o modulo foi carregado.
+func (m *Module) Source() string { return m.source }
+
+func (m *Module) Name() string        { return m.spec.Name }
+func (m *Module) Description() string { return m.spec.Description }
+func (m *Module) Tags() []string      { return m.spec.Tags }
+func (m *Module) Requires() []string  { return m.spec.Requires }
+
+// Resources agrega os recursos exclusivos das acoes (ex: dconf).
+func (m *Module) Resources() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, a := range m.actions {
+		for _, r := range a.resources() {
+			if !seen[r] {
+				seen[r] = true
+				out = append(out, r)
+			}
+		}
+	}
+	return out
+}
+
+func (m *Module) ShouldRun(_ context.Context, sys module.System) (bool, string) {
+	g := m.spec.Guard
+	if g.SkipContainer && sys.IsContainer() {
+		return false, "dentro de container"
+	}
+	if g.SkipWSL && sys.IsWSL() {
+		return false, "no WSL"
+	}
+	if g.Desktop {
+		if ok, reason := gnome.ShouldRunGuard(sys); !ok {
+			return false, reason
+		}
+	}
+	for _, cmd := range g.Commands {
+		if !sys.CommandExists(cmd) {
+			return false, fmt.Sprintf("%s nao encontrado", cmd)
+		}
+	}
+	return true, ""
+}
+
+// Check verifica cada acao: todas aplicadas = Installed, nenhuma = Missing,
+// algumas = Partial (com as pendentes na mensagem).
+func (m *Module) Check(ctx context.Context, sys module.System) (module.Status, error) {
+	var pending []string
+	for _, a := range m.actions {
+		done, err := a.check(ctx, sys)
+		if err != nil {
+			return module.Status{}, fmt.Errorf("%s: %w", a.describe(), err)
+		}
+		if !done {
+			pending = append(pending, a.describe())
+		}
+	}
+
+	switch len(pending) {
+	case 0:
+		return module.Status{Kind: module.Installed, Message: "todas as acoes aplicadas"}, nil
+	case len(m.actions):
+		return module.Status{Kind: module.Missing, Message: "nenhuma acao aplicada"}, nil
+	default:
+		return module.Status{Kind: module.Partial, Message: "pendente: " + strings.Join(pending, "; ")}, nil
+	}
+}
+
+// Plan descreve as acoes pendentes (as que o check nao considera aplicadas).
+func (m *Module) Plan(ctx context.Context, sys module.System) ([]module.Action, error) {
+	var actions []module.Action
+	for _, a := range m.actions {
+		done, err := a.check(ctx, sys)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", a.describe(), err)
+		}
+		if done {
+			continue
+		}
+		planned, err := a.plan(ctx, sys)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", a.describe(), err)
+		}
+		actions = append(actions, planned...)
+	}
+	return actions, nil
+}
+
+// Apply executa as acoes pendentes, na ordem do arquivo.
+func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	total := len(m.actions)
+	for i, a := range m.actions {
+		reporter.Step(i+1, total, a.describe())
+		done, err := a.check(ctx, sys)
+		if err != nil {
+			return fmt.Errorf("%s: %w", a.describe(), err)
+		}
+		if done {
+			reporter.Success("ja aplicado")
+			continue
+		}
+		if err := a.apply(ctx, sys, reporter); err != nil {
+			return fmt.Errorf("%s: %w", a.describe(), err)
+		}
+		reporter.Success("aplicado")
+	}
+	return nil
+}
+
+// Revert desfaz as acoes em ordem inversa. Acoes sem revert (comandos sem
+// "revert") sao mantidas e informadas.
+func (m *Module) Revert(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	total := len(m.actions)
+	for i := total - 1; i >= 0; i-- {
+		a := m.actions[i]
+		reporter.Step(total-i, total, "Desfazendo: "+a.describe())
+		reverted, err := a.revert(ctx, sys, reporter)
+		if err != nil {
+			return fmt.Errorf("%s: %w", a.describe(), err)
+		}
+		if reverted {
+			reporter.Success("desfeito")
+		} else {
+			reporter.Info("nada a desfazer")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,462 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/module/moduletest"
+	"github.com/ale/blueprint/internal/system"
+)
+
+const source = "/repo/configs/modules/exemplo.toml"
+
+const exemplo = `
+name = "exemplo"
+description = "Modulo de teste"
+tags = ["shell"]
+requires = ["starship"]
+
+[guard]
+skip_container = true
+commands = ["git"]
+
+[[actions]]
+type = "file"
+path = "~/.config/exemplo/config"
+content = "a = 1\n"
+
+[[actions]]
+type = "line"
+path = "~/.bashrc"
+line = "source ~/.config/exemplo/config"
+
+[[actions]]
+type = "dconf"
+key = "/org/gnome/desktop/interface/color-scheme"
+value = "'prefer-dark'"
+
+[[actions]]
+type = "command"
+run = ["git", "config", "--global", "pull.rebase", "true"]
+check = ["sh", "-c", "test \"$(git config --global pull.rebase)\" = true"]
+revert = ["git", "config", "--global", "--unset", "pull.rebase"]
+`
+
+func parse(t *testing.T, content string) *Module {
+	t.Helper()
+	m, err := Parse([]byte(content), source)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	return m
+}
+
+func TestParse(t *testing.T) {
+	m := parse(t, exemplo)
+
+	if m.Name() != "exemplo" || m.Description() != "Modulo de teste" {
+		t.Errorf("Name/Description = %q/%q", m.Name(), m.Description())
+	}
+	if strings.Join(m.Tags(), ",") != "shell" || strings.Join(m.Requires(), ",") != "starship" {
+		t.Errorf("Tags/Requires = %v/%v", m.Tags(), m.Requires())
+	}
+	if got := m.Resources(); len(got) != 1 || got[0] != module.ResourceDconf {
+		t.Errorf("Resources() = %v, esperava [dconf]", got)
+	}
+	if len(m.actions) != 4 {
+		t.Errorf("esperava 4 acoes, obteve %d", len(m.actions))
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"sem nome", "tags = [\"shell\"]\n", "name e obrigatorio"},
+		{"sem tags", "name = \"x\"\n", "sem tags"},
+		{"sem acoes", "name = \"x\"\ntags = [\"shell\"]\n", "sem acoes"},
+		{"chave desconhecida", "name = \"x\"\ntags = [\"shell\"]\ncolor = 1\n", "chave desconhecida: color"},
+		{"tipo desconhecido", "name = \"x\"\ntags = [\"shell\"]\n[[actions]]\ntype = \"rpm\"\n", `actions[0]: tipo desconhecido: "rpm"`},
+		{"file sem conteudo", "name = \"x\"\ntags = [\"shell\"]\n[[actions]]\ntype = \"file\"\npath = \"~/a\"\n", "content ou source"},
+		{"mode invalido", "name = \"x\"\ntags = [\"shell\"]\n[[actions]]\ntype = \"file\"\npath = \"~/a\"\ncontent = \"x\"\nmode = \"rw\"\n", "mode invalido"},
+		{"dconf relativo", "name = \"x\"\ntags = [\"shell\"]\n[[actions]]\ntype = \"dconf\"\nkey = \"org/x\"\nvalue = \"1\"\n", "dconf: key"},
+		{"command sem run", "name = \"x\"\ntags = [\"shell\"]\n[[actions]]\ntype = \"command\"\n", "run e obrigatorio"},
+		{"retries em line", "name = \"x\"\ntags = [\"shell\"]\n[[actions]]\ntype = \"line\"\npath = \"~/a\"\nline = \"x\"\nretries = 1\n", "retries so vale"},
+		{"retries negativo", "name = \"x\"\ntags = [\"shell\"]\n[[actions]]\ntype = \"command\"\nrun = [\"true\"]\nretries = -1\n", "nao pode ser negativo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content), source)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("erro = %v, esperava conter %q", err, tt.wantErr)
+			}
+			if err != nil && !strings.HasPrefix(err.Error(), source) {
+				t.Errorf("erro deveria comecar com o arquivo: %v", err)
+			}
+		})
+	}
+}
+
+func TestShouldRun(t *testing.T) {
+	m := parse(t, exemplo)
+
+	mock := system.NewMock()
+	if ok, reason := m.ShouldRun(context.Background(), mock); ok || reason != "git nao encontrado" {
+		t.Errorf("ShouldRun() = %v, %q", ok, reason)
+	}
+
+	mock.Commands["git"] = true
+	if ok, _ := m.ShouldRun(context.Background(), mock); !ok {
+		t.Error("esperava rodar com git disponivel")
+	}
+
+	mock.Container = true
+	if ok, _ := m.ShouldRun(context.Background(), mock); ok {
+		t.Error("esperava pular dentro de container")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	m := parse(t, exemplo)
+	checkCmd := `sh -c test "$(git config --global pull.rebase)" = true`
+
+	mock := system.NewMock()
+	mock.ExecResults[checkCmd] = system.ExecResult{Err: fmt.Errorf("exit 1")}
+	status, err := m.Check(context.Background(), mock)
+	if err != nil || status.Kind != module.Missing {
+		t.Fatalf("Check() = %v, %v; esperava Missing", status, err)
+	}
+
+	mock.Files["/home/test/.bashrc"] = []byte("# bashrc\nsource ~/.config/exemplo/config\n")
+	status, _ = m.Check(context.Background(), mock)
+	if status.Kind != module.Partial || !strings.Contains(status.Message, "arquivo ~/.config/exemplo/config") {
+		t.Errorf("Check() = %v; esperava Partial com o arquivo pendente", status)
+	}
+
+	mock.Files["/home/test/.config/exemplo/config"] = []byte("a = 1\n")
+	mock.ExecResults["dconf read /org/gnome/desktop/interface/color-scheme"] = system.ExecResult{Output: "'prefer-dark'\n"}
+	mock.ExecResults[checkCmd] = system.ExecResult{}
+	status, _ = m.Check(context.Background(), mock)
+	if status.Kind != module.Installed {
+		t.Errorf("Check() = %v; esperava Installed", status)
+	}
+}
+
+func TestApply(t *testing.T) {
+	m := parse(t, exemplo)
+	mock := system.NewMock()
+	mock.ExecResults[`sh -c test "$(git config --global pull.rebase)" = true`] = system.ExecResult{Err: fmt.Errorf("exit 1")}
+	mock.Files["/home/test/.bashrc"] = []byte("source ~/.config/exemplo/config\n")
+
+	if err := m.Apply(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if got := string(mock.Files["/home/test/.config/exemplo/config"]); got != "a = 1\n" {
+		t.Errorf("arquivo = %q", got)
+	}
+	if got := string(mock.Files["/home/test/.bashrc"]); strings.Count(got, "exemplo") != 1 {
+		t.Errorf("linha duplicada no bashrc: %q", got)
+	}
+	for _, want := range []string{
+		"dconf write /org/gnome/desktop/interface/color-scheme 'prefer-dark'",
+		"git config --global pull.rebase true",
+	} {
+		if !contains(mock.ExecLog, want) {
+			t.Errorf("esperava %q em %v", want, mock.ExecLog)
+		}
+	}
+}
+
+func TestApply_CommandRetries(t *testing.T) {
+	m := parse(t, `
+name = "x"
+tags = ["shell"]
+
+[[actions]]
+type = "command"
+run = ["curl", "-fsSLo", "/tmp/x", "https://example.com/x"]
+retries = 2
+`)
+	cmd := m.actions[0].(*commandAction)
+	if cmd.retry.Attempts != 3 {
+		t.Fatalf("retries = 2 deveria dar 3 tentativas, obteve %d", cmd.retry.Attempts)
+	}
+	cmd.retry.Delay = 0
+
+	mock := system.NewMock()
+	mock.ExecResults["curl -fsSLo /tmp/x https://example.com/x"] = system.ExecResult{Err: fmt.Errorf("exit 6")}
+	if err := m.Apply(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Fatal("esperava erro")
+	}
+	if len(mock.ExecLog) != 3 {
+		t.Errorf("esperava 3 tentativas, obteve %v", mock.ExecLog)
+	}
+}
+
+// recorder guarda as mensagens Info (que viram notas no resumo) e Warn.
+type recorder struct {
+	module.Reporter
+	infos, warns []string
+}
+
+func (r *recorder) Info(msg string) { r.infos = append(r.infos, msg) }
+func (r *recorder) Warn(msg string) { r.warns = append(r.warns, msg) }
+
+func TestApply_CommandOutputNotInfo(t *testing.T) {
+	m := parse(t, `
+name = "x"
+tags = ["shell"]
+
+[[actions]]
+type = "line"
+path = "~/.bashrc"
+line = "export X=1"
+
+[[actions]]
+type = "command"
+run = ["make", "install"]
+`)
+	mock := system.NewMock()
+	mock.Files["/home/test/.bashrc"] = []byte("export X=1\n")
+	mock.ExecResults["make install"] = system.ExecResult{Output: "compilando\ncc: erro fatal", Err: fmt.Errorf("exit status 2")}
+	reporter := &recorder{Reporter: moduletest.NoopReporter()}
+
+	err := m.Apply(context.Background(), mock, reporter)
+	if err == nil || !strings.Contains(err.Error(), "cc: erro fatal") {
+		t.Errorf("esperava o fim da saida no erro, obteve %v", err)
+	}
+	if len(reporter.infos) != 0 {
+		t.Errorf("progresso e saida de comando nao devem virar notas: %v", reporter.infos)
+	}
+}
+
+func TestCommand_SudoUsesPrivilegeBackend(t *testing.T) {
+	m := parse(t, `
+name = "x"
+tags = ["system"]
+
+[[actions]]
+type = "command"
+run = ["sudo", "-n", "dnf", "install", "-y", "htop"]
+check = ["sudo", "rpm", "-q", "htop"]
+revert = ["sudo", "dnf", "remove", "-y", "htop"]
+`)
+	if got := m.Resources(); len(got) != 1 || got[0] != module.ResourceSudo {
+		t.Errorf("Resources() = %v", got)
+	}
+
+	mock := system.NewMock()
+	mock.Privilege = "pkexec"
+	mock.ExecResults["pkexec rpm -q htop"] = system.ExecResult{Err: fmt.Errorf("exit 1")}
+	if err := m.Apply(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if err := m.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	want := []string{"pkexec rpm -q htop", "pkexec dnf install -y htop", "pkexec dnf remove -y htop"}
+	if strings.Join(mock.ExecLog, "|") != strings.Join(want, "|") {
+		t.Errorf("ExecLog = %v, esperava %v", mock.ExecLog, want)
+	}
+
+	actions, _ := m.actions[0].plan(context.Background(), mock)
+	if a := actions[0]; !a.Privileged || a.Target != "dnf install -y htop" {
+		t.Errorf("plan = %+v", a)
+	}
+}
+
+func TestCommand_SudoOtherUserRejected(t *testing.T) {
+	_, err := Parse([]byte(`
+name = "x"
+tags = ["system"]
+
+[[actions]]
+type = "command"
+run = ["sudo", "-u", "postgres", "psql"]
+`), source)
+	if err == nil || !strings.Contains(err.Error(), "opcao do sudo nao suportada: -u") {
+		t.Errorf("erro = %v", err)
+	}
+}
+
+func TestParse_RetryDefaults(t *testing.T) {
+	m := parse(t, `
+name = "x"
+tags = ["desktop"]
+
+[[actions]]
+type = "extension"
+uuid = "a@b"
+
+[[actions]]
+type = "command"
+run = ["true"]
+`)
+	if got := m.actions[0].(*extensionAction).retry; got != module.DefaultRetry {
+		t.Errorf("extension: retry = %+v, esperava DefaultRetry", got)
+	}
+	if got := m.actions[1].(*commandAction).retry; got != module.NoRetry {
+		t.Errorf("command: retry = %+v, esperava NoRetry", got)
+	}
+}
+
+func TestApply_SourceRelativeToModule(t *testing.T) {
+	m := parse(t, `
+name = "x"
+tags = ["shell"]
+[[actions]]
+type = "file"
+path = "~/.inputrc"
+source = "files/inputrc"
+mode = "0600"
+`)
+	mock := system.NewMock()
+	mock.Files["/repo/configs/modules/files/inputrc"] = []byte("set bell-style none\n")
+
+	if err := m.Apply(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if got := string(mock.Files["/home/test/.inputrc"]); got != "set bell-style none\n" {
+		t.Errorf(".inputrc = %q", got)
+	}
+}
+
+func TestRevert(t *testing.T) {
+	m := parse(t, exemplo)
+	mock := system.NewMock()
+	mock.Files["/home/test/.config/exemplo/config"] = []byte("a = 1\n")
+	mock.Files["/home/test/.bashrc"] = []byte("# bashrc\nsource ~/.config/exemplo/config\n")
+
+	if err := m.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if _, ok := mock.Files["/home/test/.config/exemplo/config"]; ok {
+		t.Error("arquivo deveria ser removido")
+	}
+	if got := string(mock.Files["/home/test/.bashrc"]); strings.Contains(got, "exemplo") {
+		t.Errorf("linha deveria ser removida: %q", got)
+	}
+
+	// Ordem inversa: o comando (ultima acao) e desfeito primeiro
+	want := []string{
+		"git config --global --unset pull.rebase",
+		"dconf reset /org/gnome/desktop/interface/color-scheme",
+	}
+	if len(mock.ExecLog) != 2 || mock.ExecLog[0] != want[0] || mock.ExecLog[1] != want[1] {
+		t.Errorf("ExecLog = %v, esperava %v", mock.ExecLog, want)
+	}
+}
+
+func TestRevert_KeepsFileNotWrittenByBlueprint(t *testing.T) {
+	m := parse(t, `
+name = "x"
+tags = ["shell"]
+
+[[actions]]
+type = "file"
+path = "~/.inputrc"
+content = "set bell-style none\n"
+`)
+	mock := system.NewMock()
+	mock.Files["/home/test/.inputrc"] = []byte("# do usuario\nset editing-mode vi\n")
+	reporter := &recorder{Reporter: moduletest.NoopReporter()}
+
+	if err := m.Revert(context.Background(), mock, reporter); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if _, ok := mock.Files["/home/test/.inputrc"]; !ok {
+		t.Error("arquivo com outro conteudo nao deveria ser apagado")
+	}
+	if len(reporter.warns) != 1 || !strings.Contains(reporter.warns[0], "/home/test/.inputrc mantido") {
+		t.Errorf("warns = %v", reporter.warns)
+	}
+}
+
+func TestRepoModules(t *testing.T) {
+	paths := Discover(filepath.Join("..", "..", "configs", "modules"))
+	if len(paths) == 0 {
+		t.Fatal("nenhum modulo em configs/modules")
+	}
+	reg := module.NewRegistry()
+	if errs := Register(reg, system.NewReal(), paths); len(errs) > 0 {
+		t.Errorf("modulos do repo invalidos: %v", errs)
+	}
+	m, ok := reg.ByName("readline")
+	if !ok {
+		t.Fatal("readline nao registrado")
+	}
+	if got := m.(*Module).actions[3].(*lineAction).line; got != `"\e[A": history-search-backward` {
+		t.Errorf("linha = %q", got)
+	}
+}
+
+func TestRegister_DuplicateName(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/a/one.toml"] = []byte("name = \"dup\"\ntags = [\"shell\"]\n[[actions]]\ntype = \"command\"\nrun = [\"true\"]\n")
+	mock.Files["/b/two.toml"] = mock.Files["/a/one.toml"]
+
+	reg := module.NewRegistry()
+	errs := Register(reg, mock, []string{"/a/one.toml", "/b/two.toml"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "/b/two.toml: modulo ja registrado: dup") {
+		t.Errorf("erros = %v", errs)
+	}
+}
+
+func TestRegister_SkipsInvalidFile(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/a/typo.toml"] = []byte("name = \"typo\"\ntags = [\"shell\"\n")
+	mock.Files["/a/ok.toml"] = []byte("name = \"ok\"\ntags = [\"shell\"]\n[[actions]]\ntype = \"command\"\nrun = [\"true\"]\n")
+
+	reg := module.NewRegistry()
+	errs := Register(reg, mock, []string{"/a/typo.toml", "/a/ok.toml"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "/a/typo.toml") {
+		t.Errorf("erros = %v; esperava so o do arquivo invalido", errs)
+	}
+	if _, ok := reg.ByName("ok"); !ok {
+		t.Error("modulo valido deveria ser registrado mesmo com outro arquivo invalido")
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPlan(t *testing.T) {
+	m := parse(t, exemplo)
+	mock := system.NewMock()
+	mock.Files["/home/test/.bashrc"] = []byte("source ~/.config/exemplo/config\n")
+	mock.ExecResults["dconf read /org/gnome/desktop/interface/color-scheme"] = system.ExecResult{Output: "'prefer-dark'"}
+	mock.ExecResults[`sh -c test "$(git config --global pull.rebase)" = true`] = system.ExecResult{Err: fmt.Errorf("exit 1")}
+
+	actions, err := m.Plan(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	// Linha e dconf ja aplicados; sobram o arquivo e o comando
+	if len(actions) != 2 {
+		t.Fatalf("Plan() = %v", actions)
+	}
+	if a := actions[0]; a.Kind != module.ActionWriteFile || a.Target != "/home/test/.config/exemplo/config" || string(a.Content) != "a = 1\n" {
+		t.Errorf("acao 0 = %+v", a)
+	}
+	if a := actions[1]; a.Kind != module.ActionRunCommand || a.Target != "git config --global pull.rebase true" {
+		t.Errorf("acao 1 = %+v", a)
+	}
+	if len(mock.Files) != 1 {
+		t.Errorf("Plan nao deveria escrever arquivos: %v", mock.Files)
+	}
+}
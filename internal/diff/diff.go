@@ -0,0 +1,242 @@
+// Package diff gera diffs unificados (estilo "diff -u") entre duas versoes
+// de um arquivo texto. Usado pelo dry-run e pelo "blueprint plan" para
+// mostrar o que mudaria antes de escrever.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Context e o numero de linhas de contexto em volta de cada mudanca.
+const Context = 3
+
+// maxCells limita a tabela do LCS (linhas antigas x novas). Acima disso o
+// arquivo inteiro e mostrado como substituido.
+const maxCells = 4_000_000
+
+// Cores ANSI usadas por Colorize.
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+	colorBold  = "\033[1m"
+)
+
+// op e uma linha do diff: ' ' (igual), '-' (removida) ou '+' (adicionada).
+type op struct {
+	kind byte
+	text string
+}
+
+// Unified retorna o diff unificado de old para new, com cabecalhos
+// "--- oldName" e "+++ newName". Retorna "" se o conteudo e igual.
+func Unified(oldName, newName string, old, new []byte) string {
+	if string(old) == string(new) {
+		return ""
+	}
+	a, b := splitLines(string(old)), splitLines(string(new))
+	ops := lineOps(a, b)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops) {
+		writeHunk(&sb, ops[h[0]:h[1]], h[2], h[3])
+	}
+	return sb.String()
+}
+
+// Colorize colore um diff unificado para o terminal: remocoes em vermelho,
+// adicoes em verde, cabecalhos de hunk em ciano.
+func Colorize(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	var sb strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		body := strings.TrimSuffix(line, "\n")
+		nl := line[len(body):]
+		switch {
+		case strings.HasPrefix(body, "---"), strings.HasPrefix(body, "+++"):
+			sb.WriteString(colorBold + body + colorReset + nl)
+		case strings.HasPrefix(body, "@@"):
+			sb.WriteString(colorCyan + body + colorReset + nl)
+		case strings.HasPrefix(body, "-"):
+			sb.WriteString(colorRed + body + colorReset + nl)
+		case strings.HasPrefix(body, "+"):
+			sb.WriteString(colorGreen + body + colorReset + nl)
+		default:
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+// splitLines separa o texto em linhas, mantendo a marca de "sem newline no
+// final" como no diff -u.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	last := lines[len(lines)-1]
+	if !strings.HasSuffix(last, "\n") {
+		lines[len(lines)-1] = last + "\n\\ No newline at end of file\n"
+	}
+	return lines
+}
+
+// lineOps calcula a sequencia de operacoes via maior subsequencia comum.
+func lineOps(a, b []string) []op {
+	// Prefixo e sufixo comuns ficam fora da tabela
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, l := range a[:pre] {
+		ops = append(ops, op{' ', l})
+	}
+	ops = append(ops, lcsOps(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, l := range a[len(a)-suf:] {
+		ops = append(ops, op{' ', l})
+	}
+	return ops
+}
+
+func lcsOps(a, b []string) []op {
+	n, m := len(a), len(b)
+	if n*m > maxCells {
+		ops := make([]op, 0, n+m)
+		for _, l := range a {
+			ops = append(ops, op{'-', l})
+		}
+		for _, l := range b {
+			ops = append(ops, op{'+', l})
+		}
+		return ops
+	}
+
+	// lcs[i][j] = tamanho da LCS de a[i:] e b[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
+
+// hunks agrupa as mudancas com Context linhas em volta. Cada hunk e
+// {inicio, fim, linha antiga inicial, linha nova inicial} (linhas a partir de 0).
+func hunks(ops []op) [][4]int {
+	var result [][4]int
+	oldLine, newLine := 0, 0
+	lineAt := make([][2]int, len(ops))
+	for i, o := range ops {
+		lineAt[i] = [2]int{oldLine, newLine}
+		if o.kind != '+' {
+			oldLine++
+		}
+		if o.kind != '-' {
+			newLine++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(i-Context, 0)
+		end := i
+		// Estende enquanto houver mudancas a ate 2*Context linhas iguais
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*Context {
+				end = min(end+Context, len(ops))
+				break
+			}
+			end = run
+		}
+		result = append(result, [4]int{start, end, lineAt[start][0], lineAt[start][1]})
+		i = end
+	}
+	return result
+}
+
+func writeHunk(sb *strings.Builder, ops []op, oldStart, newStart int) {
+	var oldCount, newCount int
+	for _, o := range ops {
+		if o.kind != '+' {
+			oldCount++
+		}
+		if o.kind != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, o := range ops {
+		sb.WriteByte(o.kind)
+		sb.WriteString(o.text)
+	}
+}
+
+// hunkRange formata "inicio,quantidade" como o diff -u (inicio a partir de 1;
+// com quantidade zero, aponta para a linha anterior).
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,81 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified_Equal(t *testing.T) {
+	if got := Unified("a", "b", []byte("x\n"), []byte("x\n")); got != "" {
+		t.Errorf("Unified() = %q, esperava vazio", got)
+	}
+}
+
+func TestUnified_NewFile(t *testing.T) {
+	got := Unified("/dev/null", "/tmp/f", nil, []byte("a\nb\n"))
+	want := "--- /dev/null\n+++ /tmp/f\n@@ -0,0 +1,2 @@\n+a\n+b\n"
+	if got != want {
+		t.Errorf("Unified() =\n%s\nesperava\n%s", got, want)
+	}
+}
+
+func TestUnified_Context(t *testing.T) {
+	var old, new []string
+	for i := 1; i <= 20; i++ {
+		line := string(rune('a' + i - 1))
+		old = append(old, line)
+		if i == 10 {
+			line = "X"
+		}
+		new = append(new, line)
+	}
+	new = append(new, "fim")
+
+	got := Unified("f", "f", []byte(strings.Join(old, "\n")+"\n"), []byte(strings.Join(new, "\n")+"\n"))
+	want := `--- f
++++ f
+@@ -7,7 +7,7 @@
+ g
+ h
+ i
+-j
++X
+ k
+ l
+ m
+@@ -18,3 +18,4 @@
+ r
+ s
+ t
++fim
+`
+	if got != want {
+		t.Errorf("Unified() =\n%s\nesperava\n%s", got, want)
+	}
+}
+
+func TestUnified_MergesCloseChanges(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n"
+	new := "1\nA\n3\n4\n5\n6\nB\n8\n"
+	got := Unified("f", "f", []byte(old), []byte(new))
+	if strings.Count(got, "\n@@ ") != 1 || !strings.Contains(got, "@@ -1,8 +1,8 @@") {
+		t.Errorf("esperava um unico hunk:\n%s", got)
+	}
+}
+
+func TestUnified_NoNewlineAtEnd(t *testing.T) {
+	got := Unified("f", "f", []byte("a"), []byte("a\n"))
+	want := "--- f\n+++ f\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n"
+	if got != want {
+		t.Errorf("Unified() = %q, esperava %q", got, want)
+	}
+}
+
+func TestColorize(t *testing.T) {
+	got := Colorize("--- a\n+++ a\n@@ -1 +1 @@\n-x\n+y\n ctx\n")
+	for _, want := range []string{colorRed + "-x" + colorReset, colorGreen + "+y" + colorReset, colorCyan + "@@ -1 +1 @@", "\n ctx\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Colorize() = %q, esperava conter %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Package doctor diagnostica o ambiente: verifica tudo de que os modulos
+// dependem (sudo, ferramentas do GNOME, containers, rede, diretorios) e
+// descreve cada problema com uma acao sugerida. Usado por "blueprint doctor".
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
+)
+
+// Severity classifica um achado.
+type Severity int
+
+const (
+	OK   Severity = iota // Tudo certo
+	Warn                 // Algum modulo pode ser pulado ou falhar
+	Fail                 // O blueprint (ou varios modulos) nao vai funcionar
+)
+
+// String retorna a representacao textual da severidade.
+func (s Severity) String() string {
+	switch s {
+	case OK:
+		return "ok"
+	case Warn:
+		return "aviso"
+	case Fail:
+		return "falha"
+	default:
+		return "desconhecido"
+	}
+}
+
+// Finding e o resultado de uma verificacao.
+type Finding struct {
+	Check    string // nome da verificacao (ex: "sudo")
+	Severity Severity
+	Message  string // o que foi encontrado
+	Hint     string // o que fazer (vazio se OK)
+}
+
+// NetworkTimeout limita a verificacao de rede.
+const NetworkTimeout = 5 * time.Second
+
+// NetworkURL e o endereco usado para testar a rede (extensoes GNOME).
+const NetworkURL = "https://extensions.gnome.org"
+
+// Repo descreve o diretorio do repositorio escolhido na inicializacao.
+type Repo struct {
+	Dir    string
+	Source string // de onde veio: BLUEPRINT_DIR, executavel, ~/blueprint ou diretorio atual
+}
+
+// Repo sources.
+const (
+	RepoFromEnv  = "BLUEPRINT_DIR"
+	RepoFromExe  = "executavel"
+	RepoFromHome = "~/blueprint"
+	RepoFromCwd  = "diretorio atual"
+)
+
+// Options configura o diagnostico.
+type Options struct {
+	Repo     Repo
+	StateDir string  // diretorio de historico e backups
+	Ignored  []error // plugins e modulos declarativos que falharam ao carregar
+}
+
+// Run executa todas as verificacoes, na ordem em que sao exibidas.
+func Run(ctx context.Context, sys module.System, opts Options) []Finding {
+	var findings []Finding
+	findings = append(findings, checkRepo(sys, opts.Repo))
+	findings = append(findings, checkSession(sys))
+	findings = append(findings, checkSudo(ctx, sys))
+	findings = append(findings, checkGnome(ctx, sys)...)
+	findings = append(findings, checkContainers(sys)...)
+	findings = append(findings, checkUpdateTools(sys)...)
+	findings = append(findings, checkNetwork(ctx, sys))
+	findings = append(findings, checkDirs(sys, opts.StateDir)...)
+	findings = append(findings, checkIgnored(opts.Ignored)...)
+	return findings
+}
+
+// Worst retorna a maior severidade encontrada.
+func Worst(findings []Finding) Severity {
+	worst := OK
+	for _, f := range findings {
+		if f.Severity > worst {
+			worst = f.Severity
+		}
+	}
+	return worst
+}
+
+func checkRepo(sys module.System, repo Repo) Finding {
+	f := Finding{Check: "repositorio"}
+	switch {
+	case repo.Dir == "":
+		f.Severity = Fail
+		f.Message = "diretorio do repo nao encontrado"
+		f.Hint = "defina BLUEPRINT_DIR com o caminho do clone do blueprint"
+	case !sys.FileExists(filepath.Join(repo.Dir, "configs")):
+		f.Severity = Fail
+		f.Message = fmt.Sprintf("%s (via %s) nao tem configs/", repo.Dir, repo.Source)
+		f.Hint = "starship, gnome-focus-mode e devbox leem arquivos de configs/; defina BLUEPRINT_DIR com o caminho do clone"
+	case repo.Source == RepoFromCwd:
+		f.Severity = Warn
+		f.Message = fmt.Sprintf("%s (via diretorio atual)", repo.Dir)
+		f.Hint = "rodar de outro diretorio quebra os modulos; defina BLUEPRINT_DIR ou instale o binario em <repo>/bin"
+	default:
+		f.Message = fmt.Sprintf("%s (via %s)", repo.Dir, repo.Source)
+	}
+	return f
+}
+
+func checkSession(sys module.System) Finding {
+	f := Finding{Check: "sessao"}
+	session := sys.Env("XDG_SESSION_TYPE")
+	hasDisplay := sys.Env("WAYLAND_DISPLAY") != "" || sys.Env("DISPLAY") != ""
+	switch {
+	case sys.IsContainer():
+		f.Message = "container (modulos de desktop e sistema serao pulados)"
+	case sys.IsWSL():
+		f.Message = "WSL (modulos de desktop serao pulados)"
+	case !hasDisplay:
+		f.Severity = Warn
+		f.Message = "sem sessao grafica"
+		if session != "" {
+			f.Message += " (" + session + ")"
+		}
+		f.Hint = "modulos GNOME serao pulados; rode o blueprint em um terminal dentro do desktop"
+	default:
+		if session == "" {
+			session = "grafica"
+		}
+		f.Message = session
+		if desktop := sys.Env("XDG_CURRENT_DESKTOP"); desktop != "" {
+			f.Message += " (" + desktop + ")"
+		}
+	}
+	return f
+}
+
+func checkSudo(ctx context.Context, sys module.System) Finding {
+	f := Finding{Check: "sudo"}
+	switch {
+	case sys.IsContainer():
+		f.Message = "nao necessario em container"
+	case !sys.CommandExists("sudo"):
+		f.Severity = Fail
+		f.Message = "sudo nao encontrado"
+		f.Hint = "passwordless, usb-audio e bluefin-update precisam de sudo; instale-o ou rode como um usuario do grupo wheel/sudo"
+	default:
+		if _, err := sys.Exec(ctx, "sudo", "-n", "true"); err != nil {
+			f.Severity = Warn
+			f.Message = "sudo pede senha"
+			f.Hint = "o apply pede a senha uma vez no inicio; em modo headless sem terminal, modulos de sistema vao falhar"
+		} else {
+			f.Message = "disponivel sem senha"
+		}
+	}
+	return f
+}
+
+func checkGnome(ctx context.Context, sys module.System) []Finding {
+	if sys.IsContainer() || sys.IsWSL() {
+		return nil
+	}
+	hint := "cedilla-fix, tiling-shell, clipboard-indicator e gnome-focus-mode dependem do GNOME Shell"
+	var findings []Finding
+
+	shell := Finding{Check: "gnome-shell"}
+	if out, err := sys.Exec(ctx, "gnome-shell", "--version"); err != nil {
+		shell.Severity = Warn
+		shell.Message = "gnome-shell nao encontrado"
+		shell.Hint = hint + "; sem ele a versao para baixar extensoes nao e detectada"
+	} else {
+		shell.Message = strings.TrimSpace(out)
+	}
+	findings = append(findings, shell)
+
+	for _, cmd := range []string{"gnome-extensions", "dconf"} {
+		findings = append(findings, commandFinding(sys, cmd, Warn, hint))
+	}
+	return findings
+}
+
+func checkContainers(sys module.System) []Finding {
+	if sys.IsContainer() {
+		return nil
+	}
+	hint := "devbox precisa de distrobox e podman (Bluefin ja inclui; no Ubuntu/WSL: sudo apt install podman distrobox)"
+	distrobox := commandFinding(sys, "distrobox", Warn, hint)
+	podman := commandFinding(sys, "podman", Warn, hint)
+	if sys.IsWSL() && podman.Severity != OK {
+		podman.Hint = "no WSL o devbox instala o podman via apt na primeira execucao (requer sudo)"
+	}
+	return []Finding{distrobox, podman}
+}
+
+func checkUpdateTools(sys module.System) []Finding {
+	if sys.IsContainer() || sys.IsWSL() {
+		return nil
+	}
+	return []Finding{
+		commandFinding(sys, "rpm-ostree", Warn, "bluefin-update pula a atualizacao do sistema sem rpm-ostree (esperado fora do Bluefin/Fedora Atomic)"),
+		commandFinding(sys, "flatpak", Warn, "bluefin-update pula a atualizacao de Flatpaks"),
+	}
+}
+
+func checkNetwork(ctx context.Context, sys module.System) Finding {
+	f := Finding{Check: "rede"}
+	if !sys.CommandExists("curl") {
+		f.Severity = Fail
+		f.Message = "curl nao encontrado"
+		f.Hint = "extensoes GNOME e o starship sao baixados com curl; instale-o"
+		return f
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, NetworkTimeout)
+	defer cancel()
+	timeout := strconv.Itoa(int(NetworkTimeout.Seconds()))
+	if _, err := sys.Exec(ctx, "curl", "-sfI", "--max-time", timeout, "-o", "/dev/null", NetworkURL); err != nil {
+		f.Severity = Fail
+		f.Message = fmt.Sprintf("%s inacessivel: %v", NetworkURL, err)
+		f.Hint = "verifique a conexao, DNS e proxy (HTTPS_PROXY); extensoes GNOME e o starship nao serao instalados"
+		return f
+	}
+	f.Message = NetworkURL + " acessivel"
+	return f
+}
+
+// checkDirs verifica se os diretorios em que o blueprint escreve aceitam
+// escrita, criando e apagando um arquivo de teste.
+func checkDirs(sys module.System, stateDir string) []Finding {
+	home := sys.HomeDir()
+	dirs := []struct{ name, path string }{
+		{"home", home},
+		{"cache", filepath.Join(home, ".cache")},
+		{"config", filepath.Join(home, ".config")},
+	}
+	if stateDir != "" {
+		dirs = append(dirs, struct{ name, path string }{"estado", stateDir})
+	}
+
+	var findings []Finding
+	for _, d := range dirs {
+		f := Finding{Check: "dir " + d.name}
+		if err := probeWritable(sys, d.path); err != nil {
+			f.Severity = Fail
+			f.Message = fmt.Sprintf("%s sem permissao de escrita: %v", d.path, err)
+			f.Hint = fmt.Sprintf("corrija o dono/permissoes (ex: sudo chown -R $USER %s)", d.path)
+		} else {
+			f.Message = d.path + " gravavel"
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func probeWritable(sys module.System, dir string) error {
+	if dir == "" {
+		return fmt.Errorf("caminho vazio")
+	}
+	if err := sys.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	probe := filepath.Join(dir, ".blueprint-doctor")
+	if err := sys.WriteFile(probe, []byte("ok\n"), 0o600); err != nil {
+		return err
+	}
+	return sys.Remove(probe)
+}
+
+// checkIgnored lista os modulos externos (plugins e arquivos TOML) que foram
+// pulados na inicializacao por nao carregarem.
+func checkIgnored(errs []error) []Finding {
+	var findings []Finding
+	for _, err := range errs {
+		findings = append(findings, Finding{
+			Check:    "modulo externo",
+			Severity: Warn,
+			Message:  err.Error(),
+			Hint:     "o modulo fica fora dos perfis; corrija ou remova o arquivo",
+		})
+	}
+	return findings
+}
+
+// commandFinding verifica se um comando existe; ausente gera severity + hint.
+func commandFinding(sys module.System, name string, severity Severity, hint string) Finding {
+	f := Finding{Check: name}
+	if sys.CommandExists(name) {
+		f.Message = "disponivel"
+		return f
+	}
+	f.Severity = severity
+	f.Message = name + " nao encontrado"
+	f.Hint = hint
+	return f
+}
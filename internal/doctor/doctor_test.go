@@ -0,0 +1,122 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ale/blueprint/internal/system"
+)
+
+const curlCmd = "curl -sfI --max-time 5 -o /dev/null " + NetworkURL
+
+// desktop simula um Bluefin com tudo instalado.
+func desktop() *system.Mock {
+	mock := system.NewMock()
+	mock.EnvVars["XDG_SESSION_TYPE"] = "wayland"
+	mock.EnvVars["WAYLAND_DISPLAY"] = "wayland-0"
+	for _, cmd := range []string{"sudo", "gnome-extensions", "dconf", "distrobox", "podman", "rpm-ostree", "flatpak", "curl"} {
+		mock.Commands[cmd] = true
+	}
+	mock.ExecResults["gnome-shell --version"] = system.ExecResult{Output: "GNOME Shell 46.2\n"}
+	mock.Files["/repo/configs"] = nil
+	return mock
+}
+
+func run(mock *system.Mock, repo Repo) map[string]Finding {
+	findings := Run(context.Background(), mock, Options{Repo: repo, StateDir: "/home/test/.local/state/blueprint"})
+	byCheck := make(map[string]Finding)
+	for _, f := range findings {
+		byCheck[f.Check] = f
+	}
+	return byCheck
+}
+
+func TestRun_AllOK(t *testing.T) {
+	mock := desktop()
+	findings := Run(context.Background(), mock, Options{Repo: Repo{Dir: "/repo", Source: RepoFromExe}})
+
+	if w := Worst(findings); w != OK {
+		for _, f := range findings {
+			if f.Severity != OK {
+				t.Errorf("%s: %s (%s)", f.Check, f.Severity, f.Message)
+			}
+		}
+		t.Fatalf("Worst() = %s", w)
+	}
+	if len(findings) != 14 {
+		t.Errorf("esperava 14 verificacoes, obteve %d", len(findings))
+	}
+	for path := range mock.Files {
+		if strings.HasSuffix(path, ".blueprint-doctor") {
+			t.Errorf("arquivo de teste nao removido: %s", path)
+		}
+	}
+}
+
+func TestRun_Problems(t *testing.T) {
+	mock := desktop()
+	delete(mock.Commands, "gnome-extensions")
+	mock.ExecResults["sudo -n true"] = system.ExecResult{Err: fmt.Errorf("exit 1")}
+	mock.ExecResults[curlCmd] = system.ExecResult{Err: fmt.Errorf("exit status 6")}
+	delete(mock.EnvVars, "WAYLAND_DISPLAY")
+
+	got := run(mock, Repo{Dir: "/tmp", Source: RepoFromCwd})
+
+	tests := []struct {
+		check    string
+		severity Severity
+		hint     string
+	}{
+		{"repositorio", Fail, "BLUEPRINT_DIR"},
+		{"sessao", Warn, "modulos GNOME serao pulados"},
+		{"sudo", Warn, "pede a senha"},
+		{"gnome-extensions", Warn, "cedilla-fix"},
+		{"rede", Fail, "proxy"},
+	}
+	for _, tt := range tests {
+		f := got[tt.check]
+		if f.Severity != tt.severity || !strings.Contains(f.Hint, tt.hint) {
+			t.Errorf("%s = %+v, esperava %s com dica contendo %q", tt.check, f, tt.severity, tt.hint)
+		}
+	}
+}
+
+func TestRun_Container(t *testing.T) {
+	mock := system.NewMock()
+	mock.Container = true
+	mock.Commands["curl"] = true
+
+	got := run(mock, Repo{Dir: "/repo", Source: RepoFromEnv})
+	for _, check := range []string{"gnome-shell", "distrobox", "rpm-ostree"} {
+		if _, ok := got[check]; ok {
+			t.Errorf("%s nao deveria ser verificado em container", check)
+		}
+	}
+	if f := got["sudo"]; f.Severity != OK {
+		t.Errorf("sudo = %+v", f)
+	}
+}
+
+func TestRun_DirNotWritable(t *testing.T) {
+	mock := desktop()
+	mock.WriteFileErr = fmt.Errorf("permission denied")
+
+	got := run(mock, Repo{Dir: "/repo", Source: RepoFromHome})
+	if f := got["dir cache"]; f.Severity != Fail || !strings.Contains(f.Hint, "chown") {
+		t.Errorf("dir cache = %+v", f)
+	}
+}
+
+func TestRun_Ignored(t *testing.T) {
+	findings := Run(context.Background(), desktop(), Options{
+		Repo:    Repo{Dir: "/repo", Source: RepoFromExe},
+		Ignored: []error{fmt.Errorf("plugin /bin/blueprint-module-x: describe: tempo esgotado")},
+	})
+
+	last := findings[len(findings)-1]
+	if last.Check != "modulo externo" || last.Severity != Warn || !strings.Contains(last.Message, "blueprint-module-x") {
+		t.Errorf("ultimo achado = %+v", last)
+	}
+}
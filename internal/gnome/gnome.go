@@ -7,10 +7,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ale/blueprint/internal/module"
 )
 
+// ExtensionTimeouts sao os limites de tempo dos modulos de extensao: o
+// Check so consulta o gnome-extensions, o Apply baixa a extensao com curl
+// (que pode travar sem rede).
+var ExtensionTimeouts = module.Timeouts{Check: 30 * time.Second, Apply: 5 * time.Minute}
+
 // DconfEntry representa uma chave/valor no dconf.
 type DconfEntry struct {
 	Path  string
@@ -79,6 +85,9 @@ func DetectVersion(ctx context.Context, sys module.System) (string, error) {
 }
 
 // InstallFromGnomeExtensions baixa e instala uma extensao do extensions.gnome.org.
+// Erros que nao se resolvem tentando de novo (resposta invalida, extensao sem
+// versao compativel) sao marcados com module.Permanent, para uso com
+// module.Retry (ver InstallWithRetry).
 func InstallFromGnomeExtensions(ctx context.Context, sys module.System, uuid, gnomeVer, displayName string) error {
 	apiURL := fmt.Sprintf(
 		"https://extensions.gnome.org/extension-info/?uuid=%s&shell_version=%s",
@@ -92,10 +101,10 @@ func InstallFromGnomeExtensions(ctx context.Context, sys module.System, uuid, gn
 
 	var info extensionInfo
 	if err := json.Unmarshal([]byte(jsonOut), &info); err != nil {
-		return fmt.Errorf("resposta inesperada da API: %w", err)
+		return module.Permanent(fmt.Errorf("resposta inesperada da API: %w", err))
 	}
 	if info.DownloadURL == "" {
-		return fmt.Errorf("%s nao disponivel para GNOME Shell %s — verifique se ha uma versao compativel em https://extensions.gnome.org", displayName, gnomeVer)
+		return module.Permanent(fmt.Errorf("%s nao disponivel para GNOME Shell %s — verifique se ha uma versao compativel em https://extensions.gnome.org", displayName, gnomeVer))
 	}
 
 	downloadURL := "https://extensions.gnome.org" + info.DownloadURL
@@ -112,6 +121,14 @@ func InstallFromGnomeExtensions(ctx context.Context, sys module.System, uuid, gn
 	return nil
 }
 
+// InstallWithRetry roda InstallFromGnomeExtensions com novas tentativas
+// (rede instavel), avisando cada falha no reporter.
+func InstallWithRetry(ctx context.Context, sys module.System, retry module.RetryPolicy, reporter module.Reporter, uuid, gnomeVer, displayName string) error {
+	return module.Retry(ctx, retry, reporter, "download de "+displayName, func(ctx context.Context) error {
+		return InstallFromGnomeExtensions(ctx, sys, uuid, gnomeVer, displayName)
+	})
+}
+
 // ApplyDconf escreve uma lista de chaves dconf.
 func ApplyDconf(ctx context.Context, sys module.System, entries []DconfEntry) error {
 	for _, e := range entries {
@@ -121,3 +138,57 @@ func ApplyDconf(ctx context.Context, sys module.System, entries []DconfEntry) er
 	}
 	return nil
 }
+
+// UninstallExtension desativa e remove uma extensao GNOME Shell pelo UUID.
+// Se a extensao nao estiver instalada, nao faz nada.
+func UninstallExtension(ctx context.Context, sys module.System, uuid string) error {
+	if _, err := sys.Exec(ctx, "gnome-extensions", "show", uuid); err != nil {
+		return nil
+	}
+
+	// Desativar primeiro evita que o GNOME Shell mantenha a extensao carregada
+	_, _ = sys.Exec(ctx, "gnome-extensions", "disable", uuid)
+
+	if _, err := sys.Exec(ctx, "gnome-extensions", "uninstall", uuid); err != nil {
+		return fmt.Errorf("erro ao desinstalar extensao %s: %w", uuid, err)
+	}
+	return nil
+}
+
+// ResetDconf restaura uma lista de chaves dconf para o valor padrao.
+func ResetDconf(ctx context.Context, sys module.System, entries []DconfEntry) error {
+	for _, e := range entries {
+		if _, err := sys.Exec(ctx, "dconf", "reset", e.Path); err != nil {
+			return fmt.Errorf("dconf reset %s: %w", e.Path, err)
+		}
+	}
+	return nil
+}
+
+// PlanExtension descreve o que falta para a extensao ficar instalada e ativa.
+func PlanExtension(ctx context.Context, sys module.System, uuid string) []module.Action {
+	out, err := sys.Exec(ctx, "gnome-extensions", "show", uuid)
+	if err != nil || !strings.Contains(out, uuid) {
+		return []module.Action{
+			{Kind: module.ActionInstallExtension, Target: uuid},
+			{Kind: module.ActionEnableExtension, Target: uuid},
+		}
+	}
+	if !strings.Contains(out, "Enabled: Yes") {
+		return []module.Action{{Kind: module.ActionEnableExtension, Target: uuid}}
+	}
+	return nil
+}
+
+// PlanDconf descreve as chaves cujo valor atual (dconf read) difere do desejado.
+func PlanDconf(ctx context.Context, sys module.System, entries []DconfEntry) []module.Action {
+	var actions []module.Action
+	for _, e := range entries {
+		out, err := sys.Exec(ctx, "dconf", "read", e.Path)
+		if err == nil && strings.TrimSpace(out) == e.Value {
+			continue
+		}
+		actions = append(actions, module.Action{Kind: module.ActionDconfWrite, Target: e.Path, Value: e.Value})
+	}
+	return actions
+}
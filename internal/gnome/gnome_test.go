@@ -3,9 +3,11 @@ package gnome
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/module/moduletest"
 	"github.com/ale/blueprint/internal/system"
 )
 
@@ -268,3 +270,116 @@ func TestApplyDconf_Failure(t *testing.T) {
 		t.Error("esperava erro quando dconf falha")
 	}
 }
+
+func TestUninstallExtension_NotInstalled(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["gnome-extensions show test@ext"] = system.ExecResult{Err: fmt.Errorf("not found")}
+
+	if err := UninstallExtension(context.Background(), mock, "test@ext"); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(mock.ExecLog) != 1 {
+		t.Errorf("nao deveria executar nada alem do show: %v", mock.ExecLog)
+	}
+}
+
+func TestUninstallExtension_Success(t *testing.T) {
+	mock := system.NewMock()
+
+	if err := UninstallExtension(context.Background(), mock, "test@ext"); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	expected := []string{
+		"gnome-extensions show test@ext",
+		"gnome-extensions disable test@ext",
+		"gnome-extensions uninstall test@ext",
+	}
+	if len(mock.ExecLog) != len(expected) {
+		t.Fatalf("esperava %v, obteve %v", expected, mock.ExecLog)
+	}
+	for i, cmd := range expected {
+		if mock.ExecLog[i] != cmd {
+			t.Errorf("comando %d: esperava %q, obteve %q", i, cmd, mock.ExecLog[i])
+		}
+	}
+}
+
+func TestUninstallExtension_Fails(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["gnome-extensions uninstall test@ext"] = system.ExecResult{Err: fmt.Errorf("erro")}
+
+	if err := UninstallExtension(context.Background(), mock, "test@ext"); err == nil {
+		t.Error("esperava erro quando uninstall falha")
+	}
+}
+
+func TestResetDconf(t *testing.T) {
+	mock := system.NewMock()
+	entries := []DconfEntry{{"/org/gnome/test/key1", "value1"}}
+
+	if err := ResetDconf(context.Background(), mock, entries); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(mock.ExecLog) != 1 || mock.ExecLog[0] != "dconf reset /org/gnome/test/key1" {
+		t.Errorf("exec log inesperado: %v", mock.ExecLog)
+	}
+}
+
+func TestPlanExtension(t *testing.T) {
+	const uuid = "test@example.com"
+	mock := system.NewMock()
+	mock.ExecResults["gnome-extensions show "+uuid] = system.ExecResult{Err: fmt.Errorf("not found")}
+	if got := PlanExtension(context.Background(), mock, uuid); len(got) != 2 || got[0].Kind != module.ActionInstallExtension {
+		t.Errorf("ausente: PlanExtension() = %v", got)
+	}
+
+	mock.ExecResults["gnome-extensions show "+uuid] = system.ExecResult{Output: uuid + "\n  Enabled: No\n"}
+	if got := PlanExtension(context.Background(), mock, uuid); len(got) != 1 || got[0].Kind != module.ActionEnableExtension {
+		t.Errorf("desativada: PlanExtension() = %v", got)
+	}
+
+	mock.ExecResults["gnome-extensions show "+uuid] = system.ExecResult{Output: uuid + "\n  Enabled: Yes\n"}
+	if got := PlanExtension(context.Background(), mock, uuid); len(got) != 0 {
+		t.Errorf("ativa: PlanExtension() = %v", got)
+	}
+}
+
+func TestPlanDconf(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["dconf read /a"] = system.ExecResult{Output: "uint32 4\n"}
+	mock.ExecResults["dconf read /b"] = system.ExecResult{Output: "uint32 4\n"}
+
+	got := PlanDconf(context.Background(), mock, []DconfEntry{{Path: "/a", Value: "uint32 4"}, {Path: "/b", Value: "uint32 8"}})
+	if len(got) != 1 || got[0].Target != "/b" || got[0].Value != "uint32 8" {
+		t.Errorf("PlanDconf() = %v", got)
+	}
+}
+
+func TestInstallWithRetry_RetriesNetworkErrors(t *testing.T) {
+	mock := system.NewMock()
+	infoCmd := "curl -sfL https://extensions.gnome.org/extension-info/?uuid=test@ext&shell_version=46"
+	mock.ExecResults[infoCmd] = system.ExecResult{Err: fmt.Errorf("connection refused")}
+
+	err := InstallWithRetry(context.Background(), mock, module.RetryPolicy{Attempts: 3}, moduletest.NoopReporter(), "test@ext", "46", "Test")
+	if err == nil {
+		t.Fatal("esperava erro quando curl falha sempre")
+	}
+	if n := strings.Count(strings.Join(mock.ExecLog, "\n"), infoCmd); n != 3 {
+		t.Errorf("esperava 3 consultas a API, obteve %d", n)
+	}
+}
+
+func TestInstallWithRetry_NoRetryWhenIncompatible(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["curl -sfL https://extensions.gnome.org/extension-info/?uuid=test@ext&shell_version=46"] = system.ExecResult{
+		Output: `{"download_url": ""}`,
+	}
+
+	err := InstallWithRetry(context.Background(), mock, module.RetryPolicy{Attempts: 3}, moduletest.NoopReporter(), "test@ext", "46", "Test")
+	if err == nil || !module.IsPermanent(err) {
+		t.Fatalf("esperava erro permanente, obteve %v", err)
+	}
+	if len(mock.ExecLog) != 1 {
+		t.Errorf("versao incompativel nao deveria ser repetida: %v", mock.ExecLog)
+	}
+}
@@ -0,0 +1,272 @@
+// Package history registra cada execucao (apply/remove) em um journal
+// JSON Lines em $XDG_STATE_HOME/blueprint, para consulta posterior via
+// "blueprint history".
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/version"
+)
+
+// journalFile e o nome do journal dentro do diretorio de estado.
+const journalFile = "history.jsonl"
+
+// Acoes registradas no journal.
+const (
+	ActionApply  = "apply"
+	ActionRemove = "remove"
+)
+
+// Status e a forma serializada de module.Status.
+type Status struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message,omitempty"`
+}
+
+// Entry e o resultado de um modulo dentro de uma execucao.
+type Entry struct {
+	Module    string        `json:"module"`
+	Before    *Status       `json:"before,omitempty"` // estado encontrado pelo Check
+	After     *Status       `json:"after,omitempty"`  // estado apos Apply/Revert
+	Applied   bool          `json:"applied,omitempty"`
+	Reverted  bool          `json:"reverted,omitempty"`
+	Skipped   bool          `json:"skipped,omitempty"`
+	Deferred  bool          `json:"deferred,omitempty"`  // adiado ate o proximo boot (dependencia pediu reboot)
+	Cancelled bool          `json:"cancelled,omitempty"` // interrompido ou nao iniciado por Ctrl+C/SIGTERM (ver Reason)
+	TimedOut  bool          `json:"timed_out,omitempty"` // Error e um limite de tempo estourado
+	Reason    string        `json:"reason,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Notes     []string      `json:"notes,omitempty"`
+	Retries   []Retry       `json:"retries,omitempty"` // tentativas que falharam e foram repetidas
+	Session   []Session     `json:"session,omitempty"` // acoes de sessao pedidas (reboot, logout, novo terminal)
+	Duration  time.Duration `json:"duration"`
+}
+
+// Retry e uma tentativa que falhou e foi repetida (module.Attempt).
+type Retry struct {
+	Op      string `json:"op"`
+	Attempt int    `json:"attempt"`
+	Error   string `json:"error"`
+}
+
+// Session e uma acao de sessao pedida pelo modulo (module.SessionRequest).
+type Session struct {
+	Action string `json:"action"` // restart_shell, relogin ou reboot (ver module.SessionAction.Key)
+	Reason string `json:"reason,omitempty"`
+}
+
+// Outcome descreve o resultado do modulo em poucas palavras (ex: "aplicado",
+// "pulado: em container").
+func (e Entry) Outcome() string {
+	switch {
+	case e.TimedOut:
+		return e.Error
+	case e.Error != "":
+		return "erro: " + e.Error
+	case e.Cancelled:
+		return "cancelado: " + e.Reason
+	case e.Deferred:
+		return "adiado: " + e.Reason
+	case e.Skipped:
+		return "pulado: " + e.Reason
+	case e.Applied:
+		return "aplicado"
+	case e.Reverted:
+		return "removido"
+	default:
+		return "sem alteracoes"
+	}
+}
+
+// Run e uma execucao completa de apply ou remove.
+type Run struct {
+	ID         string    `json:"id"`
+	Action     string    `json:"action"`
+	Profile    string    `json:"profile,omitempty"`
+	Version    string    `json:"version"`
+	Commit     string    `json:"commit"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Backups    int       `json:"backups,omitempty"` // arquivos salvos para blueprint rollback
+	Entries    []Entry   `json:"modules"`
+}
+
+// idFormat e o formato do ID de uma execucao: horario de inicio com
+// milissegundos, para que execucoes no mesmo segundo (ex: remove logo apos
+// o apply) nao compartilhem ID nem diretorio de backup.
+const idFormat = "20060102-150405.000"
+
+// NewRun inicia o registro de uma execucao. O ID e derivado do horario de inicio.
+func NewRun(action, profile string) *Run {
+	now := time.Now()
+	return &Run{
+		ID:        now.Format(idFormat),
+		Action:    action,
+		Profile:   profile,
+		Version:   version.Version,
+		Commit:    version.Commit,
+		StartedAt: now,
+	}
+}
+
+// Add registra o resultado de um modulo.
+func (r *Run) Add(res orchestrator.Result) {
+	e := Entry{
+		Module:    res.Module.Name(),
+		After:     toStatus(res.After),
+		Applied:   res.Applied,
+		Reverted:  res.Reverted,
+		Skipped:   res.Skipped,
+		Deferred:  res.Deferred,
+		Cancelled: res.Cancelled,
+		TimedOut:  res.TimedOut,
+		Reason:    res.Reason,
+		Notes:     res.Notes,
+		Duration:  res.Duration,
+	}
+	// Modulos pulados ou nao iniciados nao tem estado verificado
+	if !res.Skipped && !(res.Cancelled && res.Reason == orchestrator.ReasonNotStarted) {
+		e.Before = toStatus(&res.Status)
+	}
+	if res.Err != nil {
+		e.Error = res.Err.Error()
+	}
+	for _, a := range res.Retries {
+		e.Retries = append(e.Retries, Retry{Op: a.Op, Attempt: a.Number, Error: a.Err.Error()})
+	}
+	for _, req := range res.Session {
+		e.Session = append(e.Session, Session{Action: req.Action.Key(), Reason: req.Reason})
+	}
+	r.Entries = append(r.Entries, e)
+}
+
+// Observer retorna um orchestrator.Observer que registra cada modulo finalizado.
+func (r *Run) Observer() orchestrator.Observer {
+	return func(ev orchestrator.Event) {
+		if ev.Kind == orchestrator.ModuleFinished {
+			r.Add(ev.Result)
+		}
+	}
+}
+
+// Finish marca o fim da execucao.
+func (r *Run) Finish() {
+	r.FinishedAt = time.Now()
+}
+
+// Failed retorna quantos modulos terminaram com erro.
+func (r Run) Failed() int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func toStatus(s *module.Status) *Status {
+	if s == nil {
+		return nil
+	}
+	return &Status{Kind: s.Kind.String(), Message: s.Message}
+}
+
+// DefaultDir retorna $XDG_STATE_HOME/blueprint (padrao: ~/.local/state/blueprint).
+func DefaultDir(sys module.System) string {
+	if dir := sys.Env("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "blueprint")
+	}
+	return filepath.Join(sys.HomeDir(), ".local", "state", "blueprint")
+}
+
+// Store le e grava o journal de execucoes.
+type Store struct {
+	sys  module.System
+	path string
+}
+
+// NewStore cria um Store com o journal em dir.
+func NewStore(sys module.System, dir string) *Store {
+	return &Store{sys: sys, path: filepath.Join(dir, journalFile)}
+}
+
+// Path retorna o caminho do journal.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append grava uma execucao no fim do journal.
+func (s *Store) Append(run Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("serializar execucao %s: %w", run.ID, err)
+	}
+	if err := s.sys.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("criar diretorio de historico: %w", err)
+	}
+	if err := s.sys.AppendFile(s.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("gravar historico: %w", err)
+	}
+	return nil
+}
+
+// List retorna todas as execucoes, da mais antiga para a mais recente.
+// Journal inexistente resulta em lista vazia.
+func (s *Store) List() ([]Run, error) {
+	if !s.sys.FileExists(s.path) {
+		return nil, nil
+	}
+	data, err := s.sys.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("ler historico: %w", err)
+	}
+
+	var runs []Run
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("%s:%d: registro invalido: %w", s.path, line, err)
+		}
+		runs = append(runs, run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ler historico: %w", err)
+	}
+	return runs, nil
+}
+
+// Get retorna a execucao com o ID informado. "last" retorna a mais recente.
+func (s *Store) Get(id string) (Run, error) {
+	runs, err := s.List()
+	if err != nil {
+		return Run{}, err
+	}
+	if len(runs) == 0 {
+		return Run{}, fmt.Errorf("nenhuma execucao registrada")
+	}
+	if id == "last" {
+		return runs[len(runs)-1], nil
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].ID == id {
+			return runs[i], nil
+		}
+	}
+	return Run{}, fmt.Errorf("execucao desconhecida: %q (veja blueprint history)", id)
+}
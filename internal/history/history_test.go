@@ -0,0 +1,216 @@
+package history
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/system"
+)
+
+type stubModule struct{ name string }
+
+func (s stubModule) Name() string        { return s.name }
+func (s stubModule) Description() string { return "modulo de teste" }
+func (s stubModule) Tags() []string      { return nil }
+
+func TestDefaultDir(t *testing.T) {
+	mock := system.NewMock()
+	if got := DefaultDir(mock); got != "/home/test/.local/state/blueprint" {
+		t.Errorf("DefaultDir() = %q", got)
+	}
+
+	mock.EnvVars["XDG_STATE_HOME"] = "/var/state"
+	if got := DefaultDir(mock); got != "/var/state/blueprint" {
+		t.Errorf("DefaultDir() com XDG_STATE_HOME = %q", got)
+	}
+}
+
+func TestNewRun_ID(t *testing.T) {
+	run := NewRun(ActionApply, "full")
+	if got := run.StartedAt.Format(idFormat); run.ID != got || !strings.Contains(run.ID, ".") {
+		t.Errorf("ID = %q; esperava o inicio com milissegundos (%q)", run.ID, got)
+	}
+}
+
+func TestRun_Add(t *testing.T) {
+	run := NewRun(ActionApply, "full")
+	after := module.Status{Kind: module.Installed}
+
+	run.Add(orchestrator.Result{
+		Module:   stubModule{"a"},
+		Status:   module.Status{Kind: module.Missing, Message: "nao configurado"},
+		After:    &after,
+		Applied:  true,
+		Notes:    []string{"reinicie"},
+		Duration: 2 * time.Second,
+	})
+	run.Add(orchestrator.Result{Module: stubModule{"b"}, Err: errors.New("falhou")})
+	run.Add(orchestrator.Result{Module: stubModule{"c"}, Skipped: true, Reason: "sem sessao grafica"})
+
+	a := run.Entries[0]
+	if a.Before == nil || a.Before.Kind != "ausente" || a.Before.Message != "nao configurado" {
+		t.Errorf("before = %+v", a.Before)
+	}
+	if a.After == nil || a.After.Kind != "instalado" {
+		t.Errorf("after = %+v", a.After)
+	}
+	if !a.Applied || a.Duration != 2*time.Second || len(a.Notes) != 1 {
+		t.Errorf("entrada a = %+v", a)
+	}
+
+	if run.Entries[1].Error != "falhou" {
+		t.Errorf("erro = %q", run.Entries[1].Error)
+	}
+	if run.Entries[2].Before != nil {
+		t.Error("modulo pulado nao deveria ter estado anterior")
+	}
+	if run.Failed() != 1 {
+		t.Errorf("Failed() = %d, esperava 1", run.Failed())
+	}
+	if run.Version == "" || run.Commit == "" {
+		t.Error("esperava versao e commit preenchidos")
+	}
+}
+
+func TestRun_AddCancelled(t *testing.T) {
+	run := NewRun(ActionApply, "full")
+	run.Add(orchestrator.Result{
+		Module:    stubModule{"a"},
+		Status:    module.Status{Kind: module.Missing},
+		Cancelled: true,
+		Reason:    orchestrator.ReasonInterrupted,
+	})
+	run.Add(orchestrator.Result{
+		Module:    stubModule{"b"},
+		Status:    module.Status{Kind: module.Skipped},
+		Cancelled: true,
+		Reason:    orchestrator.ReasonNotStarted,
+	})
+
+	a, b := run.Entries[0], run.Entries[1]
+	if !a.Cancelled || a.Before == nil || a.Before.Kind != "ausente" {
+		t.Errorf("interrompido deveria manter o estado do check: %+v", a)
+	}
+	if !b.Cancelled || b.Before != nil {
+		t.Errorf("nao iniciado nao deveria ter estado anterior: %+v", b)
+	}
+	if run.Failed() != 0 {
+		t.Errorf("Failed() = %d, cancelamento nao e falha", run.Failed())
+	}
+}
+
+func TestRun_AddRetriesAndSession(t *testing.T) {
+	run := NewRun(ActionApply, "full")
+	run.Add(orchestrator.Result{
+		Module:  stubModule{"a"},
+		Applied: true,
+		Retries: []module.Attempt{{Op: "download", Number: 1, Err: errors.New("timeout de conexao")}},
+		Session: []module.SessionRequest{{Action: module.SessionRelogin, Reason: "faca logout"}},
+	})
+
+	if s := run.Entries[0].Session; len(s) != 1 || s[0].Action != "relogin" || s[0].Reason != "faca logout" {
+		t.Errorf("Session = %+v", s)
+	}
+
+	retries := run.Entries[0].Retries
+	if len(retries) != 1 || retries[0].Op != "download" || retries[0].Attempt != 1 || retries[0].Error != "timeout de conexao" {
+		t.Errorf("Retries = %+v", retries)
+	}
+}
+
+func TestEntry_Outcome(t *testing.T) {
+	run := NewRun(ActionApply, "full")
+	run.Add(orchestrator.Result{Module: stubModule{"devbox"}, Skipped: true, Deferred: true, Reason: "aguarda o reboot pedido por devcontainers"})
+	run.Add(orchestrator.Result{Module: stubModule{"b"}, Skipped: true, Reason: "em container"})
+	run.Add(orchestrator.Result{Module: stubModule{"c"}, Applied: true})
+
+	expected := []string{"adiado: aguarda o reboot pedido por devcontainers", "pulado: em container", "aplicado"}
+	for i, want := range expected {
+		if got := run.Entries[i].Outcome(); got != want {
+			t.Errorf("Outcome() de %s = %q, esperava %q", run.Entries[i].Module, got, want)
+		}
+	}
+	if !run.Entries[0].Deferred {
+		t.Error("esperava Deferred registrado")
+	}
+}
+
+func TestRun_Observer(t *testing.T) {
+	run := NewRun(ActionApply, "full")
+	obs := run.Observer()
+
+	obs(orchestrator.Event{Kind: orchestrator.ModuleStarted, Module: stubModule{"a"}})
+	obs(orchestrator.Event{Kind: orchestrator.ModuleFinished, Module: stubModule{"a"}, Result: orchestrator.Result{Module: stubModule{"a"}}})
+
+	if len(run.Entries) != 1 || run.Entries[0].Module != "a" {
+		t.Errorf("entradas = %+v", run.Entries)
+	}
+}
+
+func TestStore_AppendAndList(t *testing.T) {
+	mock := system.NewMock()
+	store := NewStore(mock, "/state")
+
+	runs, err := store.List()
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("List() sem journal = %v, %v", runs, err)
+	}
+
+	first := Run{ID: "20260101-100000", Action: ActionApply}
+	second := Run{ID: "20260102-100000", Action: ActionRemove}
+	for _, r := range []Run{first, second} {
+		if err := store.Append(r); err != nil {
+			t.Fatalf("Append(%s): %v", r.ID, err)
+		}
+	}
+
+	if lines := strings.Count(string(mock.Files["/state/history.jsonl"]), "\n"); lines != 2 {
+		t.Errorf("esperava 2 linhas no journal, obteve %d", lines)
+	}
+
+	runs, err = store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != first.ID || runs[1].Action != ActionRemove {
+		t.Errorf("List() = %+v", runs)
+	}
+}
+
+func TestStore_Get(t *testing.T) {
+	mock := system.NewMock()
+	store := NewStore(mock, "/state")
+
+	if _, err := store.Get("last"); err == nil {
+		t.Error("esperava erro sem execucoes registradas")
+	}
+
+	_ = store.Append(Run{ID: "r1"})
+	_ = store.Append(Run{ID: "r2"})
+
+	last, err := store.Get("last")
+	if err != nil || last.ID != "r2" {
+		t.Errorf("Get(last) = %+v, %v", last, err)
+	}
+	r1, err := store.Get("r1")
+	if err != nil || r1.ID != "r1" {
+		t.Errorf("Get(r1) = %+v, %v", r1, err)
+	}
+	if _, err := store.Get("r9"); err == nil {
+		t.Error("esperava erro para ID desconhecido")
+	}
+}
+
+func TestStore_InvalidLine(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/state/history.jsonl"] = []byte("{nao e json}\n")
+
+	_, err := NewStore(mock, "/state").List()
+	if err == nil || !strings.Contains(err.Error(), ":1:") {
+		t.Errorf("esperava erro com numero da linha, obteve %v", err)
+	}
+}
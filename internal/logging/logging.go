@@ -0,0 +1,209 @@
+// Package logging grava um log estruturado (log/slog) por execucao em
+// $XDG_STATE_HOME/blueprint/logs: cada comando externo (com codigo de saida,
+// duracao e stderr), escrita em arquivo e evento de modulo. Com -v, o mesmo
+// log e espelhado no terminal em nivel debug.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Subdir e o diretorio dos logs dentro do diretorio de estado.
+const Subdir = "logs"
+
+// Keep e quantos arquivos de log sao mantidos por comando (apply, status,
+// ...); os mais antigos sao apagados. Contar por comando evita que os
+// status de um timer empurrem para fora os logs dos applies.
+const Keep = 50
+
+// nameFormat e o horario no nome do arquivo, com milissegundos (como o ID
+// do historico): duas execucoes no mesmo segundo nao dividem o arquivo.
+const nameFormat = "20060102-150405.000"
+
+// Log e o log de uma execucao do blueprint.
+type Log struct {
+	Logger *slog.Logger
+	Path   string // arquivo de log ("" se nao foi possivel cria-lo)
+
+	file     *os.File
+	terminal *terminalHandler
+	start    time.Time
+}
+
+// Open cria o arquivo de log da execucao em <stateDir>/logs. Com verbose,
+// espelha o log em terminal (normalmente o stderr) em nivel debug. Se o
+// arquivo nao puder ser criado, o erro e retornado junto com um Log valido
+// (so terminal, ou descarte), para que o comando siga funcionando.
+func Open(stateDir, command string, verbose bool, terminal io.Writer) (*Log, error) {
+	l := &Log{start: time.Now()}
+	var handlers []slog.Handler
+
+	if verbose && terminal != nil {
+		l.terminal = &terminalHandler{
+			Handler: slog.NewTextHandler(terminal, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			muted:   new(atomic.Bool),
+		}
+		handlers = append(handlers, l.terminal)
+	}
+
+	dir := filepath.Join(stateDir, Subdir)
+	kind := sanitize(command)
+	var openErr error
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		openErr = fmt.Errorf("erro ao criar %s: %w", dir, err)
+	} else if f, err := create(dir, l.start, kind); err != nil {
+		openErr = fmt.Errorf("erro ao criar log: %w", err)
+	} else {
+		l.file = f
+		l.Path = f.Name()
+		handlers = append(handlers, slog.NewTextHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		prune(dir, kind, Keep)
+	}
+
+	switch len(handlers) {
+	case 0:
+		l.Logger = slog.New(slog.DiscardHandler)
+	case 1:
+		l.Logger = slog.New(handlers[0])
+	default:
+		l.Logger = slog.New(fanout(handlers))
+	}
+	return l, openErr
+}
+
+// MuteTerminal para de espelhar o log no terminal (ex: enquanto o TUI
+// ocupa a tela). O arquivo continua recebendo tudo.
+func (l *Log) MuteTerminal() {
+	if l.terminal != nil {
+		l.terminal.muted.Store(true)
+	}
+}
+
+// Close registra o fim da execucao (erro e duracao) e fecha o arquivo.
+func (l *Log) Close(exitCode int, err error) error {
+	attrs := []any{"exit_code", exitCode, "duracao", time.Since(l.start).Round(time.Millisecond)}
+	if err != nil {
+		l.Logger.Error("execucao finalizada", append(attrs, "erro", err.Error())...)
+	} else {
+		l.Logger.Info("execucao finalizada", attrs...)
+	}
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// sanitize deixa o nome do comando seguro para nome de arquivo.
+func sanitize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(s))
+	if s == "" {
+		return "blueprint"
+	}
+	return s
+}
+
+// create cria um arquivo de log novo (<horario>-<comando>.log). Um nome ja
+// existente nunca e reaproveitado: no mesmo milissegundo, ganha um sufixo.
+func create(dir string, start time.Time, kind string) (*os.File, error) {
+	base := start.Format(nameFormat)
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s-%s.log", base, kind)
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d-%s.log", base, i, kind)
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if !errors.Is(err, fs.ErrExist) || i >= 10 {
+			return f, err
+		}
+	}
+}
+
+// prune apaga os logs mais antigos do comando kind, mantendo os keep mais
+// recentes. O nome comeca com o horario, entao a ordem alfabetica e
+// cronologica.
+func prune(dir, kind string, keep int) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*-"+kind+".log"))
+	if err != nil || len(matches) <= keep {
+		return
+	}
+	sort.Strings(matches)
+	for _, path := range matches[:len(matches)-keep] {
+		os.Remove(path)
+	}
+}
+
+// terminalHandler e o handler do terminal, que pode ser silenciado. Os
+// handlers derivados (With) compartilham o mesmo estado.
+type terminalHandler struct {
+	slog.Handler
+	muted *atomic.Bool
+}
+
+func (h *terminalHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return !h.muted.Load() && h.Handler.Enabled(ctx, level)
+}
+
+func (h *terminalHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &terminalHandler{Handler: h.Handler.WithAttrs(attrs), muted: h.muted}
+}
+
+func (h *terminalHandler) WithGroup(name string) slog.Handler {
+	return &terminalHandler{Handler: h.Handler.WithGroup(name), muted: h.muted}
+}
+
+// fanout repassa cada registro a varios handlers.
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+	for _, h := range f {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
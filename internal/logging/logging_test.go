@@ -0,0 +1,165 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpen_WritesFileUnderLogs(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, "apply", false, nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if filepath.Dir(l.Path) != filepath.Join(dir, Subdir) {
+		t.Errorf("Path = %s, esperado dentro de %s", l.Path, filepath.Join(dir, Subdir))
+	}
+	if !strings.HasSuffix(l.Path, "-apply.log") {
+		t.Errorf("Path = %s, esperado sufixo -apply.log", l.Path)
+	}
+
+	l.Logger.Info("comando", "cmd", "dconf write /x 1", "exit_code", 0)
+	l.Logger.Debug("saida", "linha", "detalhe")
+	if err := l.Close(3, errors.New("1 modulo(s) com erro")); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(l.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{`cmd="dconf write /x 1"`, "linha=detalhe", "execucao finalizada", "exit_code=3", `erro="1 modulo(s) com erro"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log sem %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestOpen_VerboseMirrorsToTerminal(t *testing.T) {
+	var term bytes.Buffer
+	l, err := Open(t.TempDir(), "status", true, &term)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	l.Logger.Debug("saida", "linha", "visivel")
+	l.MuteTerminal()
+	l.Logger.With("modulo", "a").Info("oculto")
+	l.Close(0, nil)
+
+	if !strings.Contains(term.String(), "linha=visivel") {
+		t.Errorf("terminal sem a mensagem de debug:\n%s", term.String())
+	}
+	if strings.Contains(term.String(), "oculto") {
+		t.Errorf("terminal silenciado recebeu mensagem:\n%s", term.String())
+	}
+
+	data, _ := os.ReadFile(l.Path)
+	if !strings.Contains(string(data), "oculto") {
+		t.Errorf("arquivo deveria continuar recebendo tudo:\n%s", data)
+	}
+}
+
+func TestOpen_WithoutVerboseTerminalIsQuiet(t *testing.T) {
+	var term bytes.Buffer
+	l, _ := Open(t.TempDir(), "apply", false, &term)
+	l.Logger.Warn("comando falhou")
+	l.Close(1, nil)
+
+	if term.Len() != 0 {
+		t.Errorf("sem -v nada deveria ir ao terminal, veio:\n%s", term.String())
+	}
+}
+
+func TestOpen_UnwritableDirStillReturnsLogger(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "arquivo")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// O diretorio de estado e um arquivo: nao da para criar logs/ dentro
+	l, err := Open(file, "apply", false, nil)
+	if err == nil {
+		t.Fatal("esperado erro ao criar o diretorio de logs")
+	}
+	if l == nil || l.Logger == nil || l.Path != "" {
+		t.Fatalf("esperado Log utilizavel sem arquivo, veio %+v", l)
+	}
+	l.Logger.Info("descartado")
+	if err := l.Close(0, nil); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestPrune_KeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		for _, kind := range []string{"apply", "status"} {
+			name := fmt.Sprintf("20260101-00000%d.000-%s.log", i, kind)
+			if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	prune(dir, "status", 2)
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*-status.log"))
+	if len(matches) != 2 {
+		t.Fatalf("esperado 2 logs de status, restaram %v", matches)
+	}
+	for _, m := range matches {
+		base := filepath.Base(m)
+		if base != "20260101-000003.000-status.log" && base != "20260101-000004.000-status.log" {
+			t.Errorf("log mais antigo mantido: %s", base)
+		}
+	}
+	if applies, _ := filepath.Glob(filepath.Join(dir, "*-apply.log")); len(applies) != 5 {
+		t.Errorf("logs de apply nao deveriam contar para o status: %v", applies)
+	}
+}
+
+func TestCreate_SameMillisecondGetsOwnFile(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.MkdirAll(filepath.Join(dir, Subdir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	first, err := create(filepath.Join(dir, Subdir), start, "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := create(filepath.Join(dir, Subdir), start, "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	if first.Name() == second.Name() {
+		t.Errorf("duas execucoes no mesmo arquivo: %s", first.Name())
+	}
+	if filepath.Base(first.Name()) != "20260101-120000.000-status.log" {
+		t.Errorf("nome = %s, esperava horario com milissegundos", filepath.Base(first.Name()))
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := map[string]string{
+		"apply":     "apply",
+		"Run Now/x": "run-now-x",
+		"":          "blueprint",
+	}
+	for in, want := range tests {
+		if got := sanitize(in); got != want {
+			t.Errorf("sanitize(%q) = %q, esperado %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package module
+
+// Requirements retorna as dependencias declaradas por m, ou nil se m nao
+// implementa Dependent.
+func Requirements(m Module) []string {
+	if d, ok := m.(Dependent); ok {
+		return d.Requires()
+	}
+	return nil
+}
+
+// SortByDependencies ordena os modulos de forma que cada um venha depois
+// das suas dependencias. A ordem original e preservada sempre que possivel
+// (ordenacao topologica estavel). Dependencias fora da lista sao ignoradas;
+// modulos presos em ciclo sao mantidos no final, na ordem original.
+func SortByDependencies(modules []Module) []Module {
+	inList := make(map[string]bool, len(modules))
+	for _, m := range modules {
+		inList[m.Name()] = true
+	}
+
+	placed := make(map[string]bool, len(modules))
+	result := make([]Module, 0, len(modules))
+
+	for len(result) < len(modules) {
+		progress := false
+		for _, m := range modules {
+			if placed[m.Name()] || !depsPlaced(m, inList, placed) {
+				continue
+			}
+			placed[m.Name()] = true
+			result = append(result, m)
+			progress = true
+			// Recomeca do inicio para manter a ordem original estavel
+			break
+		}
+		if !progress {
+			break
+		}
+	}
+
+	// Ciclo: adiciona o que sobrou na ordem original
+	for _, m := range modules {
+		if !placed[m.Name()] {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+// depsPlaced verifica se todas as dependencias de m presentes na lista ja foram posicionadas.
+func depsPlaced(m Module, inList, placed map[string]bool) bool {
+	for _, dep := range Requirements(m) {
+		if inList[dep] && !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
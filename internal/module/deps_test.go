@@ -0,0 +1,80 @@
+package module
+
+import "testing"
+
+// depModule e um stubModule com dependencias declaradas.
+type depModule struct {
+	stubModule
+	requires []string
+}
+
+func (d *depModule) Requires() []string { return d.requires }
+
+func newDep(name string, requires ...string) *depModule {
+	return &depModule{stubModule: stubModule{name: name}, requires: requires}
+}
+
+func names(modules []Module) []string {
+	result := make([]string, len(modules))
+	for i, m := range modules {
+		result[i] = m.Name()
+	}
+	return result
+}
+
+func TestRequirements(t *testing.T) {
+	if got := Requirements(&stubModule{name: "a"}); got != nil {
+		t.Errorf("modulo sem Dependent deveria retornar nil, obteve %v", got)
+	}
+	if got := Requirements(newDep("b", "a")); len(got) != 1 || got[0] != "a" {
+		t.Errorf("esperava [a], obteve %v", got)
+	}
+}
+
+func TestSortByDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		modules  []Module
+		expected []string
+	}{
+		{
+			name:     "sem dependencias mantem ordem",
+			modules:  []Module{newDep("a"), newDep("b"), newDep("c")},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "dependencia registrada depois vem antes",
+			modules:  []Module{newDep("devbox", "devcontainers"), newDep("starship"), newDep("devcontainers")},
+			expected: []string{"starship", "devcontainers", "devbox"},
+		},
+		{
+			name:     "cadeia de dependencias",
+			modules:  []Module{newDep("c", "b"), newDep("b", "a"), newDep("a")},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "dependencia fora da lista e ignorada",
+			modules:  []Module{newDep("devbox", "devcontainers"), newDep("starship")},
+			expected: []string{"devbox", "starship"},
+		},
+		{
+			name:     "ciclo mantem modulos no final",
+			modules:  []Module{newDep("a", "b"), newDep("b", "a"), newDep("c")},
+			expected: []string{"c", "a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(SortByDependencies(tt.modules))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("esperava %v, obteve %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("esperava %v, obteve %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
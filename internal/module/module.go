@@ -18,15 +18,26 @@ type System interface {
 	// ExecStream executa um comando e chama callback para cada linha de saida.
 	ExecStream(ctx context.Context, callback func(line string), name string, args ...string) error
 
+	// ExecInput executa um comando enviando input no stdin e chama callback
+	// para cada linha do stdout. O stderr entra na mensagem de erro.
+	ExecInput(ctx context.Context, input []byte, callback func(line string), name string, args ...string) error
+
 	// FileExists verifica se um arquivo ou diretorio existe.
 	FileExists(path string) bool
 
 	// ReadFile le o conteudo de um arquivo.
 	ReadFile(path string) ([]byte, error)
 
+	// FileMode retorna o modo de um arquivo (tipo e permissoes), seguindo
+	// symlinks.
+	FileMode(path string) (os.FileMode, error)
+
 	// WriteFile escreve conteudo em um arquivo.
 	WriteFile(path string, data []byte, perm os.FileMode) error
 
+	// Chmod altera as permissoes de um arquivo.
+	Chmod(path string, perm os.FileMode) error
+
 	// MkdirAll cria diretorios recursivamente.
 	MkdirAll(path string, perm os.FileMode) error
 
@@ -48,9 +59,32 @@ type System interface {
 	// CommandExists verifica se um comando esta disponivel no PATH.
 	CommandExists(name string) bool
 
+	// AppendFile acrescenta data ao fim do arquivo (O_APPEND), sem ler o
+	// conteudo existente. Cria o arquivo com perm se ele nao existir.
+	AppendFile(path string, data []byte, perm os.FileMode) error
+
 	// AppendToFileIfMissing adiciona uma linha ao arquivo se ela nao existir.
 	// Retorna true se a linha foi adicionada.
 	AppendToFileIfMissing(path, line string) (bool, error)
+
+	// RemoveLineFromFile remove as linhas do arquivo iguais a line (ignorando
+	// espacos nas pontas). Retorna true se alguma linha foi removida.
+	RemoveLineFromFile(path, line string) (bool, error)
+
+	// Remove apaga um arquivo, symlink ou diretorio vazio.
+	Remove(path string) error
+
+	// ReadLink retorna o destino de um link simbolico.
+	ReadLink(path string) (string, error)
+
+	// ExecPrivileged executa um comando como root pelo backend de elevacao
+	// configurado (sudo, pkexec ou run0) e retorna a saida combinada.
+	// Modulos nunca chamam "sudo" direto via Exec.
+	ExecPrivileged(ctx context.Context, name string, args ...string) (string, error)
+
+	// WriteFilePrivileged escreve um arquivo de sistema (ex: em /etc) com o
+	// modo e o dono dados ("usuario:grupo"; vazio e root:root).
+	WriteFilePrivileged(ctx context.Context, path string, data []byte, perm os.FileMode, owner string) error
 }
 
 // Module representa um modulo de configuracao.
@@ -76,3 +110,29 @@ type Checker interface {
 type Applier interface {
 	Apply(ctx context.Context, sys System, reporter Reporter) error
 }
+
+// Reverter desfaz as mudancas feitas pelo Applier, restaurando o sistema
+// ao estado anterior (arquivos, regras, links e linhas adicionadas).
+type Reverter interface {
+	Revert(ctx context.Context, sys System, reporter Reporter) error
+}
+
+// Dependent declara modulos que precisam rodar antes deste.
+// Os nomes sao validados pelo Registry (nomes desconhecidos e ciclos).
+type Dependent interface {
+	Requires() []string
+}
+
+// Recursos exclusivos conhecidos. Dois modulos que declaram o mesmo recurso
+// nunca rodam ao mesmo tempo na execucao paralela.
+const (
+	ResourceRpmOstree = "rpm-ostree" // transacoes rpm-ostree (uma por vez no sistema)
+	ResourceDconf     = "dconf"      // escrita de chaves dconf/GNOME
+	ResourceSudo      = "sudo"       // comandos como root via ExecPrivileged (evita prompts concorrentes)
+)
+
+// Exclusive declara recursos que o modulo usa de forma exclusiva.
+// Usado pelo orchestrator para serializar modulos que disputam o mesmo recurso.
+type Exclusive interface {
+	Resources() []string
+}
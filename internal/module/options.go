@@ -0,0 +1,215 @@
+package module
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OptionType define o tipo de valor aceito por uma opcao.
+type OptionType int
+
+const (
+	OptionString OptionType = iota
+	OptionInt
+	OptionBool
+)
+
+// String retorna o nome do tipo usado nas mensagens de erro.
+func (t OptionType) String() string {
+	switch t {
+	case OptionInt:
+		return "inteiro"
+	case OptionBool:
+		return "booleano"
+	default:
+		return "texto"
+	}
+}
+
+// Option descreve uma opcao configuravel de um modulo.
+type Option struct {
+	Key         string
+	Type        OptionType
+	Default     any      // valor padrao, do tipo Go correspondente (string, int, bool)
+	Description string   // texto de ajuda (TUI e config show)
+	Choices     []string // OptionString: valores aceitos (vazio = livre)
+
+	// Validate faz validacoes extras apos a conversao de tipo (opcional).
+	Validate func(value any) error
+}
+
+// Parse converte um valor cru (TOML, flag --set ou campo do TUI) para o tipo
+// da opcao e aplica Choices e Validate.
+func (o Option) Parse(raw any) (any, error) {
+	value, err := o.convert(raw)
+	if err != nil {
+		return nil, fmt.Errorf("opcao %s: %w", o.Key, err)
+	}
+	if len(o.Choices) > 0 {
+		s := value.(string)
+		found := false
+		for _, c := range o.Choices {
+			found = found || c == s
+		}
+		if !found {
+			return nil, fmt.Errorf("opcao %s: valor %q invalido (aceitos: %s)", o.Key, s, strings.Join(o.Choices, ", "))
+		}
+	}
+	if o.Validate != nil {
+		if err := o.Validate(value); err != nil {
+			return nil, fmt.Errorf("opcao %s: %w", o.Key, err)
+		}
+	}
+	return value, nil
+}
+
+func (o Option) convert(raw any) (any, error) {
+	switch o.Type {
+	case OptionInt:
+		switch v := raw.(type) {
+		case int:
+			return v, nil
+		case int64:
+			return int(v), nil
+		case float64:
+			if v == float64(int(v)) {
+				return int(v), nil
+			}
+		case string:
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				return n, nil
+			}
+		}
+	case OptionBool:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+	default:
+		if s, ok := raw.(string); ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("esperava %s, obteve %v", o.Type, raw)
+}
+
+// Values guarda os valores das opcoes de um modulo, ja convertidos.
+type Values map[string]any
+
+// String retorna o valor texto da opcao (vazio se ausente).
+func (v Values) String(key string) string {
+	s, _ := v[key].(string)
+	return s
+}
+
+// Int retorna o valor inteiro da opcao (zero se ausente).
+func (v Values) Int(key string) int {
+	n, _ := v[key].(int)
+	return n
+}
+
+// Bool retorna o valor booleano da opcao (false se ausente).
+func (v Values) Bool(key string) bool {
+	b, _ := v[key].(bool)
+	return b
+}
+
+// Configurable expoe o schema de opcoes de um modulo. As opcoes chegam do
+// config.toml ([modules.<nome>.options]), de --set modulo.chave=valor e da
+// tela de opcoes do TUI, sempre via SetOptions (que valida pelo schema).
+type Configurable interface {
+	// OptionSchema descreve as opcoes aceitas.
+	OptionSchema() []Option
+	// OptionValues retorna os valores atuais (padroes antes de Configure).
+	OptionValues() Values
+	// Configure recebe o conjunto completo de valores, ja validados.
+	Configure(values Values) error
+}
+
+// SetOptions valida raw contra o schema, mescla com os valores atuais e
+// chama Configure. Chaves desconhecidas sao erro.
+func SetOptions(c Configurable, raw map[string]any) error {
+	values, err := ParseOptions(c, raw)
+	if err != nil {
+		return err
+	}
+	return c.Configure(values)
+}
+
+// ParseOptions valida raw contra o schema e retorna os valores atuais
+// mesclados com os novos, sem alterar o modulo.
+func ParseOptions(c Configurable, raw map[string]any) (Values, error) {
+	schema := make(map[string]Option)
+	var keys []string
+	for _, o := range c.OptionSchema() {
+		schema[o.Key] = o
+		keys = append(keys, o.Key)
+	}
+
+	values := Values{}
+	for k, v := range c.OptionValues() {
+		values[k] = v
+	}
+
+	names := make([]string, 0, len(raw))
+	for k := range raw {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		opt, ok := schema[k]
+		if !ok {
+			return nil, fmt.Errorf("opcao desconhecida: %s (aceitas: %s)", k, strings.Join(keys, ", "))
+		}
+		v, err := opt.Parse(raw[k])
+		if err != nil {
+			return nil, err
+		}
+		values[k] = v
+	}
+	return values, nil
+}
+
+// OptionSet implementa Configurable a partir de um schema. Modulos embutem
+// um OptionSet e leem os valores com Values().Int/String/Bool.
+type OptionSet struct {
+	schema []Option
+	values Values
+}
+
+// NewOptionSet cria um OptionSet com os valores padrao do schema.
+func NewOptionSet(schema ...Option) OptionSet {
+	values := make(Values, len(schema))
+	for _, o := range schema {
+		values[o.Key] = o.Default
+	}
+	return OptionSet{schema: schema, values: values}
+}
+
+// OptionSchema retorna o schema das opcoes.
+func (s *OptionSet) OptionSchema() []Option { return s.schema }
+
+// OptionValues retorna uma copia dos valores atuais.
+func (s *OptionSet) OptionValues() Values {
+	out := make(Values, len(s.values))
+	for k, v := range s.values {
+		out[k] = v
+	}
+	return out
+}
+
+// Configure substitui os valores atuais.
+func (s *OptionSet) Configure(values Values) error {
+	s.values = values
+	return nil
+}
+
+// Values retorna os valores atuais (sem copia; somente leitura).
+func (s *OptionSet) Values() Values { return s.values }
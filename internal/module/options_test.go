@@ -0,0 +1,92 @@
+package module
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// configurableStub expoe um schema com uma opcao de cada tipo.
+type configurableStub struct {
+	OptionSet
+	configured int
+}
+
+func (c *configurableStub) Configure(values Values) error {
+	c.configured++
+	return c.OptionSet.Configure(values)
+}
+
+func newConfigurableStub() *configurableStub {
+	return &configurableStub{OptionSet: NewOptionSet(
+		Option{Key: "size", Type: OptionInt, Default: 4, Validate: func(v any) error {
+			if v.(int) < 0 {
+				return fmt.Errorf("nao pode ser negativo")
+			}
+			return nil
+		}},
+		Option{Key: "mode", Type: OptionString, Default: "a", Choices: []string{"a", "b"}},
+		Option{Key: "verbose", Type: OptionBool, Default: false},
+	)}
+}
+
+func TestNewOptionSet_Defaults(t *testing.T) {
+	c := newConfigurableStub()
+	values := c.OptionValues()
+	if values.Int("size") != 4 || values.String("mode") != "a" || values.Bool("verbose") {
+		t.Errorf("OptionValues() = %v", values)
+	}
+}
+
+func TestSetOptions(t *testing.T) {
+	c := newConfigurableStub()
+	err := SetOptions(c, map[string]any{"size": int64(8), "mode": "b", "verbose": "true"})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	values := c.Values()
+	if values.Int("size") != 8 || values.String("mode") != "b" || !values.Bool("verbose") {
+		t.Errorf("Values() = %v", values)
+	}
+
+	// Valores ausentes mantem o valor atual
+	if err := SetOptions(c, map[string]any{"size": "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Values().String("mode") != "b" || c.Values().Int("size") != 2 {
+		t.Errorf("Values() = %v", c.Values())
+	}
+}
+
+func TestSetOptions_Invalid(t *testing.T) {
+	tests := []struct {
+		raw     map[string]any
+		wantErr string
+	}{
+		{map[string]any{"width": 1}, "opcao desconhecida: width (aceitas: size, mode, verbose)"},
+		{map[string]any{"size": "oito"}, "opcao size: esperava inteiro"},
+		{map[string]any{"size": 1.5}, "opcao size: esperava inteiro"},
+		{map[string]any{"size": -1}, "opcao size: nao pode ser negativo"},
+		{map[string]any{"mode": "c"}, `opcao mode: valor "c" invalido (aceitos: a, b)`},
+		{map[string]any{"verbose": "talvez"}, "opcao verbose: esperava booleano"},
+		{map[string]any{"mode": 1}, "opcao mode: esperava texto"},
+	}
+	for _, tt := range tests {
+		c := newConfigurableStub()
+		err := SetOptions(c, tt.raw)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("SetOptions(%v) = %v, esperava %q", tt.raw, err, tt.wantErr)
+		}
+		if c.configured != 0 {
+			t.Errorf("SetOptions(%v): Configure nao deveria ser chamado apos erro", tt.raw)
+		}
+	}
+}
+
+func TestOptionValues_IsCopy(t *testing.T) {
+	c := newConfigurableStub()
+	c.OptionValues()["size"] = 99
+	if c.Values().Int("size") != 4 {
+		t.Error("OptionValues deveria retornar uma copia")
+	}
+}
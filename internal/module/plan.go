@@ -0,0 +1,61 @@
+package module
+
+import (
+	"context"
+	"fmt"
+)
+
+// ActionKind identifica o tipo de uma acao planejada.
+type ActionKind string
+
+const (
+	ActionWriteFile        ActionKind = "write-file"        // escreve (ou cria) um arquivo
+	ActionAppendLine       ActionKind = "append-line"       // adiciona uma linha a um arquivo
+	ActionSymlink          ActionKind = "symlink"           // cria um link simbolico
+	ActionRunCommand       ActionKind = "run"               // executa um comando
+	ActionDconfWrite       ActionKind = "dconf-write"       // escreve uma chave dconf
+	ActionInstallExtension ActionKind = "install-extension" // baixa e instala uma extensao GNOME
+	ActionEnableExtension  ActionKind = "enable-extension"  // ativa uma extensao GNOME
+)
+
+// Action descreve uma mudanca que o Apply faria, sem executa-la.
+type Action struct {
+	Kind       ActionKind
+	Target     string // arquivo, chave dconf, UUID da extensao ou comando
+	Value      string // linha, valor dconf ou destino do symlink
+	Content    []byte // write-file: conteudo completo que seria escrito
+	Privileged bool   // executado como root (ExecPrivileged/WriteFilePrivileged)
+}
+
+// String descreve a acao em uma linha.
+func (a Action) String() string {
+	root := ""
+	if a.Privileged {
+		root = "[root] "
+	}
+	switch a.Kind {
+	case ActionWriteFile:
+		return fmt.Sprintf("%sescrever %s (%d bytes)", root, a.Target, len(a.Content))
+	case ActionAppendLine:
+		return fmt.Sprintf("%sadicionar a %s: %s", root, a.Target, a.Value)
+	case ActionSymlink:
+		return fmt.Sprintf("%scriar symlink %s -> %s", root, a.Target, a.Value)
+	case ActionRunCommand:
+		return fmt.Sprintf("%sexecutar: %s", root, a.Target)
+	case ActionDconfWrite:
+		return fmt.Sprintf("dconf %s = %s", a.Target, a.Value)
+	case ActionInstallExtension:
+		return fmt.Sprintf("instalar extensao %s", a.Target)
+	case ActionEnableExtension:
+		return fmt.Sprintf("ativar extensao %s", a.Target)
+	default:
+		return fmt.Sprintf("%s%s %s %s", root, a.Kind, a.Target, a.Value)
+	}
+}
+
+// Planner descreve o que o Apply faria, a partir de verificacoes reais e
+// somente leitura (sem System.DryRun, onde Exec sempre "funciona").
+// Usado por "blueprint plan" antes de qualquer execucao.
+type Planner interface {
+	Plan(ctx context.Context, sys System) ([]Action, error)
+}
@@ -0,0 +1,23 @@
+package module
+
+import "testing"
+
+func TestAction_String(t *testing.T) {
+	tests := []struct {
+		action Action
+		want   string
+	}{
+		{Action{Kind: ActionWriteFile, Target: "/etc/x", Content: []byte("abc"), Privileged: true}, "[root] escrever /etc/x (3 bytes)"},
+		{Action{Kind: ActionAppendLine, Target: "~/.bashrc", Value: "eval x"}, "adicionar a ~/.bashrc: eval x"},
+		{Action{Kind: ActionSymlink, Target: "~/.config/a", Value: "/repo/a"}, "criar symlink ~/.config/a -> /repo/a"},
+		{Action{Kind: ActionRunCommand, Target: "rpm-ostree install x", Privileged: true}, "[root] executar: rpm-ostree install x"},
+		{Action{Kind: ActionDconfWrite, Target: "/org/a", Value: "true"}, "dconf /org/a = true"},
+		{Action{Kind: ActionInstallExtension, Target: "a@b"}, "instalar extensao a@b"},
+		{Action{Kind: ActionEnableExtension, Target: "a@b"}, "ativar extensao a@b"},
+	}
+	for _, tt := range tests {
+		if got := tt.action.String(); got != tt.want {
+			t.Errorf("String() = %q, esperava %q", got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package module
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Registry armazena e organiza os modulos disponiveis.
 type Registry struct {
@@ -36,3 +39,64 @@ func (r *Registry) ByName(name string) (Module, bool) {
 	m, ok := r.byName[name]
 	return m, ok
 }
+
+// Validate verifica as dependencias declaradas pelos modulos (Dependent).
+// Retorna erro se algum modulo depender de um nome nao registrado,
+// de si mesmo, ou se houver ciclo entre dependencias.
+func (r *Registry) Validate() error {
+	for _, m := range r.modules {
+		for _, dep := range Requirements(m) {
+			if dep == m.Name() {
+				return fmt.Errorf("modulo %s depende de si mesmo", m.Name())
+			}
+			if _, ok := r.byName[dep]; !ok {
+				return fmt.Errorf("modulo %s depende de modulo desconhecido: %s", m.Name(), dep)
+			}
+		}
+	}
+
+	// DFS com marcacao de visita para detectar ciclos
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(r.modules))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			start := 0
+			for i, n := range path {
+				if n == name {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("ciclo de dependencias: %s", strings.Join(cycle, " -> "))
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range Requirements(r.byName[name]) {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, m := range r.modules {
+		if err := visit(m.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package module
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 // stubModule para testes do Registry.
 type stubModule struct {
@@ -70,3 +73,50 @@ func TestRegistry_ByName_NotFound(t *testing.T) {
 		t.Fatal("modulo inexistente nao deveria ser encontrado")
 	}
 }
+
+func TestRegistry_Validate_OK(t *testing.T) {
+	reg := NewRegistry()
+	_ = reg.Register(newDep("devbox", "devcontainers"))
+	_ = reg.Register(newDep("devcontainers"))
+
+	if err := reg.Validate(); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+}
+
+func TestRegistry_Validate_UnknownDependency(t *testing.T) {
+	reg := NewRegistry()
+	_ = reg.Register(newDep("devbox", "inexistente"))
+
+	err := reg.Validate()
+	if err == nil {
+		t.Fatal("esperava erro para dependencia desconhecida")
+	}
+	if !strings.Contains(err.Error(), "inexistente") {
+		t.Errorf("erro deveria mencionar a dependencia: %v", err)
+	}
+}
+
+func TestRegistry_Validate_SelfDependency(t *testing.T) {
+	reg := NewRegistry()
+	_ = reg.Register(newDep("a", "a"))
+
+	if err := reg.Validate(); err == nil {
+		t.Fatal("esperava erro para modulo que depende de si mesmo")
+	}
+}
+
+func TestRegistry_Validate_Cycle(t *testing.T) {
+	reg := NewRegistry()
+	_ = reg.Register(newDep("a", "b"))
+	_ = reg.Register(newDep("b", "c"))
+	_ = reg.Register(newDep("c", "a"))
+
+	err := reg.Validate()
+	if err == nil {
+		t.Fatal("esperava erro para ciclo de dependencias")
+	}
+	if !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Errorf("erro deveria descrever o ciclo: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy define quantas vezes uma operacao que falhou e repetida e
+// quanto se espera entre as tentativas. A espera dobra a cada falha
+// (backoff exponencial), ate MaxDelay.
+type RetryPolicy struct {
+	Attempts int           // total de tentativas (1 ou menos: sem retry)
+	Delay    time.Duration // espera antes da segunda tentativa
+	MaxDelay time.Duration // teto da espera (0: sem teto)
+}
+
+// DefaultRetry e a politica dos passos que dependem de rede (downloads,
+// apt-get update, flatpak update): 3 tentativas, esperando 2s e depois 4s.
+var DefaultRetry = RetryPolicy{Attempts: 3, Delay: 2 * time.Second, MaxDelay: 30 * time.Second}
+
+// NoRetry executa a operacao uma unica vez.
+var NoRetry = RetryPolicy{Attempts: 1}
+
+// Opcoes de retry dos modulos com passos de rede (ver RetryOptions).
+const (
+	OptionRetryAttempts = "retry_attempts"
+	OptionRetryDelay    = "retry_delay"
+)
+
+// RetryOptions declara as opcoes que ajustam a politica p de um modulo pelo
+// config.toml ([modules.<nome>.options]), --set ou TUI: o total de
+// tentativas e a espera inicial em segundos. O teto da espera fica em p.
+func RetryOptions(p RetryPolicy) []Option {
+	return []Option{
+		{
+			Key: OptionRetryAttempts, Type: OptionInt, Default: max(p.Attempts, 1),
+			Description: "Total de tentativas dos passos de rede (1: sem retry)",
+			Validate: func(v any) error {
+				if v.(int) < 1 {
+					return fmt.Errorf("precisa ser pelo menos 1")
+				}
+				return nil
+			},
+		},
+		{
+			Key: OptionRetryDelay, Type: OptionInt, Default: int(p.Delay / time.Second),
+			Description: "Espera antes da segunda tentativa, em segundos (dobra a cada falha)",
+			Validate: func(v any) error {
+				if v.(int) < 0 {
+					return fmt.Errorf("nao pode ser negativo")
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Retry retorna base com as tentativas e a espera das opcoes de
+// RetryOptions. Opcoes ausentes mantem os valores de base.
+func (v Values) Retry(base RetryPolicy) RetryPolicy {
+	if n, ok := v[OptionRetryAttempts].(int); ok {
+		base.Attempts = n
+	}
+	if s, ok := v[OptionRetryDelay].(int); ok {
+		base.Delay = time.Duration(s) * time.Second
+	}
+	return base
+}
+
+// wait retorna a espera depois da falha da tentativa n (1 = primeira).
+func (p RetryPolicy) wait(n int) time.Duration {
+	d := p.Delay
+	for i := 1; i < n && (p.MaxDelay == 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// Attempt descreve uma tentativa que falhou e foi repetida.
+type Attempt struct {
+	Op     string        // operacao (ex: "download do Starship")
+	Number int           // tentativa que falhou (1 = primeira)
+	Err    error         // erro da tentativa
+	Wait   time.Duration // espera ate a proxima tentativa
+}
+
+// RetryRecorder e implementado por reporters que guardam as tentativas
+// repetidas (o orchestrator as registra no resultado do modulo).
+type RetryRecorder interface {
+	Retried(a Attempt)
+}
+
+// permanentError marca um erro que nao adianta repetir.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca err como definitivo: Retry desiste sem nova tentativa
+// (ex: resposta invalida da API, versao incompativel).
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent retorna true se err (ou algum erro embrulhado) foi marcado
+// com Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// Retry executa fn ate ela ter sucesso ou as tentativas de p acabarem,
+// esperando com backoff exponencial entre elas. Cada falha repetida e
+// avisada no reporter (Warn) e registrada se ele implementa RetryRecorder.
+// Erros Permanent e o cancelamento (ou fim do prazo) de ctx interrompem na
+// hora. Retorna o erro da ultima tentativa.
+func Retry(ctx context.Context, p RetryPolicy, reporter Reporter, op string, fn func(ctx context.Context) error) error {
+	attempts := max(p.Attempts, 1)
+	for n := 1; ; n++ {
+		err := fn(ctx)
+		if err == nil || n >= attempts || IsPermanent(err) || ctx.Err() != nil {
+			return err
+		}
+
+		a := Attempt{Op: op, Number: n, Err: err, Wait: p.wait(n)}
+		reporter.Warn(fmt.Sprintf("%s falhou (tentativa %d/%d): %v — tentando de novo em %s", op, n, attempts, err, a.Wait))
+		if rec, ok := reporter.(RetryRecorder); ok {
+			rec.Retried(a)
+		}
+
+		if a.Wait > 0 {
+			timer := time.NewTimer(a.Wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// retryReporter guarda os avisos e as tentativas registradas.
+type retryReporter struct {
+	warns    []string
+	attempts []Attempt
+}
+
+func (r *retryReporter) Info(string)           {}
+func (r *retryReporter) Success(string)        {}
+func (r *retryReporter) Warn(msg string)       { r.warns = append(r.warns, msg) }
+func (r *retryReporter) Error(string)          {}
+func (r *retryReporter) Step(int, int, string) {}
+func (r *retryReporter) Retried(a Attempt)     { r.attempts = append(r.attempts, a) }
+
+func TestRetry_SucceedsAfterFailures(t *testing.T) {
+	rep := &retryReporter{}
+	calls := 0
+	err := Retry(context.Background(), RetryPolicy{Attempts: 3}, rep, "download", func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("rede caiu")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("esperava 3 chamadas, obteve %d", calls)
+	}
+	if len(rep.warns) != 2 || len(rep.attempts) != 2 {
+		t.Fatalf("esperava 2 avisos e 2 tentativas registradas, obteve %v / %v", rep.warns, rep.attempts)
+	}
+	if a := rep.attempts[1]; a.Op != "download" || a.Number != 2 || a.Err == nil {
+		t.Errorf("tentativa registrada inesperada: %+v", a)
+	}
+}
+
+func TestRetry_ReturnsLastError(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), RetryPolicy{Attempts: 2}, &retryReporter{}, "op", func(context.Context) error {
+		calls++
+		return errors.New("falhou")
+	})
+	if err == nil || calls != 2 {
+		t.Errorf("esperava erro apos 2 chamadas, obteve %v apos %d", err, calls)
+	}
+}
+
+func TestRetry_NoRetry(t *testing.T) {
+	rep := &retryReporter{}
+	calls := 0
+	_ = Retry(context.Background(), NoRetry, rep, "op", func(context.Context) error {
+		calls++
+		return errors.New("falhou")
+	})
+	if calls != 1 || len(rep.warns) != 0 {
+		t.Errorf("NoRetry deveria chamar 1 vez sem avisos, obteve %d chamadas e %v", calls, rep.warns)
+	}
+}
+
+func TestRetry_PermanentStops(t *testing.T) {
+	base := errors.New("versao incompativel")
+	calls := 0
+	err := Retry(context.Background(), RetryPolicy{Attempts: 5}, &retryReporter{}, "op", func(context.Context) error {
+		calls++
+		return Permanent(base)
+	})
+	if calls != 1 {
+		t.Errorf("erro permanente nao deveria ser repetido, obteve %d chamadas", calls)
+	}
+	if !errors.Is(err, base) || err.Error() != base.Error() {
+		t.Errorf("esperava o erro original, obteve %v", err)
+	}
+}
+
+func TestRetry_CancelDuringWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan error)
+	go func() {
+		done <- Retry(ctx, RetryPolicy{Attempts: 3, Delay: time.Hour}, &retryReporter{}, "op", func(context.Context) error {
+			calls++
+			return errors.New("falhou")
+		})
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil || calls != 1 {
+			t.Errorf("esperava erro apos 1 chamada, obteve %v apos %d", err, calls)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Retry nao parou ao cancelar durante a espera")
+	}
+}
+
+func TestRetryPolicy_Wait(t *testing.T) {
+	p := RetryPolicy{Delay: time.Second, MaxDelay: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := p.wait(i + 1); got != want {
+			t.Errorf("wait(%d) = %s, esperava %s", i+1, got, want)
+		}
+	}
+}
+
+func TestValues_Retry(t *testing.T) {
+	set := NewOptionSet(RetryOptions(DefaultRetry)...)
+	if got := set.Values().Retry(DefaultRetry); got != DefaultRetry {
+		t.Errorf("padrao = %+v, esperava %+v", got, DefaultRetry)
+	}
+
+	if err := SetOptions(&set, map[string]any{OptionRetryAttempts: int64(5), OptionRetryDelay: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	want := RetryPolicy{Attempts: 5, Delay: time.Second, MaxDelay: DefaultRetry.MaxDelay}
+	if got := set.Values().Retry(DefaultRetry); got != want {
+		t.Errorf("Retry() = %+v, esperava %+v", got, want)
+	}
+
+	for _, opts := range []map[string]any{{OptionRetryAttempts: 0}, {OptionRetryDelay: -1}} {
+		if err := SetOptions(&set, opts); err == nil {
+			t.Errorf("SetOptions(%v): esperava erro", opts)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package module
+
+// SessionAction e o que o usuario precisa fazer para uma mudanca valer.
+// A ordem vai da menos para a mais abrangente: um reboot cobre o
+// logout/login, que cobre abrir um novo terminal.
+type SessionAction int
+
+const (
+	SessionRestartShell SessionAction = iota + 1 // abrir um novo terminal (rc do shell)
+	SessionRelogin                               // logout/login (extensoes GNOME, XCompose)
+	SessionReboot                                // reiniciar (deployment rpm-ostree, dev mode)
+)
+
+// String retorna a acao como exibida no resumo.
+func (a SessionAction) String() string {
+	switch a {
+	case SessionRestartShell:
+		return "abrir um novo terminal"
+	case SessionRelogin:
+		return "logout/login"
+	case SessionReboot:
+		return "reiniciar o sistema"
+	default:
+		return "desconhecida"
+	}
+}
+
+// Key retorna o identificador estavel da acao (report JSON/YAML e historico).
+func (a SessionAction) Key() string {
+	switch a {
+	case SessionRestartShell:
+		return "restart_shell"
+	case SessionRelogin:
+		return "relogin"
+	case SessionReboot:
+		return "reboot"
+	default:
+		return "unknown"
+	}
+}
+
+// SessionRequest e uma acao de sessao pedida por um modulo, com o motivo.
+type SessionRequest struct {
+	Action SessionAction
+	Reason string
+}
+
+// SessionRecorder e implementado por reporters que guardam as acoes de
+// sessao (o orchestrator as registra no resultado do modulo).
+type SessionRecorder interface {
+	RequireSession(req SessionRequest)
+}
+
+// RequireSession declara que uma mudanca aplicada so vale depois de action.
+// O motivo aparece como aviso no progresso e, se o reporter implementa
+// SessionRecorder, entra no resultado do modulo (resumo consolidado e
+// --reboot/--logout).
+func RequireSession(reporter Reporter, action SessionAction, reason string) {
+	reporter.Warn(reason)
+	if rec, ok := reporter.(SessionRecorder); ok {
+		rec.RequireSession(SessionRequest{Action: action, Reason: reason})
+	}
+}
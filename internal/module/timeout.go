@@ -0,0 +1,31 @@
+package module
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timeouts limita o tempo de cada etapa de um modulo. Zero usa o padrao
+// global (--timeout); sem padrao, a etapa nao tem limite.
+type Timeouts struct {
+	Check time.Duration
+	Apply time.Duration // vale tambem para o Revert
+}
+
+// Timeouter declara limites de tempo proprios para o Check e o Apply.
+// Util para modulos que dependem de rede ou de comandos que podem travar
+// (ex: curl, rpm-ostree upgrade --check).
+type Timeouter interface {
+	Timeouts() Timeouts
+}
+
+// TimeoutError indica que uma etapa excedeu o limite de tempo, ou que um
+// comando ficou tempo demais sem produzir saida (--idle-timeout).
+type TimeoutError struct {
+	Op    string // o que estourou (ex: "apply", "rpm-ostree sem saida")
+	After time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s: tempo esgotado apos %s", e.Op, e.After)
+}
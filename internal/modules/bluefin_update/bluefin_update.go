@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ale/blueprint/internal/module"
 )
@@ -16,12 +17,20 @@ type step struct {
 	cmd      string   // Comando principal
 	args     []string // Argumentos
 	optional bool     // Se true, pula se o comando nao existir
+	network  bool     // Se true, repete em falhas (rede instavel) conforme as opcoes de retry
 }
 
 // Module implementa a atualizacao do sistema Bluefin.
-type Module struct{}
+// As opcoes de retry do OptionSet embutido valem para os passos que so
+// baixam dados (flatpak update, fwupdmgr refresh). O rpm-ostree nao e
+// repetido: uma transacao interrompida precisa de atencao manual.
+type Module struct {
+	module.OptionSet
+}
 
-func New() *Module { return &Module{} }
+func New() *Module {
+	return &Module{OptionSet: module.NewOptionSet(module.RetryOptions(module.DefaultRetry)...)}
+}
 
 func (m *Module) Name() string { return "bluefin-update" }
 func (m *Module) Description() string {
@@ -29,6 +38,14 @@ func (m *Module) Description() string {
 }
 func (m *Module) Tags() []string { return []string{"system"} }
 
+// Resources declara que o modulo roda transacoes rpm-ostree.
+func (m *Module) Resources() []string { return []string{module.ResourceRpmOstree} }
+
+// Timeouts limita o Check: "rpm-ostree upgrade --check" e "flatpak
+// remote-ls" consultam a rede e podem travar. O Apply (upgrade) pode
+// demorar legitimamente; fica com o padrao global e o --idle-timeout.
+func (m *Module) Timeouts() module.Timeouts { return module.Timeouts{Check: 2 * time.Minute} }
+
 // ShouldRun retorna false dentro de containers.
 func (m *Module) ShouldRun(_ context.Context, sys module.System) (bool, string) {
 	if sys.IsContainer() {
@@ -83,15 +100,17 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 			args: []string{"upgrade"},
 		},
 		{
-			name: "Flatpak (aplicativos)",
-			cmd:  "flatpak",
-			args: []string{"update", "-y"},
+			name:    "Flatpak (aplicativos)",
+			cmd:     "flatpak",
+			args:    []string{"update", "-y"},
+			network: true,
 		},
 		{
 			name:     "fwupd (firmware)",
 			cmd:      "fwupdmgr",
 			args:     []string{"refresh"},
 			optional: true,
+			network:  true,
 		},
 		{
 			name:     "fwupd (atualizar firmware)",
@@ -119,9 +138,15 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 			return fmt.Errorf("comando obrigatorio nao encontrado: %s — voce esta rodando em um sistema Bluefin?", s.cmd)
 		}
 
-		err := sys.ExecStream(ctx, func(line string) {
-			reporter.Info(line)
-		}, s.cmd, s.args...)
+		policy := module.NoRetry
+		if s.network {
+			policy = m.Values().Retry(module.DefaultRetry)
+		}
+		err := module.Retry(ctx, policy, reporter, s.name, func(ctx context.Context) error {
+			return sys.ExecStream(ctx, func(line string) {
+				reporter.Info(line)
+			}, s.cmd, s.args...)
+		})
 
 		if err != nil {
 			if s.optional {
@@ -192,6 +192,9 @@ func TestApply_OptionalFailureContinues(t *testing.T) {
 	mock.ExecResults["fwupdmgr refresh"] = system.ExecResult{Err: fmt.Errorf("falha")}
 
 	mod := New()
+	if err := module.SetOptions(mod, map[string]any{module.OptionRetryAttempts: 1, module.OptionRetryDelay: 0}); err != nil {
+		t.Fatal(err)
+	}
 	reporter := moduletest.NoopReporter()
 
 	err := mod.Apply(context.Background(), mock, reporter)
@@ -200,6 +203,37 @@ func TestApply_OptionalFailureContinues(t *testing.T) {
 	}
 }
 
+func TestApply_RetriesNetworkSteps(t *testing.T) {
+	mock := system.NewMock()
+	mock.Commands["rpm-ostree"] = true
+	mock.Commands["flatpak"] = true
+	mock.ExecResults["rpm-ostree upgrade"] = system.ExecResult{Err: fmt.Errorf("upgrade failed")}
+	mock.ExecResults["flatpak update -y"] = system.ExecResult{Err: fmt.Errorf("Could not resolve host")}
+
+	mod := New()
+	if err := module.SetOptions(mod, map[string]any{module.OptionRetryAttempts: 3, module.OptionRetryDelay: 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	// rpm-ostree nao e repetido
+	if err := mod.Apply(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Fatal("esperava erro do rpm-ostree")
+	}
+	if len(mock.ExecLog) != 1 {
+		t.Errorf("rpm-ostree nao deveria ser repetido: %v", mock.ExecLog)
+	}
+
+	// flatpak update e repetido ate esgotar as tentativas
+	delete(mock.ExecResults, "rpm-ostree upgrade")
+	mock.ExecLog = nil
+	if err := mod.Apply(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Fatal("esperava erro do flatpak")
+	}
+	if got := strings.Count(strings.Join(mock.ExecLog, "\n"), "flatpak update -y"); got != 3 {
+		t.Errorf("esperava 3 tentativas do flatpak update, obteve %d: %v", got, mock.ExecLog)
+	}
+}
+
 func TestApply_RequiredCommandFails(t *testing.T) {
 	mock := system.NewMock()
 	mock.Commands["rpm-ostree"] = true
@@ -71,11 +71,45 @@ func (m *Module) Apply(_ context.Context, sys module.System, reporter module.Rep
 
 	// 2. Preparar ~/.XCompose
 	reporter.Step(2, 3, "Preparando ~/.XCompose...")
+	if !sys.FileExists(xcompose) {
+		reporter.Info("Criando ~/.XCompose")
+	}
+
+	// 3. Adicionar regras
+	reporter.Step(3, 3, "Adicionando regras de cedilha...")
+	content, err := render(sys, xcompose)
+	if err != nil {
+		return err
+	}
+
+	if err := sys.WriteFile(xcompose, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("erro ao escrever ~/.XCompose: %w", err)
+	}
+
+	reporter.Success("Regras de cedilha configuradas")
+	module.RequireSession(reporter, module.SessionRelogin, "Faca logout e login para aplicar as mudancas")
+
+	return nil
+}
+
+// Plan descreve a escrita do ~/.XCompose com as regras de cedilha.
+func (m *Module) Plan(_ context.Context, sys module.System) ([]module.Action, error) {
+	xcompose := sys.HomeDir() + "/.XCompose"
+	content, err := render(sys, xcompose)
+	if err != nil {
+		return nil, err
+	}
+	return []module.Action{{Kind: module.ActionWriteFile, Target: xcompose, Content: []byte(content)}}, nil
+}
+
+// render monta o novo conteudo do ~/.XCompose: o arquivo atual (ou um
+// include "%L" padrao) sem o bloco antigo, com as regras no final.
+func render(sys module.System, xcompose string) (string, error) {
 	var content string
 	if sys.FileExists(xcompose) {
 		data, err := sys.ReadFile(xcompose)
 		if err != nil {
-			return fmt.Errorf("erro ao ler ~/.XCompose: %w", err)
+			return "", fmt.Errorf("erro ao ler ~/.XCompose: %w", err)
 		}
 		content = string(data)
 
@@ -84,7 +118,6 @@ func (m *Module) Apply(_ context.Context, sys module.System, reporter module.Rep
 	} else {
 		// Cria com include padrao
 		content = `include "%L"` + "\n"
-		reporter.Info("Criando ~/.XCompose")
 	}
 
 	// Garante include "%L" no inicio
@@ -92,16 +125,36 @@ func (m *Module) Apply(_ context.Context, sys module.System, reporter module.Rep
 		content = `include "%L"` + "\n\n" + content
 	}
 
-	// 3. Adicionar regras
-	reporter.Step(3, 3, "Adicionando regras de cedilha...")
-	content = strings.TrimRight(content, "\n") + "\n" + composeRules + "\n"
+	return strings.TrimRight(content, "\n") + "\n" + composeRules + "\n", nil
+}
+
+// Revert remove o bloco de regras de cedilha do ~/.XCompose, preservando o resto do arquivo.
+func (m *Module) Revert(_ context.Context, sys module.System, reporter module.Reporter) error {
+	xcompose := sys.HomeDir() + "/.XCompose"
+
+	if !sys.FileExists(xcompose) {
+		reporter.Info("~/.XCompose nao existe, nada a remover")
+		return nil
+	}
+
+	data, err := sys.ReadFile(xcompose)
+	if err != nil {
+		return fmt.Errorf("erro ao ler ~/.XCompose: %w", err)
+	}
 
+	content := string(data)
+	if !strings.Contains(content, beginMarker) {
+		reporter.Info("Regras de cedilha ja ausentes")
+		return nil
+	}
+
+	content = strings.TrimRight(removeBlock(content, beginMarker, endMarker), "\n") + "\n"
 	if err := sys.WriteFile(xcompose, []byte(content), 0o644); err != nil {
 		return fmt.Errorf("erro ao escrever ~/.XCompose: %w", err)
 	}
 
-	reporter.Success("Regras de cedilha configuradas")
-	reporter.Info("Faca logout e login para aplicar as mudancas")
+	reporter.Success("Regras de cedilha removidas")
+	module.RequireSession(reporter, module.SessionRelogin, "Faca logout e login para aplicar as mudancas")
 
 	return nil
 }
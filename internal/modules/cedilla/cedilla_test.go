@@ -190,3 +190,71 @@ func TestApply_ReadFileFailsOnExistingFile(t *testing.T) {
 		t.Error("esperava erro quando ReadFile falha em arquivo existente")
 	}
 }
+
+func TestRevert_RemovesBlock(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/home/test/.XCompose"] = []byte(`include "%L"
+
+<Multi_key> <a> <a> : "å"
+` + composeRules + "\n")
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	content := string(mock.Files["/home/test/.XCompose"])
+	if strings.Contains(content, beginMarker) || strings.Contains(content, `"ç"`) {
+		t.Errorf("bloco de cedilha nao foi removido: %q", content)
+	}
+	if !strings.Contains(content, `<Multi_key> <a> <a> : "å"`) {
+		t.Errorf("regras do usuario deveriam ser mantidas: %q", content)
+	}
+	if !strings.HasSuffix(content, "\n") || strings.HasSuffix(content, "\n\n") {
+		t.Errorf("arquivo deveria terminar com uma unica quebra de linha: %q", content)
+	}
+}
+
+func TestRevert_NoFile(t *testing.T) {
+	mock := system.NewMock()
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if _, ok := mock.Files["/home/test/.XCompose"]; ok {
+		t.Error("nao deveria criar ~/.XCompose")
+	}
+}
+
+func TestRevert_WriteFails(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/home/test/.XCompose"] = []byte(composeRules + "\n")
+	mock.WriteFileErr = fmt.Errorf("disco cheio")
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Error("esperava erro quando WriteFile falha")
+	}
+}
+
+func TestPlan_MatchesApply(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/home/test/.XCompose"] = []byte("# minhas regras\n")
+
+	mod := New()
+	actions, err := mod.Plan(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Kind != module.ActionWriteFile || actions[0].Target != "/home/test/.XCompose" {
+		t.Fatalf("Plan() = %v", actions)
+	}
+
+	if err := mod.Apply(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if string(actions[0].Content) != string(mock.Files["/home/test/.XCompose"]) {
+		t.Errorf("conteudo planejado difere do escrito:\nplano: %q\napply: %q", actions[0].Content, mock.Files["/home/test/.XCompose"])
+	}
+}
@@ -14,14 +14,22 @@ import (
 const extensionUUID = "clipboard-indicator@tudmotu.com"
 
 // Module implementa a instalação do Clipboard Indicator.
-type Module struct{}
+// As opcoes de retry do download da extensao vem do OptionSet embutido.
+type Module struct {
+	module.OptionSet
+}
 
-func New() *Module { return &Module{} }
+func New() *Module {
+	return &Module{OptionSet: module.NewOptionSet(module.RetryOptions(module.DefaultRetry)...)}
+}
 
 func (m *Module) Name() string        { return "clipboard-indicator" }
 func (m *Module) Description() string { return "Clipboard Indicator (historico de clipboard no GNOME)" }
 func (m *Module) Tags() []string      { return []string{"desktop"} }
 
+// Timeouts limita o download da extensao (ver gnome.ExtensionTimeouts).
+func (m *Module) Timeouts() module.Timeouts { return gnome.ExtensionTimeouts }
+
 func (m *Module) ShouldRun(_ context.Context, sys module.System) (bool, string) {
 	return gnome.ShouldRunGuard(sys)
 }
@@ -46,7 +54,7 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 	out, _ := sys.Exec(ctx, "gnome-extensions", "show", extensionUUID)
 	if !strings.Contains(out, extensionUUID) {
 		reporter.Info("Baixando Clipboard Indicator de extensions.gnome.org...")
-		if err := gnome.InstallFromGnomeExtensions(ctx, sys, extensionUUID, gnomeVer, "Clipboard Indicator"); err != nil {
+		if err := gnome.InstallWithRetry(ctx, sys, m.Values().Retry(module.DefaultRetry), reporter, extensionUUID, gnomeVer, "Clipboard Indicator"); err != nil {
 			return fmt.Errorf("erro ao instalar Clipboard Indicator: %w", err)
 		}
 		reporter.Success("Clipboard Indicator instalado")
@@ -56,12 +64,28 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 
 	// 3. Ativar
 	reporter.Step(3, total, "Ativando Clipboard Indicator...")
+	relogin := "Faca logout e login se o Clipboard Indicator nao aparecer imediatamente"
 	if _, err := sys.Exec(ctx, "gnome-extensions", "enable", extensionUUID); err != nil {
-		reporter.Warn("Clipboard Indicator sera ativado apos re-login")
+		relogin = "Clipboard Indicator sera ativado apos re-login"
 	} else {
 		reporter.Success("Clipboard Indicator ativo")
 	}
 
-	reporter.Info("Faca logout e login se nao aparecer imediatamente")
+	module.RequireSession(reporter, module.SessionRelogin, relogin)
+	return nil
+}
+
+// Plan descreve a instalacao e ativacao da extensao.
+func (m *Module) Plan(ctx context.Context, sys module.System) ([]module.Action, error) {
+	return gnome.PlanExtension(ctx, sys, extensionUUID), nil
+}
+
+// Revert desativa e desinstala o Clipboard Indicator.
+func (m *Module) Revert(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	reporter.Step(1, 1, "Desinstalando Clipboard Indicator...")
+	if err := gnome.UninstallExtension(ctx, sys, extensionUUID); err != nil {
+		return err
+	}
+	reporter.Success("Clipboard Indicator removido")
 	return nil
 }
@@ -135,3 +135,32 @@ func TestApply_AlreadyInstalled(t *testing.T) {
 		t.Fatalf("erro inesperado: %v", err)
 	}
 }
+
+func TestRevert_UninstallsExtension(t *testing.T) {
+	mock := system.NewMock()
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	found := false
+	for _, cmd := range mock.ExecLog {
+		if cmd == "gnome-extensions uninstall "+extensionUUID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("esperava desinstalar a extensao, exec log: %v", mock.ExecLog)
+	}
+}
+
+func TestRevert_UninstallFails(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["gnome-extensions uninstall "+extensionUUID] = system.ExecResult{Err: fmt.Errorf("erro")}
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Error("esperava erro quando uninstall falha")
+	}
+}
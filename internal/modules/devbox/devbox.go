@@ -10,8 +10,18 @@ import (
 	"github.com/ale/blueprint/internal/module"
 )
 
+// DefaultImage e a imagem base do container devbox.
+const DefaultImage = "quay.io/toolbx/ubuntu-toolbox:24.04"
+
+// DefaultName e o nome padrao do container.
+const DefaultName = "devbox"
+
 // Module implementa a criacao e provisionamento do devbox.
+// As opcoes image, name, home e as de retry do apt-get no WSL vem do
+// OptionSet embutido.
 type Module struct {
+	module.OptionSet
+
 	// SetupScript e o caminho absoluto para configs/devbox/setup-dev.sh.
 	SetupScript string
 }
@@ -19,13 +29,71 @@ type Module struct {
 // New cria o modulo devbox.
 // setupScript deve ser o caminho absoluto para configs/devbox/setup-dev.sh.
 func New(setupScript string) *Module {
-	return &Module{SetupScript: setupScript}
+	return &Module{
+		SetupScript: setupScript,
+		OptionSet: module.NewOptionSet(append([]module.Option{
+			{
+				Key: "image", Type: module.OptionString, Default: DefaultImage,
+				Description: "Imagem base do distrobox create", Validate: notEmpty,
+			},
+			{
+				Key: "name", Type: module.OptionString, Default: DefaultName,
+				Description: "Nome do container", Validate: notEmpty,
+			},
+			{
+				Key: "home", Type: module.OptionString, Default: "",
+				Description: "Home do container no host (vazio: ~/.distrobox/<nome>)", Validate: validHome,
+			},
+		}, module.RetryOptions(module.DefaultRetry)...)...),
+	}
 }
 
 func (m *Module) Name() string        { return "devbox" }
 func (m *Module) Description() string { return "Distrobox de desenvolvimento (criacao + provisionamento)" }
 func (m *Module) Tags() []string      { return []string{"containers", "wsl"} }
 
+// Requires declara que o devbox depende do podman configurado pelo devcontainers.
+// Em perfis sem o devcontainers (ex: wsl) a dependencia nao se aplica.
+func (m *Module) Requires() []string { return []string{"devcontainers"} }
+
+// Resources declara sudo (comandos como root), usado para instalar o podman no WSL.
+func (m *Module) Resources() []string { return []string{module.ResourceSudo} }
+
+// notEmpty rejeita valores texto vazios.
+func notEmpty(v any) error {
+	if strings.TrimSpace(v.(string)) == "" {
+		return fmt.Errorf("nao pode ser vazia")
+	}
+	return nil
+}
+
+// validHome aceita um caminho absoluto ou relativo ao home ("~/...").
+// Vazio usa o padrao ~/.distrobox/<nome>.
+func validHome(v any) error {
+	s := v.(string)
+	if s != "" && !filepath.IsAbs(s) && !strings.HasPrefix(s, "~/") {
+		return fmt.Errorf("use um caminho absoluto ou comecando com ~/: %q", s)
+	}
+	return nil
+}
+
+// container retorna o nome configurado do container.
+func (m *Module) container() string { return m.Values().String("name") }
+
+// homePath retorna o home do container no host: a opcao home ou, sem ela,
+// ~/.distrobox/<nome>.
+func (m *Module) homePath(sys module.System) string {
+	home := m.Values().String("home")
+	switch {
+	case home == "":
+		return filepath.Join(sys.HomeDir(), ".distrobox", m.container())
+	case strings.HasPrefix(home, "~/"):
+		return filepath.Join(sys.HomeDir(), home[2:])
+	default:
+		return filepath.Clean(home)
+	}
+}
+
 func (m *Module) ShouldRun(_ context.Context, sys module.System) (bool, string) {
 	if sys.IsContainer() {
 		return false, "dentro de container"
@@ -39,11 +107,12 @@ func (m *Module) Check(ctx context.Context, sys module.System) (module.Status, e
 		return module.Status{Kind: module.Missing, Message: "distrobox list falhou"}, nil
 	}
 
-	if hasContainer(output, "devbox") {
-		return module.Status{Kind: module.Installed, Message: "container devbox existe"}, nil
+	name := m.container()
+	if hasContainer(output, name) {
+		return module.Status{Kind: module.Installed, Message: fmt.Sprintf("container %s existe", name)}, nil
 	}
 
-	return module.Status{Kind: module.Missing, Message: "container devbox nao existe"}, nil
+	return module.Status{Kind: module.Missing, Message: fmt.Sprintf("container %s nao existe", name)}, nil
 }
 
 // hasContainer verifica se um container com o nome exato existe na saida do distrobox list.
@@ -65,31 +134,20 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 		if !sys.CommandExists("podman") {
 			reporter.Step(0, 3, "Instalando Podman (necessario para WSL)...")
 			// Assume apt-get pois WSL geralmente e Ubuntu/Debian
-			if _, err := sys.Exec(ctx, "sudo", "apt-get", "update"); err != nil {
+			if err := m.execPrivileged(ctx, sys, reporter, "apt-get", "update"); err != nil {
 				reporter.Warn("apt-get update falhou")
 			}
-			if _, err := sys.Exec(ctx, "sudo", "apt-get", "install", "-y", "podman"); err != nil {
+			if err := m.execPrivileged(ctx, sys, reporter, "apt-get", "install", "-y", "podman"); err != nil {
 				return fmt.Errorf("erro ao instalar podman: %w. Por favor instale manualmente", err)
 			}
 			reporter.Success("Podman instalado")
 		}
 	}
 
-	reporter.Step(1, 3, "Criando container devbox...")
-	
-	baseArgs := []string{
-		"distrobox", "create",
-		"--name", "devbox",
-		"--image", "quay.io/toolbx/ubuntu-toolbox:24.04",
-		"--yes",
-	}
+	name := m.container()
+	reporter.Step(1, 3, fmt.Sprintf("Criando container %s...", name))
 
-	// No WSL, --home customizado em volume montado causa erro de "not a shared mount".
-	// Usamos o home padrao do host.
-	if !sys.IsWSL() {
-		homePath := filepath.Join(sys.HomeDir(), ".distrobox", "devbox")
-		baseArgs = append(baseArgs, "--home", homePath)
-	}
+	baseArgs := m.createArgs(sys)
 
 	var err error
 	if usePodman {
@@ -104,12 +162,12 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 		// Ignora erro se o container ja existe
 		reporter.Warn(fmt.Sprintf("distrobox create retornou erro (container pode ja existir): %v", err))
 	} else {
-		reporter.Success("Container devbox criado")
+		reporter.Success(fmt.Sprintf("Container %s criado", name))
 	}
 
 	// TODO: considerar ExecStream para dar feedback em tempo real (setup demora minutos)
 	reporter.Step(2, 3, "Provisionando devbox...")
-	_, err = sys.Exec(ctx, "distrobox", "enter", "devbox", "--", "bash", m.SetupScript)
+	_, err = sys.Exec(ctx, "distrobox", "enter", name, "--", "bash", m.SetupScript)
 	if err != nil {
 		return fmt.Errorf("erro ao provisionar devbox: %w", err)
 	}
@@ -118,7 +176,7 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 	// Determina o caminho do home usado para o container
 	containerHome := sys.HomeDir()
 	if !sys.IsWSL() {
-		containerHome = filepath.Join(sys.HomeDir(), ".distrobox", "devbox")
+		containerHome = m.homePath(sys)
 	}
 
 	// O distrobox usa --userns keep-id, mapeando UID 0 no container para um
@@ -132,7 +190,7 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 		if user == "" {
 			user = "1000" // Fallback seguro
 		}
-		_, err = sys.Exec(ctx, "distrobox", "enter", "devbox", "--",
+		_, err = sys.Exec(ctx, "distrobox", "enter", name, "--",
 			"sudo", "chown", "-R", fmt.Sprintf("%s:%s", user, user), vscodeDir)
 		if err != nil {
 			reporter.Warn(fmt.Sprintf("chown .vscode-server falhou: %v", err))
@@ -145,7 +203,72 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 
 	reporter.Info("VS Code: rode em cada maquina cliente onde usa o VS Code:")
 	reporter.Info("  curl -fsSL https://raw.githubusercontent.com/ale/blueprint/main/scripts/vscode-devbox.sh | bash")
-	reporter.Info("Depois abra VS Code > Attach to Running Container > devbox")
+	reporter.Info("Depois abra VS Code > Attach to Running Container > " + name)
 
 	return nil
 }
+
+// execPrivileged executa como root um comando que depende de rede, com as
+// novas tentativas das opcoes de retry.
+func (m *Module) execPrivileged(ctx context.Context, sys module.System, reporter module.Reporter, name string, args ...string) error {
+	return module.Retry(ctx, m.Values().Retry(module.DefaultRetry), reporter, strings.Join(append([]string{name}, args...), " "), func(ctx context.Context) error {
+		_, err := sys.ExecPrivileged(ctx, name, args...)
+		return err
+	})
+}
+
+// createArgs monta o distrobox create do container.
+func (m *Module) createArgs(sys module.System) []string {
+	args := []string{
+		"distrobox", "create",
+		"--name", m.container(),
+		"--image", m.Values().String("image"),
+		"--yes",
+	}
+
+	// No WSL, --home customizado em volume montado causa erro de "not a shared mount".
+	// Usamos o home padrao do host.
+	if !sys.IsWSL() {
+		args = append(args, "--home", m.homePath(sys))
+	}
+	return args
+}
+
+// Plan descreve a instalacao do podman (WSL), a criacao do container e o
+// provisionamento. O container e sempre reprovisionado pelo Apply.
+func (m *Module) Plan(ctx context.Context, sys module.System) ([]module.Action, error) {
+	var actions []module.Action
+	if sys.IsWSL() && !sys.CommandExists("podman") {
+		actions = append(actions, module.Action{Kind: module.ActionRunCommand, Target: "apt-get install -y podman", Privileged: true})
+	}
+
+	exists := false
+	if out, err := sys.Exec(ctx, "distrobox", "list"); err == nil {
+		exists = hasContainer(out, m.container())
+	}
+	if !exists {
+		actions = append(actions, module.Action{Kind: module.ActionRunCommand, Target: strings.Join(m.createArgs(sys), " ")})
+	}
+
+	actions = append(actions, module.Action{
+		Kind:   module.ActionRunCommand,
+		Target: fmt.Sprintf("distrobox enter %s -- bash %s", m.container(), m.SetupScript),
+	})
+	return actions, nil
+}
+
+// Revert remove o container devbox. O home do container
+// (opcao home, padrao ~/.distrobox/<nome>) e mantido para nao perder dados do usuario.
+func (m *Module) Revert(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	name := m.container()
+	reporter.Step(1, 1, fmt.Sprintf("Removendo container %s...", name))
+	if _, err := sys.Exec(ctx, "distrobox", "rm", "--force", name); err != nil {
+		return fmt.Errorf("erro ao remover %s: %w", name, err)
+	}
+	reporter.Success(fmt.Sprintf("Container %s removido", name))
+
+	if !sys.IsWSL() {
+		reporter.Info(fmt.Sprintf("Home do container mantido em %s", m.homePath(sys)))
+	}
+	return nil
+}
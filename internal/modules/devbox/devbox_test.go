@@ -3,6 +3,7 @@ package devbox
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/ale/blueprint/internal/module"
@@ -34,6 +35,15 @@ func TestShouldRun_RunOutsideContainer(t *testing.T) {
 	}
 }
 
+func TestRequires_Devcontainers(t *testing.T) {
+	mod := New("/repo/configs/devbox/setup-dev.sh")
+
+	deps := mod.Requires()
+	if len(deps) != 1 || deps[0] != "devcontainers" {
+		t.Errorf("esperava [devcontainers], obteve %v", deps)
+	}
+}
+
 func TestCheck_Missing(t *testing.T) {
 	mock := system.NewMock()
 	mock.ExecResults["distrobox list"] = system.ExecResult{Output: "ID | NAME | STATUS | IMAGE\n"}
@@ -178,6 +188,30 @@ func TestApply_SuccessWSL(t *testing.T) {
 	}
 }
 
+func TestApply_WSLRetriesAptGet(t *testing.T) {
+	mock := system.NewMock()
+	mock.WSL = true
+	mock.EnvVars["USER"] = "ale"
+	mock.ExecResults["sudo apt-get install -y podman"] = system.ExecResult{Err: fmt.Errorf("Temporary failure resolving archive.ubuntu.com")}
+
+	mod := New("/repo/configs/devbox/setup-dev.sh")
+	if err := module.SetOptions(mod, map[string]any{module.OptionRetryAttempts: 2, module.OptionRetryDelay: 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mod.Apply(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Fatal("esperava erro quando o apt-get install falha")
+	}
+	expected := []string{
+		"sudo apt-get update",
+		"sudo apt-get install -y podman",
+		"sudo apt-get install -y podman",
+	}
+	if strings.Join(mock.ExecLog, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("comandos: esperava %v, obteve %v", expected, mock.ExecLog)
+	}
+}
+
 func TestApply_CreateFailsContinues(t *testing.T) {
 	mock := system.NewMock()
 	mock.EnvVars["USER"] = "ale"
@@ -213,3 +247,99 @@ func TestApply_SetupFails(t *testing.T) {
 		t.Error("esperava erro quando setup falha")
 	}
 }
+
+func TestRevert_RemovesContainer(t *testing.T) {
+	mock := system.NewMock()
+
+	mod := New("/repo/configs/devbox/setup-dev.sh")
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(mock.ExecLog) != 1 || mock.ExecLog[0] != "distrobox rm --force devbox" {
+		t.Errorf("exec log inesperado: %v", mock.ExecLog)
+	}
+}
+
+func TestRevert_RemoveFails(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["distrobox rm --force devbox"] = system.ExecResult{Err: fmt.Errorf("erro")}
+
+	mod := New("/repo/configs/devbox/setup-dev.sh")
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Error("esperava erro quando distrobox rm falha")
+	}
+}
+
+func TestConfigure_Image(t *testing.T) {
+	mock := system.NewMock()
+
+	mod := New("/repo/configs/devbox/setup-dev.sh")
+	if err := module.SetOptions(mod, map[string]any{"image": "quay.io/toolbx/fedora-toolbox:41"}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	_ = mod.Apply(context.Background(), mock, moduletest.NoopReporter())
+
+	if len(mock.ExecLog) == 0 || !strings.Contains(mock.ExecLog[0], "--image quay.io/toolbx/fedora-toolbox:41") {
+		t.Errorf("esperava a imagem configurada no create, obteve %v", mock.ExecLog)
+	}
+}
+
+func TestConfigure_InvalidImage(t *testing.T) {
+	mod := New("/repo/setup.sh")
+	for _, opts := range []map[string]any{{"image": ""}, {"image": int64(1)}, {"tag": "x"}} {
+		if err := module.SetOptions(mod, opts); err == nil {
+			t.Errorf("SetOptions(%v): esperava erro", opts)
+		}
+	}
+	if got := mod.OptionValues().String("image"); got != DefaultImage {
+		t.Errorf("imagem alterada apos erro: %q", got)
+	}
+}
+
+func TestConfigure_Name(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["distrobox list"] = system.ExecResult{Output: "ID | NAME | STATUS | IMAGE\nabc | work | Up | ubuntu\n"}
+
+	mod := New("/repo/setup.sh")
+	if err := module.SetOptions(mod, map[string]any{"name": "work"}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	status, _ := mod.Check(context.Background(), mock)
+	if status.Kind != module.Installed {
+		t.Errorf("esperava Installed para o container work, obteve %v", status)
+	}
+
+	_ = mod.Revert(context.Background(), mock, moduletest.NoopReporter())
+	want := "distrobox rm --force work"
+	if mock.ExecLog[len(mock.ExecLog)-1] != want {
+		t.Errorf("esperava %q, obteve %v", want, mock.ExecLog)
+	}
+}
+
+func TestConfigure_Home(t *testing.T) {
+	tests := []struct {
+		home string
+		want string
+	}{
+		{"", "/home/test/.distrobox/devbox"},
+		{"~/containers/dev", "/home/test/containers/dev"},
+		{"/data/devbox/", "/data/devbox"},
+	}
+	for _, tt := range tests {
+		mock := system.NewMock()
+		mod := New("/repo/setup.sh")
+		if err := module.SetOptions(mod, map[string]any{"home": tt.home}); err != nil {
+			t.Fatalf("SetOptions(%q): %v", tt.home, err)
+		}
+		_ = mod.Apply(context.Background(), mock, moduletest.NoopReporter())
+		if len(mock.ExecLog) == 0 || !strings.HasSuffix(mock.ExecLog[0], "--home "+tt.want) {
+			t.Errorf("home %q: esperava --home %s, obteve %v", tt.home, tt.want, mock.ExecLog)
+		}
+	}
+
+	if err := module.SetOptions(New("/repo/setup.sh"), map[string]any{"home": "containers/dev"}); err == nil {
+		t.Error("caminho relativo deveria ser rejeitado")
+	}
+}
@@ -15,6 +15,9 @@ func (m *Module) Name() string        { return "devcontainers" }
 func (m *Module) Description() string { return "Dev Containers (dev mode + podman-docker)" }
 func (m *Module) Tags() []string      { return []string{"system"} }
 
+// Resources declara que o modulo roda transacoes rpm-ostree.
+func (m *Module) Resources() []string { return []string{module.ResourceRpmOstree} }
+
 func (m *Module) ShouldRun(_ context.Context, sys module.System) (bool, string) {
 	if sys.IsContainer() {
 		return false, "dentro de container"
@@ -57,7 +60,7 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 	}
 	reporter.Success("podman-docker instalado")
 
-	reporter.Warn("Reboot necessario para aplicar as alteracoes")
+	module.RequireSession(reporter, module.SessionReboot, "Reboot necessario para aplicar as alteracoes")
 	return nil
 }
 
@@ -30,6 +30,9 @@ func (m *Module) Name() string        { return "gnome-focus-mode" }
 func (m *Module) Description() string { return "Modo foco: F11 = fullscreen + workspace exclusivo" }
 func (m *Module) Tags() []string      { return []string{"desktop"} }
 
+// Resources declara que o modulo escreve chaves dconf.
+func (m *Module) Resources() []string { return []string{module.ResourceDconf} }
+
 func (m *Module) ShouldRun(_ context.Context, sys module.System) (bool, string) {
 	return gnome.ShouldRunGuard(sys)
 }
@@ -97,7 +100,7 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 	reporter.Step(3, 3, "Ativando extensao...")
 	_, err := sys.Exec(ctx, "gnome-extensions", "enable", extensionUUID)
 	if err != nil {
-		reporter.Warn("Extensao sera ativada apos re-login")
+		module.RequireSession(reporter, module.SessionRelogin, "Extensao focus-mode sera ativada apos re-login")
 	} else {
 		reporter.Success("Focus mode ativo")
 	}
@@ -105,3 +108,14 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 	reporter.Info("F11 agora envia a janela para um workspace exclusivo")
 	return nil
 }
+
+// Revert desativa e desinstala a extensao focus-mode.
+// Workspaces dinamicos sao o padrao do GNOME e permanecem ativos.
+func (m *Module) Revert(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	reporter.Step(1, 1, "Desinstalando extensao focus-mode...")
+	if err := gnome.UninstallExtension(ctx, sys, extensionUUID); err != nil {
+		return err
+	}
+	reporter.Success("Focus mode removido")
+	return nil
+}
@@ -327,3 +327,32 @@ func TestShouldRun_SkipWithoutGnomeExtensions(t *testing.T) {
 		t.Error("deveria pular sem gnome-extensions")
 	}
 }
+
+func TestRevert_UninstallsExtension(t *testing.T) {
+	mock := system.NewMock()
+
+	mod := New("/configs/focus-mode")
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	found := false
+	for _, cmd := range mock.ExecLog {
+		if cmd == "gnome-extensions uninstall "+extensionUUID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("esperava desinstalar a extensao, exec log: %v", mock.ExecLog)
+	}
+}
+
+func TestRevert_UninstallFails(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["gnome-extensions uninstall "+extensionUUID] = system.ExecResult{Err: fmt.Errorf("erro")}
+
+	mod := New("/configs/focus-mode")
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Error("esperava erro quando uninstall falha")
+	}
+}
@@ -4,20 +4,86 @@ package passwordless
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/sysfile"
 )
 
+const gdmConf = "/etc/gdm/custom.conf"
+
+// Valores da opcao target.
+const (
+	TargetBoth      = "both"
+	TargetSudo      = "sudo"
+	TargetAutologin = "autologin"
+)
+
+// userPattern aceita nomes de usuario POSIX; o nome vai para o sudoers e
+// para o nome do arquivo em /etc/sudoers.d.
+var userPattern = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
 // Module implementa sudo sem senha e login automatico no GDM.
-type Module struct{}
+// As opcoes user (vazio: o usuario atual, $USER) e target (sudo, autologin
+// ou both) vem do OptionSet embutido.
+type Module struct {
+	module.OptionSet
+}
 
-func New() *Module { return &Module{} }
+func New() *Module {
+	return &Module{OptionSet: module.NewOptionSet(
+		module.Option{
+			Key: "user", Type: module.OptionString, Default: "",
+			Description: "Usuario configurado (vazio: o usuario atual)", Validate: validUser,
+		},
+		module.Option{
+			Key: "target", Type: module.OptionString, Default: TargetBoth,
+			Description: "O que configurar: sudo sem senha, login automatico ou ambos",
+			Choices:     []string{TargetBoth, TargetSudo, TargetAutologin},
+		},
+	)}
+}
 
 func (m *Module) Name() string        { return "passwordless" }
 func (m *Module) Description() string { return "Sudo sem senha e login automatico no GDM" }
 func (m *Module) Tags() []string      { return []string{"system"} }
 
+// Resources declara que o modulo executa comandos como root.
+func (m *Module) Resources() []string { return []string{module.ResourceSudo} }
+
+// validUser rejeita nomes que nao sao de usuario (vazio e aceito).
+func validUser(v any) error {
+	if s := v.(string); s != "" && !userPattern.MatchString(s) {
+		return fmt.Errorf("usuario invalido: %q", s)
+	}
+	return nil
+}
+
+// user retorna o usuario configurado ou, sem a opcao, o usuario atual.
+func (m *Module) user(sys module.System) string {
+	if user := m.Values().String("user"); user != "" {
+		return user
+	}
+	return sys.Env("USER")
+}
+
+// targets retorna quais partes a opcao target seleciona.
+func (m *Module) targets() (sudo, autologin bool) {
+	target := m.Values().String("target")
+	return target != TargetAutologin, target != TargetSudo
+}
+
+// sudoersFile retorna o sudoers sem senha de user.
+func sudoersFile(user string) sysfile.File {
+	return sysfile.File{
+		Path:     "/etc/sudoers.d/nopasswd-" + user,
+		Content:  []byte(user + " ALL=(ALL) NOPASSWD: ALL\n"),
+		Mode:     0o440,
+		Validate: sysfile.Visudo,
+	}
+}
+
 // ShouldRun retorna false dentro de containers.
 func (m *Module) ShouldRun(_ context.Context, sys module.System) (bool, string) {
 	if sys.IsContainer() {
@@ -26,10 +92,27 @@ func (m *Module) ShouldRun(_ context.Context, sys module.System) (bool, string)
 	return true, ""
 }
 
-// Check verifica se sudo sem senha e login automatico estao configurados.
+// Check verifica se as partes selecionadas (sudo sem senha e/ou login
+// automatico) estao configuradas para o usuario.
 func (m *Module) Check(ctx context.Context, sys module.System) (module.Status, error) {
-	sudoOK := checkSudo(ctx, sys)
-	gdmOK := checkGDM(sys)
+	user := m.user(sys)
+	wantSudo, wantGDM := m.targets()
+
+	switch {
+	case !wantGDM:
+		if checkSudo(ctx, sys, user) {
+			return module.Status{Kind: module.Installed, Message: "Sudo sem senha configurado"}, nil
+		}
+		return module.Status{Kind: module.Missing, Message: "Sudo com senha"}, nil
+	case !wantSudo:
+		if checkGDM(sys, user) {
+			return module.Status{Kind: module.Installed, Message: "Login automatico configurado"}, nil
+		}
+		return module.Status{Kind: module.Missing, Message: "Login manual"}, nil
+	}
+
+	sudoOK := checkSudo(ctx, sys, user)
+	gdmOK := checkGDM(sys, user)
 
 	switch {
 	case sudoOK && gdmOK:
@@ -47,22 +130,23 @@ func (m *Module) Check(ctx context.Context, sys module.System) (module.Status, e
 	}
 }
 
-// checkSudo testa se sudo funciona sem senha.
-func checkSudo(ctx context.Context, sys module.System) bool {
+// checkSudo testa se sudo funciona sem senha. Para outro usuario que nao o
+// atual, confere o sudoers instalado pelo modulo.
+func checkSudo(ctx context.Context, sys module.System, user string) bool {
+	if user != "" && user != sys.Env("USER") {
+		return sysfile.Unchanged(ctx, sys, sudoersFile(user))
+	}
 	_, err := sys.Exec(ctx, "sudo", "-n", "true")
 	return err == nil
 }
 
-// checkGDM verifica se o login automatico esta configurado no GDM.
-func checkGDM(sys module.System) bool {
-	const gdmConf = "/etc/gdm/custom.conf"
-
+// checkGDM verifica se o login automatico de user esta configurado no GDM.
+func checkGDM(sys module.System, user string) bool {
 	data, err := sys.ReadFile(gdmConf)
 	if err != nil {
 		return false
 	}
 
-	user := sys.Env("USER")
 	if user == "" {
 		return false
 	}
@@ -72,61 +156,116 @@ func checkGDM(sys module.System) bool {
 		strings.Contains(content, "AutomaticLogin="+user)
 }
 
-// Apply configura sudo sem senha e login automatico no GDM.
+// Apply configura as partes selecionadas: sudo sem senha e/ou login
+// automatico no GDM.
 func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.Reporter) error {
-	user := sys.Env("USER")
+	user := m.user(sys)
 	if user == "" {
 		return fmt.Errorf("variavel USER nao definida")
 	}
+	wantSudo, wantGDM := m.targets()
+	total := 1
+	if wantSudo && wantGDM {
+		total = 2
+	}
+	step := 0
 
-	cacheDir := sys.HomeDir() + "/.cache"
+	// Sudo sem senha
+	if wantSudo {
+		step++
+		reporter.Step(step, total, "Configurando sudo sem senha...")
 
-	// Step 1 — Sudo sem senha
-	reporter.Step(1, 2, "Configurando sudo sem senha...")
+		changed, err := sysfile.Install(ctx, sys, sudoersFile(user))
+		if err != nil {
+			return fmt.Errorf("erro ao configurar sudoers: %w", err)
+		}
+		if changed {
+			reporter.Success("Sudo sem senha configurado")
+		} else {
+			reporter.Success("Sudo sem senha ja configurado (sem alteracoes)")
+		}
+	}
+	if !wantGDM {
+		return nil
+	}
 
-	sudoersContent := user + " ALL=(ALL) NOPASSWD: ALL\n"
-	tmpSudoers := cacheDir + "/blueprint-nopasswd"
-	sudoersTarget := "/etc/sudoers.d/nopasswd-" + user
+	// Login automatico no GDM
+	step++
+	reporter.Step(step, total, "Configurando login automatico no GDM...")
 
-	if err := sys.WriteFile(tmpSudoers, []byte(sudoersContent), 0o644); err != nil {
-		return fmt.Errorf("erro ao escrever arquivo temporario de sudoers: %w", err)
+	gdmContent, err := sys.ReadFile(gdmConf)
+	if err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", gdmConf, err)
 	}
 
-	if _, err := sys.Exec(ctx, "sudo", "visudo", "-c", "-f", tmpSudoers); err != nil {
-		return fmt.Errorf("validacao do sudoers falhou: %w", err)
+	changed, err := sysfile.Install(ctx, sys, sysfile.File{
+		Path:    gdmConf,
+		Content: []byte(setGDMAutoLogin(string(gdmContent), user)),
+		Mode:    0o644,
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao gravar configuracao do GDM: %w", err)
 	}
-
-	if _, err := sys.Exec(ctx, "sudo", "cp", tmpSudoers, sudoersTarget); err != nil {
-		return fmt.Errorf("erro ao copiar sudoers: %w", err)
+	if !changed {
+		reporter.Success("Login automatico ja configurado (sem alteracoes)")
+		return nil
 	}
 
-	if _, err := sys.Exec(ctx, "sudo", "chmod", "0440", sudoersTarget); err != nil {
-		return fmt.Errorf("erro ao ajustar permissoes do sudoers: %w", err)
+	reporter.Success("Login automatico configurado")
+
+	return nil
+}
+
+// Revert desfaz as partes selecionadas: remove o sudoers sem senha e/ou
+// desativa o login automatico no GDM.
+func (m *Module) Revert(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	user := m.user(sys)
+	if user == "" {
+		return fmt.Errorf("variavel USER nao definida")
+	}
+	wantSudo, wantGDM := m.targets()
+	total := 1
+	if wantSudo && wantGDM {
+		total = 2
 	}
+	step := 0
+
+	// Sudo com senha
+	if wantSudo {
+		step++
+		reporter.Step(step, total, "Removendo sudo sem senha...")
+
+		if _, err := sys.ExecPrivileged(ctx, "rm", "-f", sudoersFile(user).Path); err != nil {
+			return fmt.Errorf("erro ao remover sudoers: %w", err)
+		}
 
-	reporter.Success("Sudo sem senha configurado")
+		reporter.Success("Sudo volta a pedir senha")
+	}
+	if !wantGDM {
+		return nil
+	}
 
-	// Step 2 — Login automatico no GDM
-	reporter.Step(2, 2, "Configurando login automatico no GDM...")
+	// Login manual no GDM
+	step++
+	reporter.Step(step, total, "Desativando login automatico no GDM...")
 
-	const gdmConf = "/etc/gdm/custom.conf"
 	gdmContent, err := sys.ReadFile(gdmConf)
 	if err != nil {
-		return fmt.Errorf("erro ao ler %s: %w", gdmConf, err)
+		reporter.Info(fmt.Sprintf("%s nao encontrado, pulando", gdmConf))
+		return nil
 	}
 
-	newContent := setGDMAutoLogin(string(gdmContent), user)
-	tmpGDM := cacheDir + "/blueprint-gdm-custom.conf"
-
-	if err := sys.WriteFile(tmpGDM, []byte(newContent), 0o644); err != nil {
-		return fmt.Errorf("erro ao escrever arquivo temporario do GDM: %w", err)
+	newContent := unsetGDMAutoLogin(string(gdmContent))
+	if newContent == string(gdmContent) {
+		reporter.Info("Login automatico ja desativado")
+		return nil
 	}
 
-	if _, err := sys.Exec(ctx, "sudo", "cp", tmpGDM, gdmConf); err != nil {
-		return fmt.Errorf("erro ao copiar configuracao do GDM: %w", err)
+	if _, err := sysfile.Install(ctx, sys, sysfile.File{Path: gdmConf, Content: []byte(newContent), Mode: 0o644}); err != nil {
+		return fmt.Errorf("erro ao gravar configuracao do GDM: %w", err)
 	}
 
-	reporter.Success("Login automatico configurado")
+	reporter.Success("Login automatico desativado")
 
 	return nil
 }
@@ -188,3 +327,35 @@ func setGDMAutoLogin(content, user string) string {
 
 	return strings.Join(result, "\n")
 }
+
+// unsetGDMAutoLogin desativa o login automatico na secao [daemon]:
+// AutomaticLoginEnable passa a False e a chave AutomaticLogin e removida.
+func unsetGDMAutoLogin(content string) string {
+	lines := strings.Split(content, "\n")
+	var result []string
+	inDaemon := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "[") {
+			inDaemon = strings.EqualFold(trimmed, "[daemon]")
+		}
+
+		if inDaemon {
+			key, _, found := strings.Cut(trimmed, "=")
+			key = strings.TrimSpace(key)
+			if found && key == "AutomaticLoginEnable" {
+				result = append(result, "AutomaticLoginEnable=False")
+				continue
+			}
+			if found && key == "AutomaticLogin" {
+				continue
+			}
+		}
+
+		result = append(result, line)
+	}
+
+	return strings.Join(result, "\n")
+}
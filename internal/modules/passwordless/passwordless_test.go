@@ -156,21 +156,18 @@ func TestApply_ConfiguresBoth(t *testing.T) {
 		t.Fatalf("erro inesperado: %v", err)
 	}
 
-	// Verifica sudoers temporario
-	sudoersData, ok := mock.Files["/home/test/.cache/blueprint-nopasswd"]
-	if !ok {
-		t.Fatal("arquivo temporario de sudoers nao criado")
+	// Preparados ao lado do destino (nada no home), validados e movidos no lugar
+	if got := string(mock.Files["/etc/sudoers.d/.blueprint-nopasswd-ale"]); got != "ale ALL=(ALL) NOPASSWD: ALL\n" {
+		t.Errorf("conteudo do sudoers inesperado: %q", got)
 	}
-	if string(sudoersData) != "ale ALL=(ALL) NOPASSWD: ALL\n" {
-		t.Errorf("conteudo do sudoers inesperado: %q", string(sudoersData))
+	for path := range mock.Files {
+		if strings.HasPrefix(path, "/home/test/") {
+			t.Errorf("nao deveria escrever no home: %s", path)
+		}
 	}
 
-	// Verifica GDM temporario
-	gdmData, ok := mock.Files["/home/test/.cache/blueprint-gdm-custom.conf"]
-	if !ok {
-		t.Fatal("arquivo temporario do GDM nao criado")
-	}
-	gdmContent := string(gdmData)
+	// Verifica GDM
+	gdmContent := string(mock.Files["/etc/gdm/.blueprint-custom.conf"])
 	if !strings.Contains(gdmContent, "AutomaticLoginEnable=True") {
 		t.Error("AutomaticLoginEnable nao configurado")
 	}
@@ -179,23 +176,15 @@ func TestApply_ConfiguresBoth(t *testing.T) {
 	}
 
 	// Verifica comandos executados
-	expectedCmds := []string{
-		"sudo visudo -c -f /home/test/.cache/blueprint-nopasswd",
-		"sudo cp /home/test/.cache/blueprint-nopasswd /etc/sudoers.d/nopasswd-ale",
-		"sudo chmod 0440 /etc/sudoers.d/nopasswd-ale",
-		"sudo cp /home/test/.cache/blueprint-gdm-custom.conf /etc/gdm/custom.conf",
+	expected := []string{
+		"sudo install -m 0440 -o root -g root /etc/sudoers.d/.blueprint-nopasswd-ale",
+		"sudo visudo -c -f /etc/sudoers.d/.blueprint-nopasswd-ale",
+		"sudo mv -f /etc/sudoers.d/.blueprint-nopasswd-ale /etc/sudoers.d/nopasswd-ale",
+		"sudo install -m 0644 -o root -g root /etc/gdm/.blueprint-custom.conf",
+		"sudo mv -f /etc/gdm/.blueprint-custom.conf /etc/gdm/custom.conf",
 	}
-	for _, cmd := range expectedCmds {
-		found := false
-		for _, logged := range mock.ExecLog {
-			if logged == cmd {
-				found = true
-				break
-			}
-		}
-		if !found {
-			t.Errorf("comando esperado nao executado: %s", cmd)
-		}
+	if strings.Join(mock.ExecLog, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("comandos: esperava %v, obteve %v", expected, mock.ExecLog)
 	}
 }
 
@@ -215,24 +204,11 @@ func TestApply_UserEmpty(t *testing.T) {
 	}
 }
 
-func TestApply_SudoersWriteFails(t *testing.T) {
-	mock := system.NewMock()
-	mock.EnvVars["USER"] = "ale"
-	mock.WriteFileErr = fmt.Errorf("disco cheio")
-
-	mod := New()
-	reporter := moduletest.NoopReporter()
-
-	err := mod.Apply(context.Background(), mock, reporter)
-	if err == nil {
-		t.Error("esperava erro quando WriteFile falha")
-	}
-}
-
 func TestApply_VisudoValidationFails(t *testing.T) {
 	mock := system.NewMock()
 	mock.EnvVars["USER"] = "ale"
-	mock.ExecResults["sudo visudo -c -f /home/test/.cache/blueprint-nopasswd"] = system.ExecResult{
+	mock.Files["/etc/gdm/custom.conf"] = []byte("[daemon]\n")
+	mock.ExecResults["sudo visudo -c -f /etc/sudoers.d/.blueprint-nopasswd-ale"] = system.ExecResult{
 		Err: fmt.Errorf("syntax error"),
 	}
 
@@ -246,12 +222,35 @@ func TestApply_VisudoValidationFails(t *testing.T) {
 	if !strings.Contains(err.Error(), "validacao") {
 		t.Errorf("mensagem de erro deveria mencionar validacao: %v", err)
 	}
+	for _, logged := range mock.ExecLog {
+		if strings.HasPrefix(logged, "sudo mv") || strings.Contains(logged, "gdm") {
+			t.Errorf("sudoers invalido nao deveria ser instalado: %v", mock.ExecLog)
+		}
+	}
+}
+
+func TestApply_SudoersUnchanged(t *testing.T) {
+	mock := system.NewMock()
+	mock.EnvVars["USER"] = "ale"
+	mock.Files["/etc/gdm/custom.conf"] = []byte(setGDMAutoLogin("[daemon]\n", "ale"))
+	mock.Files["/etc/sudoers.d/nopasswd-ale"] = []byte("ale ALL=(ALL) NOPASSWD: ALL\n")
+	mock.ExecResults["sudo stat -c %a %U:%G /etc/sudoers.d/nopasswd-ale"] = system.ExecResult{Output: "440 root:root"}
+	mock.ExecResults["sudo stat -c %a %U:%G /etc/gdm/custom.conf"] = system.ExecResult{Output: "644 root:root"}
+
+	if err := New().Apply(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	for _, logged := range mock.ExecLog {
+		if strings.HasPrefix(logged, "sudo install") || strings.HasPrefix(logged, "sudo mv") {
+			t.Errorf("arquivos iguais nao deveriam ser reescritos: %v", mock.ExecLog)
+		}
+	}
 }
 
-func TestApply_SudoCpFails(t *testing.T) {
+func TestApply_SudoersInstallFails(t *testing.T) {
 	mock := system.NewMock()
 	mock.EnvVars["USER"] = "ale"
-	mock.ExecResults["sudo cp /home/test/.cache/blueprint-nopasswd /etc/sudoers.d/nopasswd-ale"] = system.ExecResult{
+	mock.ExecResults["sudo install -m 0440 -o root -g root /etc/sudoers.d/.blueprint-nopasswd-ale"] = system.ExecResult{
 		Err: fmt.Errorf("permissao negada"),
 	}
 
@@ -260,7 +259,7 @@ func TestApply_SudoCpFails(t *testing.T) {
 
 	err := mod.Apply(context.Background(), mock, reporter)
 	if err == nil {
-		t.Error("esperava erro quando sudo cp falha")
+		t.Error("esperava erro quando a instalacao do sudoers falha")
 	}
 }
 
@@ -278,11 +277,11 @@ func TestApply_GDMReadFails(t *testing.T) {
 	}
 }
 
-func TestApply_GDMCpFails(t *testing.T) {
+func TestApply_GDMInstallFails(t *testing.T) {
 	mock := system.NewMock()
 	mock.EnvVars["USER"] = "ale"
 	mock.Files["/etc/gdm/custom.conf"] = []byte("[daemon]\n")
-	mock.ExecResults["sudo cp /home/test/.cache/blueprint-gdm-custom.conf /etc/gdm/custom.conf"] = system.ExecResult{
+	mock.ExecResults["sudo install -m 0644 -o root -g root /etc/gdm/.blueprint-custom.conf"] = system.ExecResult{
 		Err: fmt.Errorf("permissao negada"),
 	}
 
@@ -291,7 +290,7 @@ func TestApply_GDMCpFails(t *testing.T) {
 
 	err := mod.Apply(context.Background(), mock, reporter)
 	if err == nil {
-		t.Error("esperava erro quando sudo cp do GDM falha")
+		t.Error("esperava erro quando a instalacao do GDM falha")
 	}
 }
 
@@ -336,3 +335,184 @@ func TestSetGDMAutoLogin_DaemonIsLastSection(t *testing.T) {
 		t.Error("AutomaticLogin nao adicionado")
 	}
 }
+
+func TestRevert_RemovesSudoersAndAutoLogin(t *testing.T) {
+	mock := system.NewMock()
+	mock.EnvVars["USER"] = "ale"
+	mock.Files["/etc/gdm/custom.conf"] = []byte("[daemon]\nAutomaticLoginEnable=True\nAutomaticLogin=ale\n\n[security]\n")
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	gdmContent := string(mock.Files["/etc/gdm/.blueprint-custom.conf"])
+	if !strings.Contains(gdmContent, "AutomaticLoginEnable=False") {
+		t.Errorf("login automatico nao desativado: %q", gdmContent)
+	}
+	if strings.Contains(gdmContent, "AutomaticLogin=ale") {
+		t.Errorf("AutomaticLogin deveria ser removido: %q", gdmContent)
+	}
+
+	expectedCmds := []string{
+		"sudo rm -f /etc/sudoers.d/nopasswd-ale",
+		"sudo install -m 0644 -o root -g root /etc/gdm/.blueprint-custom.conf",
+		"sudo mv -f /etc/gdm/.blueprint-custom.conf /etc/gdm/custom.conf",
+	}
+	for _, cmd := range expectedCmds {
+		found := false
+		for _, logged := range mock.ExecLog {
+			if logged == cmd {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("comando esperado nao executado: %s", cmd)
+		}
+	}
+}
+
+func TestRevert_AutoLoginAlreadyDisabled(t *testing.T) {
+	mock := system.NewMock()
+	mock.EnvVars["USER"] = "ale"
+	mock.Files["/etc/gdm/custom.conf"] = []byte("[daemon]\nAutomaticLoginEnable=False\n")
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	for _, logged := range mock.ExecLog {
+		if strings.HasPrefix(logged, "sudo install") {
+			t.Errorf("nao deveria gravar o GDM sem mudancas: %s", logged)
+		}
+	}
+}
+
+func TestRevert_UserEmpty(t *testing.T) {
+	mock := system.NewMock()
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Error("esperava erro quando USER nao definido")
+	}
+}
+
+func TestRevert_SudoRmFails(t *testing.T) {
+	mock := system.NewMock()
+	mock.EnvVars["USER"] = "ale"
+	mock.ExecResults["sudo rm -f /etc/sudoers.d/nopasswd-ale"] = system.ExecResult{Err: fmt.Errorf("permissao negada")}
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Error("esperava erro quando sudo rm falha")
+	}
+}
+
+func TestUnsetGDMAutoLogin(t *testing.T) {
+	input := "[daemon]\nAutomaticLoginEnable = True\nAutomaticLogin=ale\n\n[security]\nAutomaticLogin=keep\n"
+	result := unsetGDMAutoLogin(input)
+
+	expected := "[daemon]\nAutomaticLoginEnable=False\n\n[security]\nAutomaticLogin=keep\n"
+	if result != expected {
+		t.Errorf("esperava %q, obteve %q", expected, result)
+	}
+}
+
+func TestCheck_AutologinOnly(t *testing.T) {
+	mock := system.NewMock()
+	mock.EnvVars["USER"] = "ale"
+	mock.ExecResults["sudo -n true"] = system.ExecResult{Err: fmt.Errorf("senha necessaria")}
+	mock.Files["/etc/gdm/custom.conf"] = []byte("[daemon]\nAutomaticLoginEnable=True\nAutomaticLogin=ale\n")
+
+	mod := New()
+	if err := module.SetOptions(mod, map[string]any{"target": TargetAutologin}); err != nil {
+		t.Fatal(err)
+	}
+	status, err := mod.Check(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if status.Kind != module.Installed {
+		t.Errorf("sudo fora do target nao deveria contar: %s (%s)", status.Kind, status.Message)
+	}
+}
+
+func TestCheck_OtherUserReadsSudoers(t *testing.T) {
+	mock := system.NewMock()
+	mock.EnvVars["USER"] = "ale"
+	mock.Files["/etc/sudoers.d/nopasswd-maria"] = []byte("maria ALL=(ALL) NOPASSWD: ALL\n")
+	mock.ExecResults["sudo stat -c %a %U:%G /etc/sudoers.d/nopasswd-maria"] = system.ExecResult{Output: "440 root:root"}
+
+	mod := New()
+	if err := module.SetOptions(mod, map[string]any{"user": "maria", "target": TargetSudo}); err != nil {
+		t.Fatal(err)
+	}
+	status, err := mod.Check(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if status.Kind != module.Installed {
+		t.Errorf("esperava Installed, obteve %s (%s)", status.Kind, status.Message)
+	}
+	for _, cmd := range mock.ExecLog {
+		if cmd == "sudo -n true" {
+			t.Error("sudo -n true testa o usuario atual, nao o configurado")
+		}
+	}
+}
+
+func TestApply_SudoOnlyForOtherUser(t *testing.T) {
+	mock := system.NewMock()
+	mock.EnvVars["USER"] = "ale"
+
+	mod := New()
+	if err := module.SetOptions(mod, map[string]any{"user": "maria", "target": TargetSudo}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mod.Apply(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if got := string(mock.Files["/etc/sudoers.d/.blueprint-nopasswd-maria"]); got != "maria ALL=(ALL) NOPASSWD: ALL\n" {
+		t.Errorf("conteudo do sudoers inesperado: %q", got)
+	}
+	for _, cmd := range mock.ExecLog {
+		if strings.Contains(cmd, "gdm") {
+			t.Errorf("target sudo nao deveria tocar no GDM: %s", cmd)
+		}
+	}
+}
+
+func TestRevert_AutologinOnly(t *testing.T) {
+	mock := system.NewMock()
+	mock.EnvVars["USER"] = "ale"
+	mock.Files["/etc/gdm/custom.conf"] = []byte("[daemon]\nAutomaticLoginEnable=True\nAutomaticLogin=ale\n")
+
+	mod := New()
+	if err := module.SetOptions(mod, map[string]any{"target": TargetAutologin}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	for _, cmd := range mock.ExecLog {
+		if strings.Contains(cmd, "sudoers") {
+			t.Errorf("target autologin nao deveria tocar no sudoers: %s", cmd)
+		}
+	}
+}
+
+func TestConfigure_Invalid(t *testing.T) {
+	tests := []map[string]any{
+		{"user": "ale ALL=(ALL) ALL"},
+		{"user": "../x"},
+		{"target": "gdm"},
+	}
+	for _, opts := range tests {
+		if err := module.SetOptions(New(), opts); err == nil {
+			t.Errorf("SetOptions(%v): esperava erro", opts)
+		}
+	}
+}
@@ -12,7 +12,10 @@ import (
 )
 
 // Module implementa a configuracao do Starship.
+// As opcoes de retry do download do instalador vem do OptionSet embutido.
 type Module struct {
+	module.OptionSet
+
 	// ConfigSource e o caminho absoluto do starship.toml no repo.
 	ConfigSource string
 }
@@ -20,7 +23,10 @@ type Module struct {
 // New cria o modulo Starship.
 // configSource deve ser o caminho absoluto para configs/starship.toml.
 func New(configSource string) *Module {
-	return &Module{ConfigSource: configSource}
+	return &Module{
+		ConfigSource: configSource,
+		OptionSet:    module.NewOptionSet(module.RetryOptions(module.DefaultRetry)...),
+	}
 }
 
 func (m *Module) Name() string        { return "starship" }
@@ -63,7 +69,10 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 	// 1. Instalar Starship se ausente
 	if !sys.CommandExists("starship") {
 		reporter.Step(1, 4, "Instalando Starship...")
-		_, err := sys.Exec(ctx, "sh", "-c", `curl -sS https://starship.rs/install.sh | sh -s -- -y`)
+		err := module.Retry(ctx, m.Values().Retry(module.DefaultRetry), reporter, "instalacao do Starship", func(ctx context.Context) error {
+			_, err := sys.Exec(ctx, "sh", "-c", `curl -sS https://starship.rs/install.sh | sh -s -- -y`)
+			return err
+		})
 		if err != nil {
 			return fmt.Errorf("erro ao instalar starship (verifique sua conexao com a internet): %w", err)
 		}
@@ -117,7 +126,82 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 		reporter.Info("Sem .zshrc, pulando")
 	}
 
+	module.RequireSession(reporter, module.SessionRestartShell, "Abra um novo terminal para carregar o prompt Starship")
 	return nil
 }
 
+// Plan descreve instalacao, symlink da config e init dos shells pendentes.
+func (m *Module) Plan(_ context.Context, sys module.System) ([]module.Action, error) {
+	var actions []module.Action
+	if !sys.CommandExists("starship") {
+		actions = append(actions, module.Action{Kind: module.ActionRunCommand, Target: "curl -sS https://starship.rs/install.sh | sh -s -- -y"})
+	}
+
+	configDest := filepath.Join(sys.HomeDir(), ".config", "starship.toml")
+	if target, err := sys.ReadLink(configDest); err != nil || target != m.ConfigSource {
+		actions = append(actions, module.Action{Kind: module.ActionSymlink, Target: configDest, Value: m.ConfigSource})
+	}
+
+	shells := []struct {
+		rc, line string
+		optional bool
+	}{
+		{".bashrc", `eval "$(starship init bash)"`, false},
+		{".zshrc", `eval "$(starship init zsh)"`, true},
+	}
+	for _, sh := range shells {
+		rc := filepath.Join(sys.HomeDir(), sh.rc)
+		if sh.optional && !sys.FileExists(rc) {
+			continue
+		}
+		if data, err := sys.ReadFile(rc); err == nil && strings.Contains(string(data), sh.line) {
+			continue
+		}
+		actions = append(actions, module.Action{Kind: module.ActionAppendLine, Target: rc, Value: sh.line})
+	}
+	return actions, nil
+}
+
+// Revert remove o symlink da config (se apontar para o repo) e o init dos shells.
+// O binario do starship e mantido, pois pode ser usado fora do blueprint.
+func (m *Module) Revert(_ context.Context, sys module.System, reporter module.Reporter) error {
+	// 1. Remover symlink da config
+	reporter.Step(1, 3, "Removendo starship.toml...")
+	configDest := filepath.Join(sys.HomeDir(), ".config", "starship.toml")
+	target, err := sys.ReadLink(configDest)
+	switch {
+	case err != nil && sys.FileExists(configDest):
+		reporter.Warn("starship.toml nao e um symlink do blueprint, mantido")
+	case err != nil:
+		reporter.Info("starship.toml nao existe")
+	case target != m.ConfigSource:
+		reporter.Warn(fmt.Sprintf("starship.toml aponta para %s, mantido", target))
+	default:
+		if err := sys.Remove(configDest); err != nil {
+			return fmt.Errorf("erro ao remover symlink: %w", err)
+		}
+		reporter.Success("Symlink removido: starship.toml")
+	}
+
+	// 2-3. Remover init dos shells
+	shells := []struct{ rc, line string }{
+		{".bashrc", `eval "$(starship init bash)"`},
+		{".zshrc", `eval "$(starship init zsh)"`},
+	}
+	for i, sh := range shells {
+		reporter.Step(i+2, 3, fmt.Sprintf("Limpando %s...", sh.rc))
+		removed, err := sys.RemoveLineFromFile(filepath.Join(sys.HomeDir(), sh.rc), sh.line)
+		if err != nil {
+			return fmt.Errorf("erro ao limpar %s: %w", sh.rc, err)
+		}
+		if removed {
+			reporter.Success(fmt.Sprintf("Starship removido do %s", sh.rc))
+		} else {
+			reporter.Info(fmt.Sprintf("Starship nao estava no %s", sh.rc))
+		}
+	}
 
+	reporter.Info("O binario do starship foi mantido")
+	module.RequireSession(reporter, module.SessionRestartShell, "Abra um novo terminal para usar o prompt padrao")
+	return nil
+}
@@ -139,12 +139,18 @@ func TestApply_InstallFails(t *testing.T) {
 	}
 
 	mod := New("/repo/configs/starship.toml")
+	if err := module.SetOptions(mod, map[string]any{module.OptionRetryAttempts: 3, module.OptionRetryDelay: 0}); err != nil {
+		t.Fatal(err)
+	}
 	reporter := moduletest.NoopReporter()
 
 	err := mod.Apply(context.Background(), mock, reporter)
 	if err == nil {
 		t.Error("esperava erro quando instalacao falha")
 	}
+	if len(mock.ExecLog) != 3 {
+		t.Errorf("esperava 3 tentativas de instalacao, obteve %v", mock.ExecLog)
+	}
 }
 
 func TestApply_MkdirAllFails(t *testing.T) {
@@ -191,3 +197,97 @@ func TestCheck_ReadFileError(t *testing.T) {
 		t.Errorf("esperava Partial sem .bashrc, obteve %s", status.Kind)
 	}
 }
+
+func TestRevert_RemovesSymlinkAndInit(t *testing.T) {
+	mock := system.NewMock()
+	mock.Symlinks["/home/test/.config/starship.toml"] = "/repo/configs/starship.toml"
+	mock.Files["/home/test/.bashrc"] = []byte("# bashrc\neval \"$(starship init bash)\"\n")
+	mock.Files["/home/test/.zshrc"] = []byte("eval \"$(starship init zsh)\"\n# zshrc\n")
+
+	mod := New("/repo/configs/starship.toml")
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if _, ok := mock.Symlinks["/home/test/.config/starship.toml"]; ok {
+		t.Error("symlink deveria ter sido removido")
+	}
+	if got := string(mock.Files["/home/test/.bashrc"]); got != "# bashrc\n" {
+		t.Errorf(".bashrc inesperado: %q", got)
+	}
+	if got := string(mock.Files["/home/test/.zshrc"]); got != "# zshrc\n" {
+		t.Errorf(".zshrc inesperado: %q", got)
+	}
+}
+
+func TestRevert_KeepsForeignConfig(t *testing.T) {
+	mock := system.NewMock()
+	mock.Symlinks["/home/test/.config/starship.toml"] = "/outro/starship.toml"
+
+	mod := New("/repo/configs/starship.toml")
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if _, ok := mock.Symlinks["/home/test/.config/starship.toml"]; !ok {
+		t.Error("symlink de outra origem nao deveria ser removido")
+	}
+}
+
+func TestRevert_KeepsRegularFile(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/home/test/.config/starship.toml"] = []byte("config do usuario")
+
+	mod := New("/repo/configs/starship.toml")
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if _, ok := mock.Files["/home/test/.config/starship.toml"]; !ok {
+		t.Error("arquivo regular nao deveria ser removido")
+	}
+}
+
+func TestRevert_RemoveFails(t *testing.T) {
+	mock := system.NewMock()
+	mock.Symlinks["/home/test/.config/starship.toml"] = "/repo/configs/starship.toml"
+	mock.RemoveErr = fmt.Errorf("permissao negada")
+
+	mod := New("/repo/configs/starship.toml")
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Error("esperava erro quando Remove falha")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/home/test/.bashrc"] = []byte("eval \"$(starship init bash)\"\n")
+	mock.Files["/home/test/.zshrc"] = []byte("# zsh\n")
+
+	mod := New("/repo/configs/starship.toml")
+	actions, err := mod.Plan(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	var kinds []string
+	for _, a := range actions {
+		kinds = append(kinds, string(a.Kind)+" "+a.Target)
+	}
+	want := []string{
+		"run curl -sS https://starship.rs/install.sh | sh -s -- -y",
+		"symlink /home/test/.config/starship.toml",
+		"append-line /home/test/.zshrc",
+	}
+	if strings.Join(kinds, "|") != strings.Join(want, "|") {
+		t.Errorf("Plan() = %v, esperava %v", kinds, want)
+	}
+
+	// Tudo configurado: plano vazio
+	mock.Commands["starship"] = true
+	mock.Symlinks["/home/test/.config/starship.toml"] = "/repo/configs/starship.toml"
+	mock.Files["/home/test/.zshrc"] = []byte("eval \"$(starship init zsh)\"\n")
+	if actions, _ := mod.Plan(context.Background(), mock); len(actions) != 0 {
+		t.Errorf("esperava plano vazio, obteve %v", actions)
+	}
+}
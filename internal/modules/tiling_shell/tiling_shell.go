@@ -14,26 +14,79 @@ import (
 const tilingShellUUID = "tilingshell@ferrarodomenico.com"
 const forgeUUID = "forge@jmmaranan.com"
 
-var gapSettings = []gnome.DconfEntry{
-	{Path: "/org/gnome/shell/extensions/tilingshell/inner-gaps", Value: "uint32 4"},
-	{Path: "/org/gnome/shell/extensions/tilingshell/outer-gaps", Value: "uint32 4"},
-}
+const (
+	innerGapsKey = "/org/gnome/shell/extensions/tilingshell/inner-gaps"
+	outerGapsKey = "/org/gnome/shell/extensions/tilingshell/outer-gaps"
+)
+
+// defaultGap e o espacamento padrao (px) entre e ao redor das janelas.
+const defaultGap = 4
 
 // Module implementa auto-tiling com Tiling Shell.
-type Module struct{}
+// As opcoes inner_gap e outer_gap (px) e as de retry do download da
+// extensao vem do OptionSet embutido.
+type Module struct {
+	module.OptionSet
+}
 
-func New() *Module { return &Module{} }
+func New() *Module {
+	return &Module{OptionSet: module.NewOptionSet(append([]module.Option{
+		{
+			Key: "inner_gap", Type: module.OptionInt, Default: defaultGap,
+			Description: "Espacamento entre janelas (px)", Validate: nonNegative,
+		},
+		{
+			Key: "outer_gap", Type: module.OptionInt, Default: defaultGap,
+			Description: "Espacamento ate a borda da tela (px)", Validate: nonNegative,
+		},
+	}, module.RetryOptions(module.DefaultRetry)...)...)}
+}
 
 func (m *Module) Name() string        { return "tiling-shell" }
 func (m *Module) Description() string { return "Auto-tiling Tiling Shell (snap + layouts)" }
 func (m *Module) Tags() []string      { return []string{"desktop"} }
 
+// Resources declara que o modulo escreve chaves dconf.
+func (m *Module) Resources() []string { return []string{module.ResourceDconf} }
+
+// Timeouts limita o download da extensao (ver gnome.ExtensionTimeouts).
+func (m *Module) Timeouts() module.Timeouts { return gnome.ExtensionTimeouts }
+
+// nonNegative rejeita gaps negativos.
+func nonNegative(v any) error {
+	if v.(int) < 0 {
+		return fmt.Errorf("nao pode ser negativo")
+	}
+	return nil
+}
+
+// gapSettings monta as chaves dconf dos gaps configurados.
+func (m *Module) gapSettings() []gnome.DconfEntry {
+	values := m.Values()
+	return []gnome.DconfEntry{
+		{Path: innerGapsKey, Value: fmt.Sprintf("uint32 %d", values.Int("inner_gap"))},
+		{Path: outerGapsKey, Value: fmt.Sprintf("uint32 %d", values.Int("outer_gap"))},
+	}
+}
+
 func (m *Module) ShouldRun(_ context.Context, sys module.System) (bool, string) {
 	return gnome.ShouldRunGuard(sys)
 }
 
+// Check confere a extensao e, com ela ativa, os gaps gravados no dconf:
+// gaps diferentes das opcoes deixam o modulo Partial para o apply regrava-los.
 func (m *Module) Check(ctx context.Context, sys module.System) (module.Status, error) {
-	return gnome.CheckExtension(ctx, sys, tilingShellUUID, "Tiling Shell")
+	status, err := gnome.CheckExtension(ctx, sys, tilingShellUUID, "Tiling Shell")
+	if err != nil || status.Kind != module.Installed {
+		return status, err
+	}
+	if len(gnome.PlanDconf(ctx, sys, m.gapSettings())) > 0 {
+		values := m.Values()
+		return module.Status{Kind: module.Partial, Message: fmt.Sprintf(
+			"Tiling Shell ativo, mas os gaps diferem do configurado (inner=%d, outer=%d)",
+			values.Int("inner_gap"), values.Int("outer_gap"))}, nil
+	}
+	return status, nil
 }
 
 func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.Reporter) error {
@@ -65,7 +118,7 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 	out, _ = sys.Exec(ctx, "gnome-extensions", "show", tilingShellUUID)
 	if !strings.Contains(out, tilingShellUUID) {
 		reporter.Info("Baixando Tiling Shell de extensions.gnome.org...")
-		if err := gnome.InstallFromGnomeExtensions(ctx, sys, tilingShellUUID, gnomeVer, "Tiling Shell"); err != nil {
+		if err := gnome.InstallWithRetry(ctx, sys, m.Values().Retry(module.DefaultRetry), reporter, tilingShellUUID, gnomeVer, "Tiling Shell"); err != nil {
 			return fmt.Errorf("erro ao instalar Tiling Shell: %w", err)
 		}
 		reporter.Success("Tiling Shell instalado")
@@ -73,19 +126,52 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 		reporter.Info("Tiling Shell ja instalado")
 	}
 
+	relogin := "Faca logout e login se o Tiling Shell nao aparecer imediatamente"
 	if _, err := sys.Exec(ctx, "gnome-extensions", "enable", tilingShellUUID); err != nil {
-		reporter.Warn("Tiling Shell sera ativado apos re-login")
+		relogin = "Tiling Shell sera ativado apos re-login"
 	} else {
 		reporter.Success("Tiling Shell ativado")
 	}
 
 	// 4. Configurar gaps
 	reporter.Step(4, total, "Configurando gaps...")
-	if err := gnome.ApplyDconf(ctx, sys, gapSettings); err != nil {
+	if err := gnome.ApplyDconf(ctx, sys, m.gapSettings()); err != nil {
 		return fmt.Errorf("erro ao configurar gaps: %w", err)
 	}
-	reporter.Success("Gaps: inner=4, outer=4")
+	values := m.Values()
+	reporter.Success(fmt.Sprintf("Gaps: inner=%d, outer=%d", values.Int("inner_gap"), values.Int("outer_gap")))
+
+	module.RequireSession(reporter, module.SessionRelogin, relogin)
+	return nil
+}
+
+// Plan descreve a desativacao do Forge, a instalacao do Tiling Shell e os
+// gaps que diferem do configurado.
+func (m *Module) Plan(ctx context.Context, sys module.System) ([]module.Action, error) {
+	var actions []module.Action
+	if out, _ := sys.Exec(ctx, "gnome-extensions", "show", forgeUUID); strings.Contains(out, "Enabled: Yes") {
+		actions = append(actions, module.Action{Kind: module.ActionRunCommand, Target: "gnome-extensions disable " + forgeUUID})
+	}
+	actions = append(actions, gnome.PlanExtension(ctx, sys, tilingShellUUID)...)
+	actions = append(actions, gnome.PlanDconf(ctx, sys, m.gapSettings())...)
+	return actions, nil
+}
+
+// Revert desinstala o Tiling Shell e restaura os gaps padrao.
+// O Forge, se foi desabilitado no Apply, nao e reativado automaticamente.
+func (m *Module) Revert(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	reporter.Step(1, 2, "Desinstalando Tiling Shell...")
+	if err := gnome.UninstallExtension(ctx, sys, tilingShellUUID); err != nil {
+		return err
+	}
+	reporter.Success("Tiling Shell removido")
+
+	reporter.Step(2, 2, "Restaurando gaps...")
+	if err := gnome.ResetDconf(ctx, sys, m.gapSettings()); err != nil {
+		return fmt.Errorf("erro ao restaurar gaps: %w", err)
+	}
+	reporter.Success("Gaps restaurados")
 
-	reporter.Info("Faca logout e login se o Tiling Shell nao aparecer imediatamente")
+	module.RequireSession(reporter, module.SessionRelogin, "Faca logout e login para concluir a remocao")
 	return nil
 }
@@ -66,6 +66,8 @@ func TestCheck_Installed(t *testing.T) {
 	mock.ExecResults["gnome-extensions show tilingshell@ferrarodomenico.com"] = system.ExecResult{
 		Output: "tilingshell@ferrarodomenico.com\n  Name: Tiling Shell\n  Enabled: Yes\n  State: ACTIVE\n",
 	}
+	mock.ExecResults["dconf read "+innerGapsKey] = system.ExecResult{Output: "uint32 4"}
+	mock.ExecResults["dconf read "+outerGapsKey] = system.ExecResult{Output: "uint32 4"}
 
 	mod := New()
 	status, err := mod.Check(context.Background(), mock)
@@ -77,6 +79,27 @@ func TestCheck_Installed(t *testing.T) {
 	}
 }
 
+func TestCheck_GapsDiffer(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["gnome-extensions show tilingshell@ferrarodomenico.com"] = system.ExecResult{
+		Output: "tilingshell@ferrarodomenico.com\n  Name: Tiling Shell\n  Enabled: Yes\n  State: ACTIVE\n",
+	}
+	mock.ExecResults["dconf read "+innerGapsKey] = system.ExecResult{Output: "uint32 4"}
+	mock.ExecResults["dconf read "+outerGapsKey] = system.ExecResult{Output: "uint32 4"}
+
+	mod := New()
+	if err := module.SetOptions(mod, map[string]any{"inner_gap": int64(8)}); err != nil {
+		t.Fatal(err)
+	}
+	status, err := mod.Check(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if status.Kind != module.Partial || !strings.Contains(status.Message, "inner=8") {
+		t.Errorf("esperava Partial pelos gaps, obteve %s: %s", status.Kind, status.Message)
+	}
+}
+
 func TestShouldRun_SkipWithoutGnomeExtensions(t *testing.T) {
 	mock := system.NewMock()
 	mock.EnvVars["WAYLAND_DISPLAY"] = "wayland-0"
@@ -216,6 +239,9 @@ func TestApply_InstallFails(t *testing.T) {
 	}
 
 	mod := New()
+	if err := module.SetOptions(mod, map[string]any{module.OptionRetryAttempts: 1, module.OptionRetryDelay: 0}); err != nil {
+		t.Fatal(err)
+	}
 	reporter := moduletest.NoopReporter()
 
 	err := mod.Apply(context.Background(), mock, reporter)
@@ -251,3 +277,71 @@ func TestApply_DconfFails(t *testing.T) {
 		t.Error("esperava erro quando dconf write falha")
 	}
 }
+
+func TestRevert_UninstallsAndResetsGaps(t *testing.T) {
+	mock := system.NewMock()
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	var uninstalled, reset bool
+	for _, cmd := range mock.ExecLog {
+		if cmd == "gnome-extensions uninstall "+tilingShellUUID {
+			uninstalled = true
+		}
+		if strings.HasPrefix(cmd, "dconf reset") && strings.Contains(cmd, "tilingshell") {
+			reset = true
+		}
+	}
+	if !uninstalled {
+		t.Error("esperava desinstalar o Tiling Shell")
+	}
+	if !reset {
+		t.Error("esperava restaurar os gaps via dconf reset")
+	}
+}
+
+func TestConfigure_Gaps(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["gnome-shell --version"] = system.ExecResult{Output: "GNOME Shell 47.2"}
+	mock.ExecResults["gnome-extensions show tilingshell@ferrarodomenico.com"] = system.ExecResult{
+		Output: "tilingshell@ferrarodomenico.com\n  Name: Tiling Shell\n",
+	}
+
+	mod := New()
+	if err := module.SetOptions(mod, map[string]any{"inner_gap": int64(8)}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if got := mod.OptionValues(); got["inner_gap"] != 8 || got["outer_gap"] != 4 {
+		t.Errorf("OptionValues() = %v", got)
+	}
+
+	if err := mod.Apply(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	want := "dconf write /org/gnome/shell/extensions/tilingshell/inner-gaps uint32 8"
+	found := false
+	for _, cmd := range mock.ExecLog {
+		if cmd == want {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("esperava %q em %v", want, mock.ExecLog)
+	}
+}
+
+func TestConfigure_Invalid(t *testing.T) {
+	tests := []map[string]any{
+		{"gap": int64(8)},
+		{"inner_gap": "oito"},
+		{"outer_gap": int64(-1)},
+	}
+	for _, opts := range tests {
+		if err := module.SetOptions(New(), opts); err == nil {
+			t.Errorf("SetOptions(%v): esperava erro", opts)
+		}
+	}
+}
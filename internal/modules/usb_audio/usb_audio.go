@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/sysfile"
 )
 
 const (
@@ -30,6 +31,9 @@ func (m *Module) Name() string        { return "usb-audio" }
 func (m *Module) Description() string { return "Regras udev para desabilitar autosuspend em audio USB" }
 func (m *Module) Tags() []string      { return []string{"system"} }
 
+// Resources declara que o modulo executa comandos como root.
+func (m *Module) Resources() []string { return []string{module.ResourceSudo} }
+
 // ShouldRun retorna false dentro de containers.
 func (m *Module) ShouldRun(_ context.Context, sys module.System) (bool, string) {
 	if sys.IsContainer() {
@@ -54,33 +58,33 @@ func (m *Module) Check(_ context.Context, sys module.System) (module.Status, err
 
 // Apply instala as regras udev e recarrega o udevadm.
 func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.Reporter) error {
-	cacheDir := sys.HomeDir() + "/.cache"
-	tmpFile := cacheDir + "/blueprint-usb-audio.rules"
-
-	// Step 1 — Escreve regras no arquivo temporario
-	reporter.Step(1, 3, "Escrevendo regras udev...")
-
-	if err := sys.WriteFile(tmpFile, []byte(rulesContent), 0o644); err != nil {
-		return fmt.Errorf("erro ao escrever arquivo temporario: %w", err)
+	// Step 1 — Instala as regras em /etc (validadas com udevadm verify)
+	reporter.Step(1, 2, "Instalando regras udev...")
+
+	changed, err := sysfile.Install(ctx, sys, sysfile.File{
+		Path:     rulesPath,
+		Content:  []byte(rulesContent),
+		Mode:     0o644,
+		Validate: sysfile.UdevVerify,
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao instalar regras udev: %w", err)
 	}
-
-	// Step 2 — Copia para /etc com sudo
-	reporter.Step(2, 3, "Instalando regras udev...")
-
-	if _, err := sys.Exec(ctx, "sudo", "cp", tmpFile, rulesPath); err != nil {
-		return fmt.Errorf("erro ao copiar regras udev: %w", err)
+	if !changed {
+		reporter.Success("Regras udev ja estavam instaladas (sem alteracoes)")
+		return nil
 	}
 
 	reporter.Success("Regras udev instaladas")
 
-	// Step 3 — Recarrega regras
-	reporter.Step(3, 3, "Recarregando udev...")
+	// Step 2 — Recarrega regras
+	reporter.Step(2, 2, "Recarregando udev...")
 
-	if _, err := sys.Exec(ctx, "sudo", "udevadm", "control", "--reload-rules"); err != nil {
+	if _, err := sys.ExecPrivileged(ctx, "udevadm", "control", "--reload-rules"); err != nil {
 		return fmt.Errorf("erro ao recarregar regras udev: %w", err)
 	}
 
-	if _, err := sys.Exec(ctx, "sudo", "udevadm", "trigger", "--subsystem-match=usb"); err != nil {
+	if _, err := sys.ExecPrivileged(ctx, "udevadm", "trigger", "--subsystem-match=usb"); err != nil {
 		return fmt.Errorf("erro ao aplicar regras udev: %w", err)
 	}
 
@@ -88,3 +92,24 @@ func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.R
 
 	return nil
 }
+
+// Revert remove as regras udev e recarrega o udevadm.
+func (m *Module) Revert(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	reporter.Step(1, 2, "Removendo regras udev...")
+
+	if _, err := sys.ExecPrivileged(ctx, "rm", "-f", rulesPath); err != nil {
+		return fmt.Errorf("erro ao remover regras udev: %w", err)
+	}
+
+	reporter.Success("Regras udev removidas")
+
+	reporter.Step(2, 2, "Recarregando udev...")
+
+	if _, err := sys.ExecPrivileged(ctx, "udevadm", "control", "--reload-rules"); err != nil {
+		return fmt.Errorf("erro ao recarregar regras udev: %w", err)
+	}
+
+	reporter.Success("Regras udev recarregadas")
+
+	return nil
+}
@@ -3,6 +3,7 @@ package usb_audio
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/ale/blueprint/internal/module"
@@ -76,6 +77,8 @@ func TestCheck_Partial(t *testing.T) {
 	}
 }
 
+const stagedPath = "/etc/udev/rules.d/.blueprint-99-usb-audio-no-autosuspend.rules"
+
 func TestApply_InstallsRules(t *testing.T) {
 	mock := system.NewMock()
 
@@ -87,53 +90,63 @@ func TestApply_InstallsRules(t *testing.T) {
 		t.Fatalf("erro inesperado: %v", err)
 	}
 
-	// Verifica arquivo temporario
-	tmpFile := "/home/test/.cache/blueprint-usb-audio.rules"
-	data, ok := mock.Files[tmpFile]
-	if !ok {
-		t.Fatal("arquivo temporario nao criado")
-	}
-	if string(data) != rulesContent {
-		t.Errorf("conteudo inesperado: %q", string(data))
+	// Preparado ao lado do destino, validado e movido no lugar
+	if string(mock.Files[stagedPath]) != rulesContent {
+		t.Errorf("conteudo inesperado: %q", string(mock.Files[stagedPath]))
 	}
-
-	// Verifica comandos executados
-	expectedCmds := []string{
-		"sudo cp " + tmpFile + " " + rulesPath,
+	expected := []string{
+		"sudo install -m 0644 -o root -g root " + stagedPath,
+		"udevadm verify --help",
+		"sudo udevadm verify " + stagedPath,
+		"sudo mv -f " + stagedPath + " " + rulesPath,
 		"sudo udevadm control --reload-rules",
 		"sudo udevadm trigger --subsystem-match=usb",
 	}
-	for _, cmd := range expectedCmds {
-		found := false
-		for _, logged := range mock.ExecLog {
-			if logged == cmd {
-				found = true
-				break
-			}
-		}
-		if !found {
-			t.Errorf("comando esperado nao executado: %s", cmd)
-		}
+	if strings.Join(mock.ExecLog, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("comandos: esperava %v, obteve %v", expected, mock.ExecLog)
 	}
 }
 
-func TestApply_WriteFileFails(t *testing.T) {
+func TestApply_Unchanged(t *testing.T) {
 	mock := system.NewMock()
-	mock.WriteFileErr = fmt.Errorf("disco cheio")
+	mock.Files[rulesPath] = []byte(rulesContent)
+	mock.ExecResults["sudo stat -c %a %U:%G "+rulesPath] = system.ExecResult{Output: "644 root:root"}
+
+	if err := New().Apply(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(mock.ExecLog) != 1 {
+		t.Errorf("regras iguais nao deveriam ser reinstaladas nem recarregadas: %v", mock.ExecLog)
+	}
+}
+
+func TestApply_VerifyFails(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["sudo udevadm verify "+stagedPath] = system.ExecResult{
+		Output: "invalid key/value pair",
+		Err:    fmt.Errorf("exit status 1"),
+	}
 
 	mod := New()
 	reporter := moduletest.NoopReporter()
 
 	err := mod.Apply(context.Background(), mock, reporter)
-	if err == nil {
-		t.Error("esperava erro quando WriteFile falha")
+	if err == nil || !strings.Contains(err.Error(), "invalid key/value pair") {
+		t.Fatalf("esperava erro de validacao, obteve %v", err)
+	}
+	for _, logged := range mock.ExecLog {
+		if strings.HasPrefix(logged, "sudo mv") || strings.Contains(logged, "reload-rules") {
+			t.Errorf("regras invalidas nao deveriam ser instaladas: %v", mock.ExecLog)
+		}
+	}
+	if last := mock.ExecLog[len(mock.ExecLog)-1]; last != "sudo rm -f "+stagedPath {
+		t.Errorf("arquivo preparado deveria ser apagado, ultimo comando: %s", last)
 	}
 }
 
-func TestApply_CopyFails(t *testing.T) {
+func TestApply_InstallFails(t *testing.T) {
 	mock := system.NewMock()
-	tmpFile := "/home/test/.cache/blueprint-usb-audio.rules"
-	mock.ExecResults["sudo cp "+tmpFile+" "+rulesPath] = system.ExecResult{
+	mock.ExecResults["sudo install -m 0644 -o root -g root "+stagedPath] = system.ExecResult{
 		Err: fmt.Errorf("permissao negada"),
 	}
 
@@ -142,7 +155,7 @@ func TestApply_CopyFails(t *testing.T) {
 
 	err := mod.Apply(context.Background(), mock, reporter)
 	if err == nil {
-		t.Error("esperava erro quando sudo cp falha")
+		t.Error("esperava erro quando a instalacao das regras falha")
 	}
 }
 
@@ -175,3 +188,32 @@ func TestApply_TriggerFails(t *testing.T) {
 		t.Error("esperava erro quando udevadm trigger falha")
 	}
 }
+
+func TestRevert_RemovesRules(t *testing.T) {
+	mock := system.NewMock()
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	expected := []string{
+		"sudo rm -f " + rulesPath,
+		"sudo udevadm control --reload-rules",
+	}
+	for i, cmd := range expected {
+		if i >= len(mock.ExecLog) || mock.ExecLog[i] != cmd {
+			t.Errorf("comando %d: esperava %q, exec log: %v", i, cmd, mock.ExecLog)
+		}
+	}
+}
+
+func TestRevert_RemoveFails(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["sudo rm -f "+rulesPath] = system.ExecResult{Err: fmt.Errorf("permissao negada")}
+
+	mod := New()
+	if err := mod.Revert(context.Background(), mock, moduletest.NoopReporter()); err == nil {
+		t.Error("esperava erro quando rm falha")
+	}
+}
@@ -0,0 +1,140 @@
+package orchestrator
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
+)
+
+// EventKind identifica o tipo de evento emitido durante a execucao.
+type EventKind int
+
+const (
+	ModuleStarted  EventKind = iota // Modulo comecou a ser processado
+	StatusChecked                   // Check retornou o estado atual (Event.Status)
+	LogLine                         // Mensagem do orchestrator ou do modulo (Event.Level/Text)
+	ModuleFinished                  // Modulo terminou (Event.Result)
+)
+
+// LogLevel espelha os metodos de module.Reporter.
+type LogLevel int
+
+const (
+	LogInfo LogLevel = iota
+	LogSuccess
+	LogWarn
+	LogError
+	LogStep
+)
+
+// Event descreve algo que aconteceu com um modulo durante Run ou Remove.
+// Apenas os campos relevantes para o Kind sao preenchidos.
+type Event struct {
+	Kind   EventKind
+	Module module.Module
+
+	Status module.Status // StatusChecked
+
+	Level   LogLevel // LogLine
+	Text    string   // LogLine
+	Current int      // LogLine com Level == LogStep
+	Total   int      // LogLine com Level == LogStep
+
+	Result Result // ModuleFinished
+}
+
+// Observer recebe os eventos da execucao. As chamadas sao serializadas
+// pelo orchestrator (nunca concorrentes), mesmo na execucao paralela.
+type Observer func(Event)
+
+// Observe registra um observer para os eventos de Run e Remove.
+func (o *Orchestrator) Observe(obs Observer) {
+	o.observers = append(o.observers, obs)
+}
+
+// emit registra o evento no log e o entrega a todos os observers.
+func (o *Orchestrator) emit(ev Event) {
+	o.logEvent(ev)
+	if len(o.observers) == 0 {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, obs := range o.observers {
+		obs(ev)
+	}
+}
+
+// logEvent registra um evento no logger do orchestrator (ver SetLogger).
+func (o *Orchestrator) logEvent(ev Event) {
+	name := ev.Module.Name()
+	switch ev.Kind {
+	case ModuleStarted:
+		o.log.Info("modulo iniciado", "modulo", name)
+	case StatusChecked:
+		o.log.Info("status verificado", "modulo", name, "status", ev.Status.Kind.String(), "mensagem", ev.Status.Message)
+	case LogLine:
+		level := slog.LevelInfo
+		switch ev.Level {
+		case LogWarn:
+			level = slog.LevelWarn
+		case LogError:
+			level = slog.LevelError
+		case LogStep:
+			level = slog.LevelDebug
+		}
+		o.log.Log(context.Background(), level, ev.Text, "modulo", name)
+	case ModuleFinished:
+		r := ev.Result
+		attrs := []any{"modulo", name, "duracao", r.Duration.Round(time.Millisecond)}
+		if len(r.Retries) > 0 {
+			attrs = append(attrs, "retentativas", len(r.Retries))
+		}
+		switch {
+		case r.Err != nil:
+			o.log.Error("modulo falhou", append(attrs, "erro", r.Err.Error())...)
+		case r.Cancelled:
+			o.log.Warn("modulo cancelado", append(attrs, "motivo", r.Reason)...)
+		case r.Skipped:
+			o.log.Info("modulo pulado", append(attrs, "motivo", r.Reason)...)
+		default:
+			if r.After != nil {
+				attrs = append(attrs, "status", r.After.Kind.String())
+			}
+			o.log.Info("modulo finalizado", append(attrs, "aplicado", r.Applied, "removido", r.Reverted)...)
+		}
+	}
+}
+
+// eventReporter repassa mensagens ao reporter e as emite como LogLine do modulo.
+type eventReporter struct {
+	o     *Orchestrator
+	m     module.Module
+	inner module.Reporter
+}
+
+func (r *eventReporter) log(level LogLevel, msg string) {
+	r.o.emit(Event{Kind: LogLine, Module: r.m, Level: level, Text: msg})
+}
+
+func (r *eventReporter) Info(msg string)    { r.inner.Info(msg); r.log(LogInfo, msg) }
+func (r *eventReporter) Success(msg string) { r.inner.Success(msg); r.log(LogSuccess, msg) }
+func (r *eventReporter) Warn(msg string)    { r.inner.Warn(msg); r.log(LogWarn, msg) }
+func (r *eventReporter) Error(msg string)   { r.inner.Error(msg); r.log(LogError, msg) }
+
+func (r *eventReporter) Step(current, total int, msg string) {
+	r.inner.Step(current, total, msg)
+	r.o.emit(Event{Kind: LogLine, Module: r.m, Level: LogStep, Text: msg, Current: current, Total: total})
+}
+
+// discardReporter descarta mensagens; usado quando o orchestrator e criado
+// sem reporter e a saida e consumida apenas via Observer (ex: TUI).
+type discardReporter struct{}
+
+func (discardReporter) Info(string)           {}
+func (discardReporter) Success(string)        {}
+func (discardReporter) Warn(string)           {}
+func (discardReporter) Error(string)          {}
+func (discardReporter) Step(int, int, string) {}
@@ -0,0 +1,158 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/system"
+)
+
+// recordEvents registra os eventos como "kind:modulo" para comparacao.
+func recordEvents(orch *Orchestrator) *[]string {
+	var events []string
+	orch.Observe(func(ev Event) {
+		switch ev.Kind {
+		case ModuleStarted:
+			events = append(events, "started:"+ev.Module.Name())
+		case StatusChecked:
+			events = append(events, fmt.Sprintf("status:%s:%s", ev.Module.Name(), ev.Status.Kind))
+		case ModuleFinished:
+			events = append(events, "finished:"+ev.Module.Name())
+		}
+	})
+	return &events
+}
+
+func TestObserve_RunEvents(t *testing.T) {
+	orch := New(system.NewMock(), nil)
+	events := recordEvents(orch)
+
+	installed := &fakeModule{name: "a", checkStatus: module.Status{Kind: module.Installed}}
+	missing := &fakeModule{name: "b", checkStatus: module.Status{Kind: module.Missing}}
+
+	orch.Run(context.Background(), []module.Module{installed, missing})
+
+	want := []string{
+		"started:a", "status:a:instalado", "finished:a",
+		"started:b", "status:b:ausente", "finished:b",
+	}
+	if !reflect.DeepEqual(*events, want) {
+		t.Errorf("eventos = %v, esperava %v", *events, want)
+	}
+}
+
+func TestObserve_LogLinesMirrorReporter(t *testing.T) {
+	reporter := &testReporter{}
+	orch := New(system.NewMock(), reporter)
+
+	var logs []string
+	orch.Observe(func(ev Event) {
+		if ev.Kind == LogLine {
+			logs = append(logs, ev.Module.Name()+": "+ev.Text)
+		}
+	})
+
+	mod := &fakeModule{name: "a", checkStatus: module.Status{Kind: module.Installed}}
+	orch.Run(context.Background(), []module.Module{mod})
+
+	if len(logs) != len(reporter.messages) {
+		t.Fatalf("esperava %d linhas de log, obteve %d: %v", len(reporter.messages), len(logs), logs)
+	}
+	if !strings.Contains(logs[len(logs)-1], "ja instalado") {
+		t.Errorf("ultima linha = %q, esperava 'ja instalado'", logs[len(logs)-1])
+	}
+}
+
+func TestObserve_FinishedCarriesResult(t *testing.T) {
+	orch := New(system.NewMock(), nil)
+
+	var finished []Result
+	orch.Observe(func(ev Event) {
+		if ev.Kind == ModuleFinished {
+			finished = append(finished, ev.Result)
+		}
+	})
+
+	failing := &fakeModule{name: "a", checkStatus: module.Status{Kind: module.Missing}, applyErr: fmt.Errorf("falhou")}
+	dependent := &fakeDependentModule{
+		fakeModule: fakeModule{name: "b", checkStatus: module.Status{Kind: module.Missing}},
+		requires:   []string{"a"},
+	}
+
+	orch.Run(context.Background(), []module.Module{failing, dependent})
+
+	if len(finished) != 2 {
+		t.Fatalf("esperava 2 eventos finished, obteve %d", len(finished))
+	}
+	if finished[0].Err == nil {
+		t.Error("esperava erro no resultado de a")
+	}
+	if !finished[1].Skipped {
+		t.Error("esperava b pulado por dependencia")
+	}
+}
+
+func TestObserve_RemoveEvents(t *testing.T) {
+	orch := New(system.NewMock(), nil)
+	events := recordEvents(orch)
+
+	mod := &fakeRevertibleModule{fakeModule: fakeModule{name: "a", checkStatus: module.Status{Kind: module.Installed}}}
+	orch.Remove(context.Background(), []module.Module{mod})
+
+	want := []string{"started:a", "status:a:instalado", "finished:a"}
+	if !reflect.DeepEqual(*events, want) {
+		t.Errorf("eventos = %v, esperava %v", *events, want)
+	}
+}
+
+func TestObserve_ParallelEventsPerModule(t *testing.T) {
+	orch, _ := newParallel(3)
+
+	counts := make(map[string]int)
+	orch.Observe(func(ev Event) {
+		if ev.Kind == ModuleFinished {
+			counts[ev.Module.Name()]++
+		}
+	})
+
+	var modules []module.Module
+	for _, name := range []string{"a", "b", "c"} {
+		modules = append(modules, &concurrentModule{name: name, apply: func(module.Reporter) error { return nil }})
+	}
+	orch.Run(context.Background(), modules)
+
+	for _, name := range []string{"a", "b", "c"} {
+		if counts[name] != 1 {
+			t.Errorf("%s: esperava 1 evento finished, obteve %d", name, counts[name])
+		}
+	}
+}
+
+func TestSetLogger_RecordsModuleEvents(t *testing.T) {
+	var buf strings.Builder
+	orch := New(system.NewMock(), nil)
+	orch.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	ok := &fakeModule{name: "a", checkStatus: module.Status{Kind: module.Missing}}
+	failing := &fakeModule{name: "b", checkStatus: module.Status{Kind: module.Missing}, applyErr: fmt.Errorf("erro simulado")}
+	orch.Run(context.Background(), []module.Module{ok, failing})
+
+	got := buf.String()
+	for _, want := range []string{
+		`msg="modulo iniciado" modulo=a`,
+		`msg="status verificado" modulo=a status=ausente`,
+		`msg="a: aplicando..." modulo=a`,
+		`msg="modulo finalizado" modulo=a`,
+		`level=ERROR msg="modulo falhou" modulo=b`,
+		`erro="erro simulado"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log sem %q:\n%s", want, got)
+		}
+	}
+}
@@ -1,28 +1,54 @@
-// Package orchestrator executa modulos em sequencia: Guard -> Check -> Apply.
+// Package orchestrator executa modulos em sequencia: Guard -> Check -> Apply
+// (ou Guard -> Check -> Revert na remocao).
 package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/ale/blueprint/internal/module"
 )
 
 // Result armazena o resultado da execucao de um modulo.
 type Result struct {
-	Module  module.Module
-	Status  module.Status
-	Applied bool
-	Skipped bool
-	Reason  string
-	Err     error
-	Notes   []string // Instrucoes pos-apply exibidas no sumario final
+	Module    module.Module
+	Status    module.Status  // Estado encontrado pelo Check antes de aplicar/remover
+	After     *module.Status // Estado re-verificado apos Apply/Revert (nil se nao verificado)
+	Applied   bool
+	Reverted  bool // Modulo removido via Reverter (blueprint remove)
+	Skipped   bool
+	Deferred  bool // Pulado ate o proximo boot: uma dependencia pediu reboot (ver DeferredBy)
+	Cancelled bool // Execucao cancelada (Ctrl+C, SIGTERM) antes ou durante o modulo (ver Reason)
+	TimedOut  bool // Err e um *module.TimeoutError: etapa ou comando excedeu o limite de tempo
+	Unchecked bool // Check falhou (Err): Status nao reflete o sistema
+	Reason    string
+	Err       error
+	Notes     []string                // Instrucoes pos-apply exibidas no sumario final
+	Retries   []module.Attempt        // Tentativas que falharam e foram repetidas (module.Retry)
+	Session   []module.SessionRequest // Acoes de sessao pedidas pelo modulo (module.RequireSession)
+	Duration  time.Duration           // Tempo total do modulo (guard, check e apply)
 }
 
-// notingReporter captura mensagens Info como Notes alem de repassar ao reporter original.
+// notingReporter captura mensagens Info como Notes, as tentativas repetidas
+// (module.RetryRecorder) e as acoes de sessao (module.SessionRecorder) alem
+// de repassar ao reporter original.
 type notingReporter struct {
-	inner module.Reporter
-	notes []string
+	inner   module.Reporter
+	notes   []string
+	retries []module.Attempt
+	session []module.SessionRequest
+}
+
+func (r *notingReporter) RequireSession(req module.SessionRequest) {
+	r.session = append(r.session, req)
+}
+
+func (r *notingReporter) Retried(a module.Attempt) {
+	r.retries = append(r.retries, a)
 }
 
 func (r *notingReporter) Info(msg string) {
@@ -30,42 +56,304 @@ func (r *notingReporter) Info(msg string) {
 	r.inner.Info(msg)
 }
 
-func (r *notingReporter) Success(msg string)                   { r.inner.Success(msg) }
-func (r *notingReporter) Warn(msg string)                      { r.inner.Warn(msg) }
-func (r *notingReporter) Error(msg string)                     { r.inner.Error(msg) }
-func (r *notingReporter) Step(current, total int, msg string)  { r.inner.Step(current, total, msg) }
+func (r *notingReporter) Success(msg string)                  { r.inner.Success(msg) }
+func (r *notingReporter) Warn(msg string)                     { r.inner.Warn(msg) }
+func (r *notingReporter) Error(msg string)                    { r.inner.Error(msg) }
+func (r *notingReporter) Step(current, total int, msg string) { r.inner.Step(current, total, msg) }
 
 // Orchestrator coordena a execucao de modulos.
 type Orchestrator struct {
-	sys      module.System
-	reporter module.Reporter
+	sys        module.System
+	reporter   module.Reporter
+	deselected map[string]bool
+	jobs       int
+	timeout    time.Duration // limite padrao de Check e Apply/Revert (0: sem limite)
+	observers  []Observer
+	log        *slog.Logger
+	mu         sync.Mutex // serializa chamadas aos observers
 }
 
-// New cria um Orchestrator.
+// New cria um Orchestrator que executa um modulo por vez.
+// reporter pode ser nil quando a saida e consumida apenas via Observe.
 func New(sys module.System, reporter module.Reporter) *Orchestrator {
-	return &Orchestrator{sys: sys, reporter: reporter}
+	if reporter == nil {
+		reporter = discardReporter{}
+	}
+	return &Orchestrator{
+		sys:        sys,
+		reporter:   reporter,
+		deselected: make(map[string]bool),
+		jobs:       1,
+		log:        slog.New(slog.DiscardHandler),
+	}
+}
+
+// SetLogger registra os eventos de cada modulo (inicio, status, mensagens,
+// resultado e duracao) no logger.
+func (o *Orchestrator) SetLogger(l *slog.Logger) {
+	o.log = l
+}
+
+// SetJobs define quantos modulos Run pode executar simultaneamente.
+// Valores menores que 1 sao tratados como 1 (execucao sequencial).
+func (o *Orchestrator) SetJobs(n int) {
+	if n < 1 {
+		n = 1
+	}
+	o.jobs = n
+}
+
+// SetTimeout define o limite de tempo padrao do Check e do Apply/Revert.
+// Modulos que implementam module.Timeouter podem declarar limites proprios.
+// Zero desliga o limite padrao.
+func (o *Orchestrator) SetTimeout(d time.Duration) {
+	o.timeout = d
+}
+
+// Deselect marca modulos que o usuario desmarcou explicitamente (ex: no TUI).
+// Modulos que dependem deles sao pulados em Run.
+func (o *Orchestrator) Deselect(names ...string) {
+	for _, n := range names {
+		o.deselected[n] = true
+	}
 }
 
-// Run executa uma lista de modulos em sequencia.
+// Run executa uma lista de modulos respeitando dependencias.
 // Para cada modulo: Guard -> Check -> Apply (se necessario).
+// Modulos cuja dependencia falhou, foi pulada ou desmarcada sao pulados.
+// Se ctx for cancelado, o modulo em execucao e interrompido e os seguintes
+// nao iniciam; todos retornam com Cancelled.
+// Com SetJobs(n > 1), modulos independentes rodam em paralelo (ver runParallel).
 func (o *Orchestrator) Run(ctx context.Context, modules []module.Module) []Result {
+	modules = module.SortByDependencies(modules)
+	if o.jobs > 1 {
+		return o.runParallel(ctx, modules)
+	}
+
 	var results []Result
 	total := len(modules)
+	done := make(map[string]Result, total)
 
 	for i, m := range modules {
-		o.reporter.Step(i+1, total, fmt.Sprintf("Processando %s...", m.Name()))
-		result := o.runOne(ctx, m)
+		reporter := &eventReporter{o: o, m: m, inner: o.reporter}
+		start := o.start(m)
+		reporter.Step(i+1, total, fmt.Sprintf("Processando %s...", m.Name()))
+
+		var result Result
+		if ctx.Err() != nil {
+			result = o.notStarted(m, reporter)
+		} else if reason, blocked := BlockedBy(m, done, o.deselected); blocked {
+			reporter.Warn(fmt.Sprintf("%s: pulado — %s", m.Name(), reason))
+			result = skippedResult(m, reason)
+		} else if reason, deferred := DeferredBy(m, done); deferred {
+			result = o.deferred(m, reason, reporter)
+		} else {
+			result = o.runOne(ctx, m, reporter, reporter)
+		}
+
+		result = o.finish(start, result)
+		done[m.Name()] = result
 		results = append(results, result)
 	}
 
 	return results
 }
 
-// CheckAll verifica o status de todos os modulos sem aplicar.
+// BlockedBy verifica se alguma dependencia de m impede sua execucao.
+// done contem os resultados ja obtidos na execucao atual; deselected, os
+// modulos desmarcados pelo usuario. Dependencias que nao fazem parte da
+// execucao (ex: excluidas pelo perfil) nao bloqueiam.
+func BlockedBy(m module.Module, done map[string]Result, deselected map[string]bool) (string, bool) {
+	for _, dep := range module.Requirements(m) {
+		if deselected[dep] {
+			return fmt.Sprintf("depende de %s, que foi desmarcado", dep), true
+		}
+
+		r, ok := done[dep]
+		if !ok {
+			continue
+		}
+		switch {
+		case r.Err != nil:
+			return fmt.Sprintf("depende de %s, que falhou", dep), true
+		case r.Skipped && !r.Deferred:
+			return fmt.Sprintf("depende de %s, que foi pulado", dep), true
+		}
+	}
+	return "", false
+}
+
+// DeferredBy verifica se m deve esperar o proximo boot: alguma dependencia
+// foi aplicada pedindo reboot (module.SessionReboot) ou tambem foi adiada.
+// O modulo so funcionaria depois do reboot (ex: devbox usa o podman-docker
+// instalado pelo devcontainers).
+func DeferredBy(m module.Module, done map[string]Result) (string, bool) {
+	for _, dep := range module.Requirements(m) {
+		r, ok := done[dep]
+		if !ok {
+			continue
+		}
+		switch {
+		case r.Deferred:
+			return fmt.Sprintf("depende de %s, que foi adiado para depois do reboot", dep), true
+		case r.RequiresReboot():
+			return fmt.Sprintf("aguarda o reboot pedido por %s", dep), true
+		}
+	}
+	return "", false
+}
+
+// RequiresReboot indica se o modulo foi aplicado e pediu reboot.
+func (r Result) RequiresReboot() bool {
+	if r.Err != nil {
+		return false
+	}
+	for _, req := range r.Session {
+		if req.Action == module.SessionReboot {
+			return true
+		}
+	}
+	return false
+}
+
+// start emite ModuleStarted e retorna o instante de inicio do modulo.
+func (o *Orchestrator) start(m module.Module) time.Time {
+	o.emit(Event{Kind: ModuleStarted, Module: m})
+	return time.Now()
+}
+
+// finish registra a duracao do modulo e emite ModuleFinished.
+func (o *Orchestrator) finish(start time.Time, result Result) Result {
+	result.Duration = time.Since(start)
+	result.TimedOut = isTimeout(result.Err)
+	o.emit(Event{Kind: ModuleFinished, Module: result.Module, Result: result})
+	return result
+}
+
+// recheck roda o Check de novo apos Apply/Revert para registrar o estado final.
+// Retorna nil se o modulo nao tem Checker ou se a verificacao falhar.
+func (o *Orchestrator) recheck(ctx context.Context, m module.Module) *module.Status {
+	checker, ok := m.(module.Checker)
+	if !ok {
+		return nil
+	}
+	status, err := o.check(ctx, m, checker)
+	if err != nil {
+		return nil
+	}
+	return &status
+}
+
+// limit retorna o limite de tempo de uma etapa de m: o declarado pelo
+// modulo (module.Timeouter) ou o padrao de SetTimeout.
+func (o *Orchestrator) limit(m module.Module, apply bool) time.Duration {
+	if t, ok := m.(module.Timeouter); ok {
+		timeouts := t.Timeouts()
+		d := timeouts.Check
+		if apply {
+			d = timeouts.Apply
+		}
+		if d > 0 {
+			return d
+		}
+	}
+	return o.timeout
+}
+
+// withLimit executa fn com um limite de tempo. Se o limite estourar (e ctx
+// nao tiver sido cancelado), o erro e um *module.TimeoutError, mesmo que fn
+// tenha ignorado o erro do comando morto.
+func withLimit(ctx context.Context, limit time.Duration, op string, fn func(context.Context) error) error {
+	if limit <= 0 {
+		return fn(ctx)
+	}
+	lctx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+	err := fn(lctx)
+	if ctx.Err() == nil && errors.Is(lctx.Err(), context.DeadlineExceeded) {
+		return &module.TimeoutError{Op: op, After: limit}
+	}
+	return err
+}
+
+// check roda o Check de m respeitando o limite de tempo.
+func (o *Orchestrator) check(ctx context.Context, m module.Module, checker module.Checker) (module.Status, error) {
+	var status module.Status
+	err := withLimit(ctx, o.limit(m, false), "check", func(ctx context.Context) error {
+		var err error
+		status, err = checker.Check(ctx, o.sys)
+		return err
+	})
+	return status, err
+}
+
+// isTimeout verifica se err (ou algum erro embrulhado) e um *module.TimeoutError.
+func isTimeout(err error) bool {
+	var timeoutErr *module.TimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+// skippedResult monta o Result de um modulo pulado.
+func skippedResult(m module.Module, reason string) Result {
+	return Result{
+		Module:  m,
+		Skipped: true,
+		Reason:  reason,
+		Status:  module.Status{Kind: module.Skipped, Message: reason},
+	}
+}
+
+// deferred monta o Result de um modulo adiado para depois do reboot.
+func (o *Orchestrator) deferred(m module.Module, reason string, reporter module.Reporter) Result {
+	reporter.Warn(fmt.Sprintf("%s: adiado — %s", m.Name(), reason))
+	result := skippedResult(m, reason)
+	result.Deferred = true
+	return result
+}
+
+// Motivos de Result.Cancelled.
+const (
+	ReasonNotStarted  = "cancelado antes de iniciar"
+	ReasonInterrupted = "interrompido durante a execucao"
+)
+
+// notStarted monta o Result de um modulo que nao chegou a iniciar porque
+// a execucao foi cancelada.
+func (o *Orchestrator) notStarted(m module.Module, reporter module.Reporter) Result {
+	reporter.Warn(fmt.Sprintf("%s: %s", m.Name(), ReasonNotStarted))
+	return cancelledResult(m, ReasonNotStarted)
+}
+
+// cancelledResult monta o Result de um modulo cancelado sem estado conhecido.
+func cancelledResult(m module.Module, reason string) Result {
+	return Result{
+		Module:    m,
+		Cancelled: true,
+		Reason:    reason,
+		Status:    module.Status{Kind: module.Skipped, Message: reason},
+	}
+}
+
+// interrupted marca result como interrompido: o erro do Check/Apply/Revert
+// e consequencia do cancelamento (ex: processo morto), nao uma falha.
+func (o *Orchestrator) interrupted(result Result, reporter module.Reporter) Result {
+	reporter.Warn(fmt.Sprintf("%s: %s", result.Module.Name(), ReasonInterrupted))
+	result.Cancelled = true
+	result.Reason = ReasonInterrupted
+	result.Err = nil
+	return result
+}
+
+// CheckAll verifica o status de todos os modulos sem aplicar. Com ctx
+// cancelado, os modulos restantes retornam com Cancelled.
 func (o *Orchestrator) CheckAll(ctx context.Context, modules []module.Module) []Result {
 	var results []Result
 
 	for _, m := range modules {
+		if ctx.Err() != nil {
+			results = append(results, cancelledResult(m, ReasonNotStarted))
+			continue
+		}
 		result := Result{Module: m}
 
 		// Verifica guard
@@ -75,6 +363,7 @@ func (o *Orchestrator) CheckAll(ctx context.Context, modules []module.Module) []
 				result.Skipped = true
 				result.Reason = reason
 				result.Status = module.Status{Kind: module.Skipped, Message: reason}
+				o.log.Info("modulo pulado", "modulo", m.Name(), "motivo", reason)
 				results = append(results, result)
 				continue
 			}
@@ -82,9 +371,18 @@ func (o *Orchestrator) CheckAll(ctx context.Context, modules []module.Module) []
 
 		// Verifica status
 		if checker, ok := m.(module.Checker); ok {
-			status, err := checker.Check(ctx, o.sys)
+			status, err := o.check(ctx, m, checker)
+			if err != nil && ctx.Err() != nil {
+				results = append(results, cancelledResult(m, ReasonInterrupted))
+				continue
+			}
 			if err != nil {
 				result.Err = err
+				result.Unchecked = true
+				result.TimedOut = isTimeout(err)
+				o.log.Error("erro ao verificar", "modulo", m.Name(), "erro", err.Error())
+			} else {
+				o.log.Info("status verificado", "modulo", m.Name(), "status", status.Kind.String(), "mensagem", status.Message)
 			}
 			result.Status = status
 		}
@@ -95,50 +393,170 @@ func (o *Orchestrator) CheckAll(ctx context.Context, modules []module.Module) []
 	return results
 }
 
-func (o *Orchestrator) runOne(ctx context.Context, m module.Module) Result {
+// runOne executa Guard -> Check -> Apply para um modulo.
+// reporter recebe as mensagens do orchestrator; moduleReporter e repassado
+// ao Apply (na execucao paralela, prefixado com o nome do modulo).
+func (o *Orchestrator) runOne(ctx context.Context, m module.Module, reporter, moduleReporter module.Reporter) Result {
 	result := Result{Module: m}
 
 	// 1. Guard: verifica se deve executar
 	if guard, ok := m.(module.Guard); ok {
 		shouldRun, reason := guard.ShouldRun(ctx, o.sys)
 		if !shouldRun {
-			o.reporter.Warn(fmt.Sprintf("%s: pulado — %s", m.Name(), reason))
-			result.Skipped = true
-			result.Reason = reason
-			result.Status = module.Status{Kind: module.Skipped, Message: reason}
-			return result
+			reporter.Warn(fmt.Sprintf("%s: pulado — %s", m.Name(), reason))
+			return skippedResult(m, reason)
 		}
 	}
 
 	// 2. Check: verifica estado atual
 	if checker, ok := m.(module.Checker); ok {
-		status, err := checker.Check(ctx, o.sys)
+		status, err := o.check(ctx, m, checker)
 		if err != nil {
-			o.reporter.Error(fmt.Sprintf("%s: erro ao verificar — %v", m.Name(), err))
+			if ctx.Err() != nil {
+				return o.interrupted(result, reporter)
+			}
+			reporter.Error(fmt.Sprintf("%s: erro ao verificar — %v", m.Name(), err))
 			result.Err = err
+			result.Unchecked = true
 			return result
 		}
 		result.Status = status
+		o.emit(Event{Kind: StatusChecked, Module: m, Status: status})
 
 		if status.Kind == module.Installed {
-			o.reporter.Success(fmt.Sprintf("%s: ja instalado", m.Name()))
+			result.After = &status
+			reporter.Success(fmt.Sprintf("%s: ja instalado", m.Name()))
 			return result
 		}
 	}
 
 	// 3. Apply: aplica mudancas
 	if applier, ok := m.(module.Applier); ok {
-		o.reporter.Info(fmt.Sprintf("%s: aplicando...", m.Name()))
-		nr := &notingReporter{inner: o.reporter}
-		if err := applier.Apply(ctx, o.sys, nr); err != nil {
-			o.reporter.Error(fmt.Sprintf("%s: erro ao aplicar — %v", m.Name(), err))
+		reporter.Info(fmt.Sprintf("%s: aplicando...", m.Name()))
+		nr := &notingReporter{inner: moduleReporter}
+		err := withLimit(ctx, o.limit(m, true), "apply", func(ctx context.Context) error {
+			return applier.Apply(ctx, o.sys, nr)
+		})
+		result.Retries = nr.retries
+		result.Session = nr.session
+		if err != nil {
+			if ctx.Err() != nil {
+				result.Notes = nr.notes
+				return o.interrupted(result, reporter)
+			}
+			reporter.Error(fmt.Sprintf("%s: erro ao aplicar — %v", m.Name(), err))
 			result.Err = err
 			return result
 		}
 		result.Applied = true
 		result.Notes = nr.notes
-		o.reporter.Success(fmt.Sprintf("%s: aplicado com sucesso", m.Name()))
+		result.After = o.recheck(ctx, m)
+		reporter.Success(fmt.Sprintf("%s: aplicado com sucesso", m.Name()))
+	}
+
+	return result
+}
+
+// Remove desfaz uma lista de modulos, dependentes antes das dependencias.
+// Para cada modulo: Guard -> Check -> Revert (se houver algo a remover).
+// O cancelamento de ctx funciona como em Run.
+func (o *Orchestrator) Remove(ctx context.Context, modules []module.Module) []Result {
+	ordered := RemovalOrder(modules)
+	total := len(ordered)
+	results := make([]Result, 0, total)
+
+	for i, m := range ordered {
+		reporter := &eventReporter{o: o, m: m, inner: o.reporter}
+		start := o.start(m)
+		reporter.Step(i+1, total, fmt.Sprintf("Removendo %s...", m.Name()))
+
+		var result Result
+		if ctx.Err() != nil {
+			result = o.notStarted(m, reporter)
+		} else {
+			result = o.removeOne(ctx, m, reporter)
+		}
+		results = append(results, o.finish(start, result))
+	}
+
+	return results
+}
+
+// RemovalOrder retorna os modulos na ordem de remocao: o inverso da ordem
+// de dependencias, para que dependentes sejam removidos antes.
+func RemovalOrder(modules []module.Module) []module.Module {
+	sorted := module.SortByDependencies(modules)
+	ordered := make([]module.Module, len(sorted))
+	for i, m := range sorted {
+		ordered[len(sorted)-1-i] = m
+	}
+	return ordered
+}
+
+// removeOne desfaz um unico modulo: Guard -> Check -> Revert.
+// Modulos sem Reverter sao pulados; modulos ausentes nao sao tocados.
+func (o *Orchestrator) removeOne(ctx context.Context, m module.Module, reporter module.Reporter) Result {
+	reverter, ok := m.(module.Reverter)
+	if !ok {
+		reason := "remocao nao suportada"
+		reporter.Warn(fmt.Sprintf("%s: pulado — %s", m.Name(), reason))
+		return skippedResult(m, reason)
+	}
+
+	// 1. Guard: mesmo ambiente exigido pelo Apply
+	if guard, ok := m.(module.Guard); ok {
+		shouldRun, reason := guard.ShouldRun(ctx, o.sys)
+		if !shouldRun {
+			reporter.Warn(fmt.Sprintf("%s: pulado — %s", m.Name(), reason))
+			return skippedResult(m, reason)
+		}
+	}
+
+	result := Result{Module: m}
+
+	// 2. Check: nada a remover se o modulo nao esta instalado
+	if checker, ok := m.(module.Checker); ok {
+		status, err := o.check(ctx, m, checker)
+		if err != nil {
+			if ctx.Err() != nil {
+				return o.interrupted(result, reporter)
+			}
+			reporter.Error(fmt.Sprintf("%s: erro ao verificar — %v", m.Name(), err))
+			result.Err = err
+			result.Unchecked = true
+			return result
+		}
+		result.Status = status
+		o.emit(Event{Kind: StatusChecked, Module: m, Status: status})
+
+		if status.Kind == module.Missing {
+			result.After = &status
+			reporter.Success(fmt.Sprintf("%s: nada a remover", m.Name()))
+			return result
+		}
+	}
+
+	// 3. Revert: desfaz as mudancas
+	reporter.Info(fmt.Sprintf("%s: removendo...", m.Name()))
+	nr := &notingReporter{inner: reporter}
+	err := withLimit(ctx, o.limit(m, true), "remove", func(ctx context.Context) error {
+		return reverter.Revert(ctx, o.sys, nr)
+	})
+	result.Retries = nr.retries
+	result.Session = nr.session
+	if err != nil {
+		if ctx.Err() != nil {
+			result.Notes = nr.notes
+			return o.interrupted(result, reporter)
+		}
+		reporter.Error(fmt.Sprintf("%s: erro ao remover — %v", m.Name(), err))
+		result.Err = err
+		return result
 	}
+	result.Reverted = true
+	result.Notes = nr.notes
+	result.After = o.recheck(ctx, m)
+	reporter.Success(fmt.Sprintf("%s: removido com sucesso", m.Name()))
 
 	return result
 }
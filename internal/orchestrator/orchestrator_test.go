@@ -2,8 +2,11 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ale/blueprint/internal/module"
 	"github.com/ale/blueprint/internal/system"
@@ -195,8 +198,8 @@ func TestRun_CheckError(t *testing.T) {
 
 	results := orch.Run(context.Background(), []module.Module{mod})
 
-	if results[0].Err == nil {
-		t.Error("esperava erro no resultado quando Check falha")
+	if results[0].Err == nil || !results[0].Unchecked {
+		t.Error("esperava erro (Unchecked) no resultado quando Check falha")
 	}
 	if mod.applied {
 		t.Error("Apply nao deveria ser chamado quando Check retorna erro")
@@ -237,8 +240,8 @@ func TestCheckAll_CheckerError(t *testing.T) {
 
 	results := orch.CheckAll(context.Background(), []module.Module{mod})
 
-	if results[0].Err == nil {
-		t.Error("esperava erro no resultado de CheckAll quando checker falha")
+	if results[0].Err == nil || !results[0].Unchecked {
+		t.Error("esperava erro (Unchecked) no resultado de CheckAll quando checker falha")
 	}
 }
 
@@ -292,3 +295,510 @@ func TestCheckAll_BareModule(t *testing.T) {
 		t.Logf("status de bare module: %s (zero-value esperado)", results[0].Status.Kind)
 	}
 }
+
+// fakeDependentModule adiciona Dependent ao fakeModule.
+type fakeDependentModule struct {
+	fakeModule
+	requires []string
+}
+
+func (f *fakeDependentModule) Requires() []string { return f.requires }
+
+func TestRun_OrdersByDependencies(t *testing.T) {
+	mock := system.NewMock()
+	reporter := &testReporter{}
+	orch := New(mock, reporter)
+
+	dependent := &fakeDependentModule{
+		fakeModule: fakeModule{name: "devbox", checkStatus: module.Status{Kind: module.Missing}},
+		requires:   []string{"devcontainers"},
+	}
+	dep := &fakeModule{name: "devcontainers", checkStatus: module.Status{Kind: module.Missing}}
+
+	results := orch.Run(context.Background(), []module.Module{dependent, dep})
+
+	if results[0].Module.Name() != "devcontainers" || results[1].Module.Name() != "devbox" {
+		t.Errorf("ordem incorreta: %s, %s", results[0].Module.Name(), results[1].Module.Name())
+	}
+	if !dependent.applied || !dep.applied {
+		t.Error("ambos os modulos deveriam ser aplicados")
+	}
+}
+
+func TestRun_SkipsWhenDependencyFails(t *testing.T) {
+	mock := system.NewMock()
+	reporter := &testReporter{}
+	orch := New(mock, reporter)
+
+	dep := &fakeModule{
+		name:        "devcontainers",
+		checkStatus: module.Status{Kind: module.Missing},
+		applyErr:    fmt.Errorf("rpm-ostree falhou"),
+	}
+	dependent := &fakeDependentModule{
+		fakeModule: fakeModule{name: "devbox", checkStatus: module.Status{Kind: module.Missing}},
+		requires:   []string{"devcontainers"},
+	}
+
+	results := orch.Run(context.Background(), []module.Module{dep, dependent})
+
+	if !results[1].Skipped {
+		t.Fatal("dependente deveria ser pulado quando a dependencia falha")
+	}
+	if dependent.applied {
+		t.Error("Apply do dependente nao deveria ser chamado")
+	}
+	if results[1].Reason != "depende de devcontainers, que falhou" {
+		t.Errorf("motivo inesperado: %s", results[1].Reason)
+	}
+}
+
+func TestRun_SkipsWhenDependencySkipped(t *testing.T) {
+	mock := system.NewMock()
+	reporter := &testReporter{}
+	orch := New(mock, reporter)
+
+	dep := &fakeGuardedModule{
+		fakeModule: fakeModule{name: "a"},
+		shouldRun:  false,
+		reason:     "dentro de container",
+	}
+	dependent := &fakeDependentModule{
+		fakeModule: fakeModule{name: "b", checkStatus: module.Status{Kind: module.Missing}},
+		requires:   []string{"a"},
+	}
+
+	results := orch.Run(context.Background(), []module.Module{dep, dependent})
+
+	if !results[1].Skipped || dependent.applied {
+		t.Error("dependente deveria ser pulado quando a dependencia e pulada")
+	}
+}
+
+func TestRun_SkipsWhenDependencyDeselected(t *testing.T) {
+	mock := system.NewMock()
+	reporter := &testReporter{}
+	orch := New(mock, reporter)
+	orch.Deselect("devcontainers")
+
+	dependent := &fakeDependentModule{
+		fakeModule: fakeModule{name: "devbox", checkStatus: module.Status{Kind: module.Missing}},
+		requires:   []string{"devcontainers"},
+	}
+
+	results := orch.Run(context.Background(), []module.Module{dependent})
+
+	if !results[0].Skipped {
+		t.Fatal("dependente deveria ser pulado quando a dependencia foi desmarcada")
+	}
+	if results[0].Reason != "depende de devcontainers, que foi desmarcado" {
+		t.Errorf("motivo inesperado: %s", results[0].Reason)
+	}
+}
+
+func TestRun_DependencyOutsideRunDoesNotBlock(t *testing.T) {
+	mock := system.NewMock()
+	reporter := &testReporter{}
+	orch := New(mock, reporter)
+
+	// Ex: perfil wsl exclui devcontainers, mas devbox deve rodar
+	dependent := &fakeDependentModule{
+		fakeModule: fakeModule{name: "devbox", checkStatus: module.Status{Kind: module.Missing}},
+		requires:   []string{"devcontainers"},
+	}
+
+	results := orch.Run(context.Background(), []module.Module{dependent})
+
+	if results[0].Skipped || !dependent.applied {
+		t.Error("dependencia fora da execucao nao deveria bloquear o modulo")
+	}
+}
+
+// fakeRevertibleModule adiciona Reverter ao fakeModule.
+type fakeRevertibleModule struct {
+	fakeModule
+	requires  []string
+	revertErr error
+	reverted  bool
+	order     *[]string
+}
+
+func (f *fakeRevertibleModule) Requires() []string { return f.requires }
+
+func (f *fakeRevertibleModule) Revert(_ context.Context, _ module.System, _ module.Reporter) error {
+	f.reverted = true
+	if f.order != nil {
+		*f.order = append(*f.order, f.name)
+	}
+	return f.revertErr
+}
+
+func TestRemove_RevertsInstalled(t *testing.T) {
+	orch := New(system.NewMock(), &testReporter{})
+
+	mod := &fakeRevertibleModule{
+		fakeModule: fakeModule{name: "cedilla", checkStatus: module.Status{Kind: module.Installed}},
+	}
+
+	results := orch.Remove(context.Background(), []module.Module{mod})
+
+	if !mod.reverted || !results[0].Reverted {
+		t.Error("modulo instalado deveria ser revertido")
+	}
+}
+
+func TestRemove_NothingToRemove(t *testing.T) {
+	orch := New(system.NewMock(), &testReporter{})
+
+	mod := &fakeRevertibleModule{
+		fakeModule: fakeModule{name: "cedilla", checkStatus: module.Status{Kind: module.Missing}},
+	}
+
+	results := orch.Remove(context.Background(), []module.Module{mod})
+
+	if mod.reverted || results[0].Reverted {
+		t.Error("modulo ausente nao deveria ser revertido")
+	}
+	if results[0].Err != nil || results[0].Skipped {
+		t.Errorf("resultado inesperado: %+v", results[0])
+	}
+}
+
+func TestRemove_Unsupported(t *testing.T) {
+	orch := New(system.NewMock(), &testReporter{})
+
+	mod := &fakeModule{name: "bluefin-update", checkStatus: module.Status{Kind: module.Installed}}
+
+	results := orch.Remove(context.Background(), []module.Module{mod})
+
+	if !results[0].Skipped || results[0].Reason != "remocao nao suportada" {
+		t.Errorf("modulo sem Reverter deveria ser pulado: %+v", results[0])
+	}
+}
+
+func TestRemove_RevertError(t *testing.T) {
+	orch := New(system.NewMock(), &testReporter{})
+
+	mod := &fakeRevertibleModule{
+		fakeModule: fakeModule{name: "usb-audio", checkStatus: module.Status{Kind: module.Partial}},
+		revertErr:  fmt.Errorf("sudo falhou"),
+	}
+
+	results := orch.Remove(context.Background(), []module.Module{mod})
+
+	if results[0].Err == nil || results[0].Reverted {
+		t.Errorf("esperava erro no resultado: %+v", results[0])
+	}
+}
+
+func TestRemove_DependentsFirst(t *testing.T) {
+	orch := New(system.NewMock(), &testReporter{})
+	var order []string
+
+	dep := &fakeRevertibleModule{
+		fakeModule: fakeModule{name: "devcontainers", checkStatus: module.Status{Kind: module.Installed}},
+		order:      &order,
+	}
+	dependent := &fakeRevertibleModule{
+		fakeModule: fakeModule{name: "devbox", checkStatus: module.Status{Kind: module.Installed}},
+		requires:   []string{"devcontainers"},
+		order:      &order,
+	}
+
+	orch.Remove(context.Background(), []module.Module{dep, dependent})
+
+	if len(order) != 2 || order[0] != "devbox" || order[1] != "devcontainers" {
+		t.Errorf("dependente deveria ser removido primeiro: %v", order)
+	}
+}
+
+// fixingModule fica instalado depois do Apply (para testar o Check posterior).
+type fixingModule struct {
+	fakeModule
+}
+
+func (f *fixingModule) Check(_ context.Context, _ module.System) (module.Status, error) {
+	if f.applied {
+		return module.Status{Kind: module.Installed}, nil
+	}
+	return module.Status{Kind: module.Missing}, nil
+}
+
+func TestRun_RecordsStatusAfterApply(t *testing.T) {
+	orch := New(system.NewMock(), &testReporter{})
+	mod := &fixingModule{fakeModule: fakeModule{name: "a"}}
+
+	results := orch.Run(context.Background(), []module.Module{mod})
+
+	r := results[0]
+	if r.Status.Kind != module.Missing {
+		t.Errorf("Status = %v, esperava ausente", r.Status.Kind)
+	}
+	if r.After == nil || r.After.Kind != module.Installed {
+		t.Errorf("After = %+v, esperava instalado", r.After)
+	}
+	if r.Duration <= 0 {
+		t.Error("esperava duracao preenchida")
+	}
+}
+
+// cancelingModule cancela a execucao durante o Apply, como um Ctrl+C com um
+// comando em andamento (o comando morto retorna erro).
+type cancelingModule struct {
+	fakeModule
+	cancel context.CancelFunc
+}
+
+func (f *cancelingModule) Apply(ctx context.Context, _ module.System, _ module.Reporter) error {
+	f.applied = true
+	f.cancel()
+	<-ctx.Done()
+	return fmt.Errorf("signal: terminated")
+}
+
+func TestRun_CancelInterruptsAndStopsRemaining(t *testing.T) {
+	for _, jobs := range []int{1, 2} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			orch := New(system.NewMock(), &testReporter{})
+			orch.SetJobs(jobs)
+			first := &cancelingModule{
+				fakeModule: fakeModule{name: "a", checkStatus: module.Status{Kind: module.Missing}},
+				cancel:     cancel,
+			}
+			second := &fakeRevertibleModule{
+				fakeModule: fakeModule{name: "b", checkStatus: module.Status{Kind: module.Missing}},
+				requires:   []string{"a"},
+			}
+
+			results := orch.Run(ctx, []module.Module{first, second})
+
+			if len(results) != 2 {
+				t.Fatalf("esperava 2 resultados, obteve %d", len(results))
+			}
+			a, b := results[0], results[1]
+			if !a.Cancelled || a.Err != nil || a.Reason != ReasonInterrupted {
+				t.Errorf("a = %+v, esperava interrompido sem erro", a)
+			}
+			if a.Status.Kind != module.Missing {
+				t.Errorf("a.Status = %v, esperava o estado do Check preservado", a.Status.Kind)
+			}
+			if !b.Cancelled || b.Reason != ReasonNotStarted || second.applied {
+				t.Errorf("b = %+v (aplicado=%v), esperava cancelado antes de iniciar", b, second.applied)
+			}
+		})
+	}
+}
+
+func TestRemove_CancelledContextStartsNothing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mod := &fakeRevertibleModule{fakeModule: fakeModule{name: "a", checkStatus: module.Status{Kind: module.Installed}}}
+	results := New(system.NewMock(), &testReporter{}).Remove(ctx, []module.Module{mod})
+
+	if len(results) != 1 || !results[0].Cancelled || results[0].Reverted {
+		t.Fatalf("results = %+v, esperava cancelado sem remover", results)
+	}
+}
+
+func TestCheckAll_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mod := &fakeModule{name: "a", checkStatus: module.Status{Kind: module.Installed}}
+	results := New(system.NewMock(), nil).CheckAll(ctx, []module.Module{mod})
+
+	if len(results) != 1 || !results[0].Cancelled || results[0].Status.Kind != module.Skipped {
+		t.Fatalf("results = %+v, esperava cancelado", results)
+	}
+}
+
+// slowModule trava no Check ou no Apply ate o contexto ser cancelado, como
+// um curl sem rede. timeouts e opcional (module.Timeouter).
+type slowModule struct {
+	fakeModule
+	hangCheck bool
+	timeouts  *module.Timeouts
+}
+
+func (f *slowModule) Check(ctx context.Context, _ module.System) (module.Status, error) {
+	if f.hangCheck {
+		<-ctx.Done()
+		// Como o bluefin-update: o erro do comando e ignorado
+		return module.Status{Kind: module.Installed}, nil
+	}
+	return f.checkStatus, nil
+}
+
+func (f *slowModule) Apply(ctx context.Context, _ module.System, _ module.Reporter) error {
+	<-ctx.Done()
+	return fmt.Errorf("curl: signal: terminated")
+}
+
+type timeoutModule struct{ slowModule }
+
+func (f *timeoutModule) Timeouts() module.Timeouts { return *f.timeouts }
+
+func TestRun_GlobalTimeout(t *testing.T) {
+	orch := New(system.NewMock(), &testReporter{})
+	orch.SetTimeout(20 * time.Millisecond)
+
+	mod := &slowModule{fakeModule: fakeModule{name: "a", checkStatus: module.Status{Kind: module.Missing}}}
+	r := orch.Run(context.Background(), []module.Module{mod})[0]
+
+	var timeoutErr *module.TimeoutError
+	if !r.TimedOut || !errors.As(r.Err, &timeoutErr) {
+		t.Fatalf("result = %+v, esperava timeout", r)
+	}
+	if timeoutErr.Op != "apply" || timeoutErr.After != 20*time.Millisecond {
+		t.Errorf("timeout = %+v", timeoutErr)
+	}
+	if r.Cancelled {
+		t.Error("timeout nao e cancelamento")
+	}
+	if !strings.Contains(r.Err.Error(), "tempo esgotado apos 20ms") {
+		t.Errorf("mensagem = %q", r.Err.Error())
+	}
+}
+
+func TestRun_ModuleTimeoutOverridesDefault(t *testing.T) {
+	orch := New(system.NewMock(), &testReporter{})
+	orch.SetTimeout(time.Hour)
+
+	// Check trava e ignora o erro: o resultado ainda e timeout, nao "instalado"
+	mod := &timeoutModule{slowModule{
+		fakeModule: fakeModule{name: "a"},
+		hangCheck:  true,
+		timeouts:   &module.Timeouts{Check: 20 * time.Millisecond},
+	}}
+	r := orch.Run(context.Background(), []module.Module{mod})[0]
+
+	var timeoutErr *module.TimeoutError
+	if !r.TimedOut || !errors.As(r.Err, &timeoutErr) || timeoutErr.Op != "check" {
+		t.Fatalf("result = %+v, esperava timeout no check", r)
+	}
+}
+
+func TestRun_NoTimeoutByDefault(t *testing.T) {
+	mod := &fakeModule{name: "a", checkStatus: module.Status{Kind: module.Missing}}
+	r := New(system.NewMock(), &testReporter{}).Run(context.Background(), []module.Module{mod})[0]
+	if r.Err != nil || r.TimedOut || !r.Applied {
+		t.Fatalf("result = %+v", r)
+	}
+}
+
+func TestCheckAll_Timeout(t *testing.T) {
+	orch := New(system.NewMock(), nil)
+	orch.SetTimeout(20 * time.Millisecond)
+
+	mod := &slowModule{fakeModule: fakeModule{name: "a"}, hangCheck: true}
+	r := orch.CheckAll(context.Background(), []module.Module{mod})[0]
+	if !r.TimedOut || r.Err == nil {
+		t.Fatalf("result = %+v, esperava timeout", r)
+	}
+}
+
+// flakyModule falha no Apply as primeiras failures vezes, repetindo via
+// module.Retry.
+type flakyModule struct {
+	fakeModule
+	failures int
+}
+
+func (f *flakyModule) Apply(ctx context.Context, _ module.System, reporter module.Reporter) error {
+	return module.Retry(ctx, module.RetryPolicy{Attempts: 3}, reporter, "download", func(context.Context) error {
+		if f.failures > 0 {
+			f.failures--
+			return fmt.Errorf("conexao recusada")
+		}
+		f.applied = true
+		return nil
+	})
+}
+
+func TestRun_RecordsRetries(t *testing.T) {
+	rep := &testReporter{}
+	mod := &flakyModule{fakeModule: fakeModule{name: "a", checkStatus: module.Status{Kind: module.Missing}}, failures: 2}
+	r := New(system.NewMock(), rep).Run(context.Background(), []module.Module{mod})[0]
+
+	if r.Err != nil || !r.Applied {
+		t.Fatalf("result = %+v, esperava aplicado", r)
+	}
+	if len(r.Retries) != 2 || r.Retries[0].Op != "download" || r.Retries[1].Number != 2 {
+		t.Errorf("Retries = %+v, esperava 2 tentativas de download", r.Retries)
+	}
+	if !strings.Contains(strings.Join(rep.messages, "\n"), "WARN: download falhou (tentativa 1/3)") {
+		t.Errorf("esperava aviso da tentativa no reporter: %v", rep.messages)
+	}
+}
+
+func TestRun_RecordsRetriesOnFailure(t *testing.T) {
+	mod := &flakyModule{fakeModule: fakeModule{name: "a", checkStatus: module.Status{Kind: module.Missing}}, failures: 5}
+	r := New(system.NewMock(), nil).Run(context.Background(), []module.Module{mod})[0]
+
+	if r.Err == nil || len(r.Retries) != 2 {
+		t.Fatalf("result = %+v, esperava erro apos 2 retentativas", r)
+	}
+}
+
+// rebootModule pede reboot no Apply.
+type rebootModule struct{ fakeModule }
+
+func (f *rebootModule) Apply(_ context.Context, _ module.System, reporter module.Reporter) error {
+	module.RequireSession(reporter, module.SessionReboot, "reboot necessario")
+	return nil
+}
+
+func TestRun_RecordsSessionActions(t *testing.T) {
+	rep := &testReporter{}
+	mod := &rebootModule{fakeModule{name: "a", checkStatus: module.Status{Kind: module.Missing}}}
+	r := New(system.NewMock(), rep).Run(context.Background(), []module.Module{mod})[0]
+
+	if len(r.Session) != 1 || r.Session[0].Action != module.SessionReboot || r.Session[0].Reason != "reboot necessario" {
+		t.Errorf("Session = %+v, esperava o pedido de reboot", r.Session)
+	}
+	if !strings.Contains(strings.Join(rep.messages, "\n"), "WARN: reboot necessario") {
+		t.Errorf("pedido deveria aparecer como aviso: %v", rep.messages)
+	}
+}
+
+func TestRun_DefersDependentsOfReboot(t *testing.T) {
+	dep := &rebootModule{fakeModule{name: "devcontainers", checkStatus: module.Status{Kind: module.Missing}}}
+	devbox := &fakeDependentModule{
+		fakeModule: fakeModule{name: "devbox", checkStatus: module.Status{Kind: module.Missing}},
+		requires:   []string{"devcontainers"},
+	}
+	vscode := &fakeDependentModule{
+		fakeModule: fakeModule{name: "vscode", checkStatus: module.Status{Kind: module.Missing}},
+		requires:   []string{"devbox"},
+	}
+	other := &fakeModule{name: "starship", checkStatus: module.Status{Kind: module.Missing}}
+
+	results := New(system.NewMock(), &testReporter{}).Run(context.Background(), []module.Module{dep, devbox, vscode, other})
+
+	byName := make(map[string]Result)
+	for _, r := range results {
+		byName[r.Module.Name()] = r
+	}
+	if !byName["devcontainers"].RequiresReboot() {
+		t.Error("devcontainers deveria pedir reboot")
+	}
+	for _, name := range []string{"devbox", "vscode"} {
+		r := byName[name]
+		if !r.Deferred || !r.Skipped {
+			t.Errorf("%s deveria ser adiado: %+v", name, r)
+		}
+	}
+	if devbox.applied || vscode.applied {
+		t.Error("modulos adiados nao deveriam ser aplicados")
+	}
+	if !strings.Contains(byName["devbox"].Reason, "devcontainers") {
+		t.Errorf("motivo deveria citar a dependencia: %q", byName["devbox"].Reason)
+	}
+	if !other.applied {
+		t.Error("modulo independente deveria ser aplicado")
+	}
+}
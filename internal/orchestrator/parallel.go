@@ -0,0 +1,208 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ale/blueprint/internal/module"
+)
+
+// syncReporter serializa chamadas ao reporter quando varios modulos rodam em paralelo.
+type syncReporter struct {
+	mu    sync.Mutex
+	inner module.Reporter
+}
+
+func (r *syncReporter) Info(msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inner.Info(msg)
+}
+
+func (r *syncReporter) Success(msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inner.Success(msg)
+}
+
+func (r *syncReporter) Warn(msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inner.Warn(msg)
+}
+
+func (r *syncReporter) Error(msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inner.Error(msg)
+}
+
+func (r *syncReporter) Step(current, total int, msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inner.Step(current, total, msg)
+}
+
+// prefixReporter prefixa as mensagens com o nome do modulo, para que a saida
+// de modulos concorrentes continue legivel no modo headless.
+type prefixReporter struct {
+	inner  module.Reporter
+	prefix string
+}
+
+func newPrefixReporter(inner module.Reporter, name string) *prefixReporter {
+	return &prefixReporter{inner: inner, prefix: "[" + name + "] "}
+}
+
+func (r *prefixReporter) Info(msg string)    { r.inner.Info(r.prefix + msg) }
+func (r *prefixReporter) Success(msg string) { r.inner.Success(r.prefix + msg) }
+func (r *prefixReporter) Warn(msg string)    { r.inner.Warn(r.prefix + msg) }
+func (r *prefixReporter) Error(msg string)   { r.inner.Error(r.prefix + msg) }
+func (r *prefixReporter) Step(current, total int, msg string) {
+	r.inner.Step(current, total, r.prefix+msg)
+}
+
+// finished carrega o resultado de um modulo executado por um worker.
+type finished struct {
+	index  int
+	result Result
+}
+
+// runParallel executa os modulos (ja ordenados por dependencia) com ate
+// o.jobs workers simultaneos. Um modulo so inicia quando:
+//   - as dependencias que o precedem na ordem terminaram;
+//   - nenhum modulo em execucao usa os mesmos recursos exclusivos (module.Exclusive);
+//   - ha worker livre.
+//
+// Os resultados sao retornados na mesma ordem de modules.
+func (o *Orchestrator) runParallel(ctx context.Context, modules []module.Module) []Result {
+	total := len(modules)
+	results := make([]Result, total)
+	position := make(map[string]int, total)
+	for i, m := range modules {
+		position[m.Name()] = i
+	}
+
+	reporter := &syncReporter{inner: o.reporter}
+	done := make(map[string]Result, total)
+	held := make(map[string]bool)
+	started := make([]bool, total)
+	ch := make(chan finished)
+	running, step := 0, 0
+
+	for len(done) < total {
+		for i, m := range modules {
+			if started[i] || !depsFinished(m, i, position, done) {
+				continue
+			}
+
+			// Execucao cancelada: nao inicia mais nada
+			if ctx.Err() != nil {
+				started[i] = true
+				step++
+				rep := &eventReporter{o: o, m: m, inner: reporter}
+				start := o.start(m)
+				rep.Step(step, total, fmt.Sprintf("Processando %s...", m.Name()))
+				results[i] = o.finish(start, o.notStarted(m, rep))
+				done[m.Name()] = results[i]
+				continue
+			}
+
+			// Dependencia falhou: pula sem ocupar worker
+			if reason, blocked := BlockedBy(m, done, o.deselected); blocked {
+				started[i] = true
+				step++
+				rep := &eventReporter{o: o, m: m, inner: reporter}
+				start := o.start(m)
+				rep.Step(step, total, fmt.Sprintf("Processando %s...", m.Name()))
+				rep.Warn(fmt.Sprintf("%s: pulado — %s", m.Name(), reason))
+				results[i] = o.finish(start, skippedResult(m, reason))
+				done[m.Name()] = results[i]
+				continue
+			}
+
+			// Dependencia pediu reboot: adia sem ocupar worker
+			if reason, deferred := DeferredBy(m, done); deferred {
+				started[i] = true
+				step++
+				rep := &eventReporter{o: o, m: m, inner: reporter}
+				start := o.start(m)
+				rep.Step(step, total, fmt.Sprintf("Processando %s...", m.Name()))
+				results[i] = o.finish(start, o.deferred(m, reason, rep))
+				done[m.Name()] = results[i]
+				continue
+			}
+
+			if running >= o.jobs || !resourcesFree(m, held) {
+				continue
+			}
+
+			started[i] = true
+			running++
+			step++
+			for _, res := range resources(m) {
+				held[res] = true
+			}
+			rep := &eventReporter{o: o, m: m, inner: reporter}
+			start := o.start(m)
+			rep.Step(step, total, fmt.Sprintf("Processando %s...", m.Name()))
+
+			go func(i int, m module.Module, rep module.Reporter) {
+				result := o.runOne(ctx, m, rep, newPrefixReporter(rep, m.Name()))
+				ch <- finished{index: i, result: o.finish(start, result)}
+			}(i, m, rep)
+		}
+
+		if len(done) == total {
+			break
+		}
+
+		// Sempre ha algo rodando aqui: se nada estivesse rodando, o primeiro
+		// modulo pendente teria dependencias e recursos livres.
+		f := <-ch
+		running--
+		m := modules[f.index]
+		for _, res := range resources(m) {
+			delete(held, res)
+		}
+		results[f.index] = f.result
+		done[m.Name()] = f.result
+	}
+
+	return results
+}
+
+// depsFinished verifica se as dependencias de m que o precedem na ordem ja terminaram.
+// Dependencias posteriores (so possiveis em ciclos) ou fora da execucao sao ignoradas,
+// mantendo o mesmo comportamento da execucao sequencial.
+func depsFinished(m module.Module, index int, position map[string]int, done map[string]Result) bool {
+	for _, dep := range module.Requirements(m) {
+		pos, ok := position[dep]
+		if !ok || pos > index {
+			continue
+		}
+		if _, finished := done[dep]; !finished {
+			return false
+		}
+	}
+	return true
+}
+
+// resources retorna os recursos exclusivos declarados por m.
+func resources(m module.Module) []string {
+	if e, ok := m.(module.Exclusive); ok {
+		return e.Resources()
+	}
+	return nil
+}
+
+// resourcesFree verifica se nenhum recurso de m esta em uso.
+func resourcesFree(m module.Module, held map[string]bool) bool {
+	for _, res := range resources(m) {
+		if held[res] {
+			return false
+		}
+	}
+	return true
+}
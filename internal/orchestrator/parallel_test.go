@@ -0,0 +1,219 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/system"
+)
+
+// lockedReporter e um testReporter seguro para uso concorrente.
+type lockedReporter struct {
+	mu sync.Mutex
+	testReporter
+}
+
+func (r *lockedReporter) Info(msg string)    { r.mu.Lock(); r.testReporter.Info(msg); r.mu.Unlock() }
+func (r *lockedReporter) Success(msg string) { r.mu.Lock(); r.testReporter.Success(msg); r.mu.Unlock() }
+func (r *lockedReporter) Warn(msg string)    { r.mu.Lock(); r.testReporter.Warn(msg); r.mu.Unlock() }
+func (r *lockedReporter) Error(msg string)   { r.mu.Lock(); r.testReporter.Error(msg); r.mu.Unlock() }
+func (r *lockedReporter) Step(current, total int, msg string) {
+	r.mu.Lock()
+	r.testReporter.Step(current, total, msg)
+	r.mu.Unlock()
+}
+
+// concurrentModule executa apply no Apply e declara dependencias e recursos.
+type concurrentModule struct {
+	name      string
+	requires  []string
+	resources []string
+	apply     func(reporter module.Reporter) error
+}
+
+func (c *concurrentModule) Name() string        { return c.name }
+func (c *concurrentModule) Description() string { return "modulo concorrente de teste" }
+func (c *concurrentModule) Tags() []string      { return []string{"test"} }
+func (c *concurrentModule) Requires() []string  { return c.requires }
+func (c *concurrentModule) Resources() []string { return c.resources }
+
+func (c *concurrentModule) Check(_ context.Context, _ module.System) (module.Status, error) {
+	return module.Status{Kind: module.Missing}, nil
+}
+
+func (c *concurrentModule) Apply(_ context.Context, _ module.System, reporter module.Reporter) error {
+	return c.apply(reporter)
+}
+
+func newParallel(jobs int) (*Orchestrator, *lockedReporter) {
+	reporter := &lockedReporter{}
+	orch := New(system.NewMock(), reporter)
+	orch.SetJobs(jobs)
+	return orch, reporter
+}
+
+func TestRunParallel_IndependentModulesOverlap(t *testing.T) {
+	orch, _ := newParallel(2)
+
+	// Cada modulo espera o outro iniciar: so termina se rodarem juntos
+	var wg sync.WaitGroup
+	wg.Add(2)
+	barrier := func(_ module.Reporter) error {
+		wg.Done()
+		waited := make(chan struct{})
+		go func() { wg.Wait(); close(waited) }()
+		select {
+		case <-waited:
+			return nil
+		case <-time.After(2 * time.Second):
+			return fmt.Errorf("modulos nao rodaram em paralelo")
+		}
+	}
+
+	results := orch.Run(context.Background(), []module.Module{
+		&concurrentModule{name: "a", apply: barrier},
+		&concurrentModule{name: "b", apply: barrier},
+	})
+
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.Module.Name(), r.Err)
+		}
+	}
+}
+
+func TestRunParallel_ExclusiveResourceSerializes(t *testing.T) {
+	orch, _ := newParallel(4)
+
+	var active, maxActive int32
+	exclusive := func(_ module.Reporter) error {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			cur := atomic.LoadInt32(&maxActive)
+			if n <= cur || atomic.CompareAndSwapInt32(&maxActive, cur, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return nil
+	}
+
+	orch.Run(context.Background(), []module.Module{
+		&concurrentModule{name: "bluefin-update", resources: []string{module.ResourceRpmOstree}, apply: exclusive},
+		&concurrentModule{name: "devcontainers", resources: []string{module.ResourceRpmOstree}, apply: exclusive},
+		&concurrentModule{name: "outro", resources: []string{module.ResourceRpmOstree}, apply: exclusive},
+	})
+
+	if maxActive != 1 {
+		t.Errorf("modulos com o mesmo recurso nao deveriam rodar juntos (max simultaneos: %d)", maxActive)
+	}
+}
+
+func TestRunParallel_RespectsDependencies(t *testing.T) {
+	orch, _ := newParallel(4)
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func(module.Reporter) error {
+		return func(_ module.Reporter) error {
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	orch.Run(context.Background(), []module.Module{
+		&concurrentModule{name: "devbox", requires: []string{"devcontainers"}, apply: record("devbox")},
+		&concurrentModule{name: "devcontainers", apply: record("devcontainers")},
+	})
+
+	if len(order) != 2 || order[0] != "devcontainers" || order[1] != "devbox" {
+		t.Errorf("dependencia deveria terminar antes do dependente: %v", order)
+	}
+}
+
+func TestRunParallel_SkipsWhenDependencyFails(t *testing.T) {
+	orch, _ := newParallel(4)
+
+	applied := false
+	results := orch.Run(context.Background(), []module.Module{
+		&concurrentModule{name: "devcontainers", apply: func(module.Reporter) error { return fmt.Errorf("falhou") }},
+		&concurrentModule{name: "devbox", requires: []string{"devcontainers"}, apply: func(module.Reporter) error {
+			applied = true
+			return nil
+		}},
+	})
+
+	if applied {
+		t.Error("dependente nao deveria ser aplicado quando a dependencia falha")
+	}
+	if !results[1].Skipped {
+		t.Errorf("dependente deveria ser pulado: %+v", results[1])
+	}
+}
+
+func TestRunParallel_PreservesOrderAndPrefixesOutput(t *testing.T) {
+	orch, reporter := newParallel(3)
+
+	say := func(_ module.Reporter) error { return nil }
+	results := orch.Run(context.Background(), []module.Module{
+		&concurrentModule{name: "a", apply: say},
+		&concurrentModule{name: "b", apply: func(r module.Reporter) error {
+			r.Success("feito")
+			return nil
+		}},
+		&concurrentModule{name: "c", apply: say},
+	})
+
+	for i, name := range []string{"a", "b", "c"} {
+		if results[i].Module.Name() != name || !results[i].Applied {
+			t.Errorf("posicao %d: esperava %s aplicado, obteve %+v", i, name, results[i])
+		}
+	}
+
+	found := false
+	for _, msg := range reporter.messages {
+		if msg == "OK: [b] feito" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("mensagem do modulo deveria ser prefixada: %v", reporter.messages)
+	}
+}
+
+func TestSetJobs_MinimumOne(t *testing.T) {
+	orch := New(system.NewMock(), &testReporter{})
+	orch.SetJobs(0)
+	if orch.jobs != 1 {
+		t.Errorf("jobs deveria ser 1, obteve %d", orch.jobs)
+	}
+}
+
+func TestRunParallel_DefersDependentsOfReboot(t *testing.T) {
+	orch, _ := newParallel(4)
+
+	applied := false
+	results := orch.Run(context.Background(), []module.Module{
+		&concurrentModule{name: "devcontainers", apply: func(r module.Reporter) error {
+			module.RequireSession(r, module.SessionReboot, "reboot necessario")
+			return nil
+		}},
+		&concurrentModule{name: "devbox", requires: []string{"devcontainers"}, apply: func(module.Reporter) error {
+			applied = true
+			return nil
+		}},
+	})
+
+	if applied || !results[1].Deferred {
+		t.Errorf("dependente deveria ser adiado: %+v", results[1])
+	}
+}
@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/ale/blueprint/internal/module"
+)
+
+// Plan descreve o que Run faria com um modulo, sem executar nada.
+type Plan struct {
+	Module  module.Module
+	Status  module.Status   // Estado atual (Check)
+	Skipped bool            // Guard retornou false
+	Reason  string          // Motivo do skip
+	Planned bool            // Modulo implementa module.Planner
+	Actions []module.Action // Acoes que o Apply faria (vazio se ja instalado)
+	Err     error           // Erro do Check ou do Plan
+}
+
+// Plan roda Guard -> Check -> Plan para cada modulo, na ordem de execucao.
+// Modulos ja instalados nao sao planejados (o Apply os pularia).
+func (o *Orchestrator) Plan(ctx context.Context, modules []module.Module) []Plan {
+	modules = module.SortByDependencies(modules)
+	checked := o.CheckAll(ctx, modules)
+
+	plans := make([]Plan, 0, len(checked))
+	for _, r := range checked {
+		p := Plan{Module: r.Module, Status: r.Status, Skipped: r.Skipped, Reason: r.Reason, Err: r.Err}
+		planner, ok := r.Module.(module.Planner)
+		p.Planned = ok
+		if ok && !r.Skipped && r.Err == nil && r.Status.Kind != module.Installed {
+			p.Actions, p.Err = planner.Plan(ctx, o.sys)
+		}
+		plans = append(plans, p)
+	}
+	return plans
+}
@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/system"
+)
+
+// fakePlannerModule adiciona Planner ao fakeModule.
+type fakePlannerModule struct {
+	fakeModule
+	actions []module.Action
+	planErr error
+	planned bool
+}
+
+func (f *fakePlannerModule) Plan(_ context.Context, _ module.System) ([]module.Action, error) {
+	f.planned = true
+	return f.actions, f.planErr
+}
+
+func TestPlan(t *testing.T) {
+	write := module.Action{Kind: module.ActionWriteFile, Target: "/home/test/.XCompose", Content: []byte("x")}
+	missing := &fakePlannerModule{
+		fakeModule: fakeModule{name: "missing", checkStatus: module.Status{Kind: module.Missing}},
+		actions:    []module.Action{write},
+	}
+	installed := &fakePlannerModule{
+		fakeModule: fakeModule{name: "installed", checkStatus: module.Status{Kind: module.Installed}},
+		actions:    []module.Action{write},
+	}
+	failing := &fakePlannerModule{
+		fakeModule: fakeModule{name: "failing", checkStatus: module.Status{Kind: module.Partial}},
+		planErr:    fmt.Errorf("sem permissao"),
+	}
+	noPlanner := &fakeModule{name: "no-planner", checkStatus: module.Status{Kind: module.Missing}}
+	skipped := &fakeGuardedModule{fakeModule: fakeModule{name: "skipped"}, reason: "dentro de container"}
+
+	plans := New(system.NewMock(), nil).Plan(context.Background(),
+		[]module.Module{missing, installed, failing, noPlanner, skipped})
+
+	if len(plans) != 5 {
+		t.Fatalf("esperava 5 planos, obteve %d", len(plans))
+	}
+	if p := plans[0]; !p.Planned || len(p.Actions) != 1 || p.Actions[0].Target != write.Target {
+		t.Errorf("missing: %+v", p)
+	}
+	if p := plans[1]; len(p.Actions) != 0 || installed.planned {
+		t.Errorf("installed nao deveria ser planejado: %+v", p)
+	}
+	if p := plans[2]; p.Err == nil {
+		t.Errorf("failing: esperava erro, obteve %+v", p)
+	}
+	if p := plans[3]; p.Planned || len(p.Actions) != 0 {
+		t.Errorf("no-planner: %+v", p)
+	}
+	if p := plans[4]; !p.Skipped || p.Reason != "dentro de container" {
+		t.Errorf("skipped: %+v", p)
+	}
+	for _, m := range []*fakePlannerModule{missing, installed, failing} {
+		if m.applied {
+			t.Errorf("%s: Plan nao deveria aplicar", m.name)
+		}
+	}
+}
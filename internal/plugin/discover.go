@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Discover lista os executaveis blueprint-module-* dos diretorios de
+// plugins e do PATH, nessa ordem. Se o mesmo nome aparece em mais de um
+// lugar, vale o primeiro (como no PATH).
+func Discover(pluginDirs []string, pathEnv string) []string {
+	dirs := append([]string{}, pluginDirs...)
+	dirs = append(dirs, filepath.SplitList(pathEnv)...)
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		matches, _ := filepath.Glob(filepath.Join(dir, Prefix+"*"))
+		sort.Strings(matches)
+		for _, path := range matches {
+			name := filepath.Base(path)
+			if seen[name] || !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// isExecutable verifica se e um arquivo regular com permissao de execucao.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
@@ -0,0 +1,251 @@
+// Package plugin integra modulos externos: executaveis blueprint-module-*
+// (no PATH ou em ~/.config/blueprint/plugins) que falam um protocolo JSON
+// simples no stdin/stdout. Cada plugin vira um module.Module comum, visivel
+// no status, nos perfis e no TUI.
+//
+// Cada chamada executa o plugin uma vez. O blueprint escreve um pedido no
+// stdin e le linhas JSON do stdout:
+//
+//	stdin:  {"protocol": 1, "method": "check"}
+//	stdout: {"type": "step", "current": 1, "total": 2, "text": "Instalando..."}
+//	        {"type": "info", "text": "..."}
+//	        {"type": "result", "status": "installed", "message": "ok"}
+//
+// Metodos: describe, guard, check, apply e revert (se describe declarar
+// "revert": true). Eventos aceitos: step, info, success, warn e error.
+// Linhas que nao sao JSON viram info. Saida com codigo diferente de zero ou
+// um result com "error" e falha.
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
+)
+
+// Protocol e a versao do protocolo enviada em cada pedido.
+const Protocol = 1
+
+// Prefix e o prefixo do nome dos executaveis de plugin.
+const Prefix = "blueprint-module-"
+
+// describeTimeout limita o describe, que roda a cada inicializacao.
+const describeTimeout = 5 * time.Second
+
+// Metodos do protocolo.
+const (
+	MethodDescribe = "describe"
+	MethodGuard    = "guard"
+	MethodCheck    = "check"
+	MethodApply    = "apply"
+	MethodRevert   = "revert"
+)
+
+// Request e o pedido enviado ao plugin.
+type Request struct {
+	Protocol int    `json:"protocol"`
+	Method   string `json:"method"`
+}
+
+// Message e uma linha de saida do plugin: um evento de progresso ou o
+// resultado final (type "result").
+type Message struct {
+	Type    string `json:"type"`
+	Text    string `json:"text,omitempty"`
+	Current int    `json:"current,omitempty"`
+	Total   int    `json:"total,omitempty"`
+
+	// describe
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Requires    []string `json:"requires,omitempty"`
+	Resources   []string `json:"resources,omitempty"` // ex: "sudo", "dconf" (ver module.Exclusive)
+	Revert      bool     `json:"revert,omitempty"`
+
+	// guard
+	Run    *bool  `json:"run,omitempty"`
+	Reason string `json:"reason,omitempty"`
+
+	// check: installed, missing ou partial
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// qualquer metodo
+	Error string `json:"error,omitempty"`
+}
+
+// statusKinds mapeia os status do protocolo.
+var statusKinds = map[string]module.StatusKind{
+	"installed": module.Installed,
+	"missing":   module.Missing,
+	"partial":   module.Partial,
+}
+
+// Module e um plugin externo. Implementa Guard, Checker, Applier, Dependent
+// e Exclusive; Revert so tem efeito se o plugin declarar suporte.
+type Module struct {
+	path string
+	desc Message
+}
+
+// Load executa o describe do plugin e monta o modulo.
+func Load(ctx context.Context, sys module.System, path string) (*Module, error) {
+	ctx, cancel := context.WithTimeout(ctx, describeTimeout)
+	defer cancel()
+
+	m := &Module{path: path}
+	desc, err := m.call(ctx, sys, MethodDescribe, nil)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+	if desc.Name == "" {
+		return nil, fmt.Errorf("plugin %s: describe sem name", path)
+	}
+	if len(desc.Tags) == 0 {
+		return nil, fmt.Errorf("plugin %s: modulo %s sem tags (nao entraria em nenhum perfil)", path, desc.Name)
+	}
+	m.desc = desc
+	return m, nil
+}
+
+// Register carrega os plugins e registra os modulos. Um plugin que falha no
+// describe (quebrado, lento, JSON invalido) ou repete um nome ja registrado
+// e pulado: os erros retornados viram aviso e aparecem no doctor, sem
+// impedir o uso da CLI.
+func Register(ctx context.Context, reg *module.Registry, sys module.System, paths []string) []error {
+	var errs []error
+	for _, path := range paths {
+		m, err := Load(ctx, sys, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := reg.Register(m); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", path, err))
+		}
+	}
+	return errs
+}
+
+// Path retorna o executavel do plugin.
+func (m *Module) Path() string { return m.path }
+
+func (m *Module) Name() string        { return m.desc.Name }
+func (m *Module) Description() string { return m.desc.Description }
+func (m *Module) Tags() []string      { return m.desc.Tags }
+func (m *Module) Requires() []string  { return m.desc.Requires }
+func (m *Module) Resources() []string { return m.desc.Resources }
+
+// ShouldRun pergunta ao plugin se deve rodar. Falha ao executar o guard
+// pula o modulo com o erro como motivo.
+func (m *Module) ShouldRun(ctx context.Context, sys module.System) (bool, string) {
+	res, err := m.call(ctx, sys, MethodGuard, nil)
+	if err != nil {
+		return false, err.Error()
+	}
+	if res.Run == nil || *res.Run {
+		return true, ""
+	}
+	return false, res.Reason
+}
+
+// Check pede o estado ao plugin. Sem resultado (ex: em dry-run, onde o
+// plugin nao e executado) o modulo e considerado ausente.
+func (m *Module) Check(ctx context.Context, sys module.System) (module.Status, error) {
+	res, err := m.call(ctx, sys, MethodCheck, nil)
+	if err != nil {
+		return module.Status{}, err
+	}
+	if res.Type == "" {
+		return module.Status{Kind: module.Missing, Message: "plugin sem resposta"}, nil
+	}
+	kind, ok := statusKinds[res.Status]
+	if !ok {
+		return module.Status{}, fmt.Errorf("plugin %s: status invalido: %q", m.Name(), res.Status)
+	}
+	return module.Status{Kind: kind, Message: res.Message}, nil
+}
+
+func (m *Module) Apply(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	_, err := m.call(ctx, sys, MethodApply, reporter)
+	return err
+}
+
+// Revert chama o revert do plugin, se suportado.
+func (m *Module) Revert(ctx context.Context, sys module.System, reporter module.Reporter) error {
+	if !m.desc.Revert {
+		reporter.Warn(fmt.Sprintf("plugin %s nao suporta remocao", m.Name()))
+		return nil
+	}
+	_, err := m.call(ctx, sys, MethodRevert, reporter)
+	return err
+}
+
+// call executa um metodo e repassa os eventos ao reporter (se houver).
+// Retorna a mensagem result (vazia se o plugin nao enviou nenhuma).
+func (m *Module) call(ctx context.Context, sys module.System, method string, reporter module.Reporter) (Message, error) {
+	req, err := json.Marshal(Request{Protocol: Protocol, Method: method})
+	if err != nil {
+		return Message{}, err
+	}
+
+	var result Message
+	var protoErr error
+	handle := func(line string) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return
+		}
+		var msg Message
+		if err := json.Unmarshal([]byte(line), &msg); err != nil || msg.Type == "" {
+			report(reporter, Message{Type: "info", Text: line})
+			return
+		}
+		if msg.Type == "result" {
+			result = msg
+			return
+		}
+		if !report(reporter, msg) && protoErr == nil {
+			protoErr = fmt.Errorf("evento desconhecido: %q", msg.Type)
+		}
+	}
+
+	if err := sys.ExecInput(ctx, req, handle, m.path); err != nil {
+		return Message{}, fmt.Errorf("%s %s: %w", filepath.Base(m.path), method, err)
+	}
+	if protoErr != nil {
+		return Message{}, fmt.Errorf("%s %s: %w", filepath.Base(m.path), method, protoErr)
+	}
+	if result.Error != "" {
+		return Message{}, fmt.Errorf("%s", result.Error)
+	}
+	return result, nil
+}
+
+// report repassa um evento ao reporter. Retorna false se o tipo e desconhecido.
+func report(reporter module.Reporter, msg Message) bool {
+	if reporter == nil {
+		return true
+	}
+	switch msg.Type {
+	case "step":
+		reporter.Step(msg.Current, msg.Total, msg.Text)
+	case "info":
+		reporter.Info(msg.Text)
+	case "success":
+		reporter.Success(msg.Text)
+	case "warn":
+		reporter.Warn(msg.Text)
+	case "error":
+		reporter.Error(msg.Text)
+	default:
+		return false
+	}
+	return true
+}
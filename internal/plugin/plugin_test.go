@@ -0,0 +1,295 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/module/moduletest"
+	"github.com/ale/blueprint/internal/system"
+)
+
+const pluginPath = "/plugins/blueprint-module-vpn"
+
+// fakePlugin responde ExecInput por metodo do protocolo.
+type fakePlugin struct {
+	*system.Mock
+	responses map[string]string // metodo -> saida (linhas JSON)
+	errs      map[string]error
+	methods   []string
+}
+
+func newFakePlugin() *fakePlugin {
+	return &fakePlugin{
+		Mock: system.NewMock(),
+		responses: map[string]string{
+			MethodDescribe: `{"type":"result","name":"vpn","description":"VPN da empresa","tags":["system"],"requires":["devbox"],"resources":["sudo"],"revert":true}`,
+		},
+		errs: make(map[string]error),
+	}
+}
+
+func (f *fakePlugin) ExecInput(_ context.Context, input []byte, callback func(string), _ string, _ ...string) error {
+	var req Request
+	if err := json.Unmarshal(input, &req); err != nil {
+		return err
+	}
+	if req.Protocol != Protocol {
+		return fmt.Errorf("protocolo inesperado: %d", req.Protocol)
+	}
+	f.methods = append(f.methods, req.Method)
+	for _, line := range strings.Split(f.responses[req.Method], "\n") {
+		callback(line)
+	}
+	return f.errs[req.Method]
+}
+
+// recordingReporter guarda as mensagens recebidas.
+type recordingReporter struct{ lines []string }
+
+func (r *recordingReporter) Info(msg string)    { r.lines = append(r.lines, "info:"+msg) }
+func (r *recordingReporter) Success(msg string) { r.lines = append(r.lines, "success:"+msg) }
+func (r *recordingReporter) Warn(msg string)    { r.lines = append(r.lines, "warn:"+msg) }
+func (r *recordingReporter) Error(msg string)   { r.lines = append(r.lines, "error:"+msg) }
+func (r *recordingReporter) Step(current, total int, msg string) {
+	r.lines = append(r.lines, fmt.Sprintf("step %d/%d:%s", current, total, msg))
+}
+
+func load(t *testing.T, sys module.System) *Module {
+	t.Helper()
+	m, err := Load(context.Background(), sys, pluginPath)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	return m
+}
+
+func TestLoad_Describe(t *testing.T) {
+	m := load(t, newFakePlugin())
+
+	if m.Name() != "vpn" || m.Description() != "VPN da empresa" || m.Path() != pluginPath {
+		t.Errorf("modulo = %q/%q/%q", m.Name(), m.Description(), m.Path())
+	}
+	if strings.Join(m.Tags(), ",") != "system" || strings.Join(m.Requires(), ",") != "devbox" || strings.Join(m.Resources(), ",") != "sudo" {
+		t.Errorf("Tags/Requires/Resources = %v/%v/%v", m.Tags(), m.Requires(), m.Resources())
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		wantErr string
+	}{
+		{"sem name", `{"type":"result","tags":["x"]}`, nil, "describe sem name"},
+		{"sem tags", `{"type":"result","name":"vpn"}`, nil, "sem tags"},
+		{"erro no result", `{"type":"result","error":"quebrado"}`, nil, "quebrado"},
+		{"falha", "", fmt.Errorf("exit status 1"), "blueprint-module-vpn describe: exit status 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sys := newFakePlugin()
+			sys.responses[MethodDescribe] = tt.output
+			sys.errs[MethodDescribe] = tt.err
+			_, err := Load(context.Background(), sys, pluginPath)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("erro = %v, esperava conter %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShouldRun(t *testing.T) {
+	sys := newFakePlugin()
+	m := load(t, sys)
+
+	sys.responses[MethodGuard] = `{"type":"result","run":false,"reason":"fora da rede da empresa"}`
+	if ok, reason := m.ShouldRun(context.Background(), sys); ok || reason != "fora da rede da empresa" {
+		t.Errorf("ShouldRun() = %v, %q", ok, reason)
+	}
+
+	// Sem resposta: roda
+	sys.responses[MethodGuard] = ""
+	if ok, _ := m.ShouldRun(context.Background(), sys); !ok {
+		t.Error("esperava rodar sem resposta do guard")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	sys := newFakePlugin()
+	m := load(t, sys)
+
+	sys.responses[MethodCheck] = `{"type":"result","status":"partial","message":"config ausente"}`
+	status, err := m.Check(context.Background(), sys)
+	if err != nil || status.Kind != module.Partial || status.Message != "config ausente" {
+		t.Errorf("Check() = %v, %v", status, err)
+	}
+
+	sys.responses[MethodCheck] = `{"type":"result","status":"quase"}`
+	if _, err := m.Check(context.Background(), sys); err == nil {
+		t.Error("esperava erro com status invalido")
+	}
+
+	// Sem resposta (ex: dry-run): ausente
+	sys.responses[MethodCheck] = ""
+	if status, _ := m.Check(context.Background(), sys); status.Kind != module.Missing {
+		t.Errorf("Check() = %v, esperava Missing", status)
+	}
+}
+
+func TestApply_StreamsEvents(t *testing.T) {
+	sys := newFakePlugin()
+	m := load(t, sys)
+
+	sys.responses[MethodApply] = strings.Join([]string{
+		`{"type":"step","current":1,"total":2,"text":"Instalando cliente"}`,
+		`saida livre do instalador`,
+		`{"type":"success","text":"cliente instalado"}`,
+		`{"type":"warn","text":"reinicie a rede"}`,
+		`{"type":"result"}`,
+	}, "\n")
+
+	reporter := &recordingReporter{}
+	if err := m.Apply(context.Background(), sys, reporter); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	want := []string{
+		"step 1/2:Instalando cliente",
+		"info:saida livre do instalador",
+		"success:cliente instalado",
+		"warn:reinicie a rede",
+	}
+	if strings.Join(reporter.lines, "|") != strings.Join(want, "|") {
+		t.Errorf("eventos = %v, esperava %v", reporter.lines, want)
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	sys := newFakePlugin()
+	m := load(t, sys)
+
+	sys.responses[MethodApply] = `{"type":"result","error":"credenciais invalidas"}`
+	if err := m.Apply(context.Background(), sys, moduletest.NoopReporter()); err == nil || err.Error() != "credenciais invalidas" {
+		t.Errorf("erro = %v", err)
+	}
+
+	sys.responses[MethodApply] = `{"type":"progress","text":"50%"}`
+	if err := m.Apply(context.Background(), sys, moduletest.NoopReporter()); err == nil || !strings.Contains(err.Error(), `evento desconhecido: "progress"`) {
+		t.Errorf("erro = %v", err)
+	}
+}
+
+func TestRevert(t *testing.T) {
+	sys := newFakePlugin()
+	m := load(t, sys)
+
+	if err := m.Revert(context.Background(), sys, moduletest.NoopReporter()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if sys.methods[len(sys.methods)-1] != MethodRevert {
+		t.Errorf("metodos = %v, esperava revert", sys.methods)
+	}
+
+	// Sem suporte declarado: nao chama o plugin
+	sys.responses[MethodDescribe] = `{"type":"result","name":"vpn","tags":["system"]}`
+	m = load(t, sys)
+	calls := len(sys.methods)
+	reporter := &recordingReporter{}
+	if err := m.Revert(context.Background(), sys, reporter); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(sys.methods) != calls || len(reporter.lines) != 1 || !strings.HasPrefix(reporter.lines[0], "warn:") {
+		t.Errorf("metodos = %v, eventos = %v", sys.methods, reporter.lines)
+	}
+}
+
+func TestRegister_RealExecutable(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+read -r request
+case "$request" in
+  *describe*) echo '{"type":"result","name":"hello","tags":["shell"]}' ;;
+  *check*) echo '{"type":"result","status":"installed","message":"ok"}' ;;
+  *) echo "metodo desconhecido" >&2; exit 1 ;;
+esac
+`
+	path := filepath.Join(dir, Prefix+"hello")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Arquivo sem permissao de execucao e ignorado
+	if err := os.WriteFile(filepath.Join(dir, Prefix+"ignored"), []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := Discover([]string{dir}, "")
+	if len(paths) != 1 || paths[0] != path {
+		t.Fatalf("Discover() = %v", paths)
+	}
+
+	reg := module.NewRegistry()
+	sys := system.NewReal()
+	if errs := Register(context.Background(), reg, sys, paths); len(errs) > 0 {
+		t.Fatalf("erro inesperado: %v", errs)
+	}
+	m, ok := reg.ByName("hello")
+	if !ok {
+		t.Fatal("plugin hello nao registrado")
+	}
+
+	status, err := m.(module.Checker).Check(context.Background(), sys)
+	if err != nil || status.Kind != module.Installed {
+		t.Errorf("Check() = %v, %v", status, err)
+	}
+
+	err = m.(module.Applier).Apply(context.Background(), sys, moduletest.NoopReporter())
+	if err == nil || !strings.Contains(err.Error(), "metodo desconhecido") {
+		t.Errorf("esperava erro com o stderr do plugin, obteve %v", err)
+	}
+}
+
+func TestRegister_SkipsBrokenPlugin(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, Prefix+"good")
+	broken := filepath.Join(dir, Prefix+"broken")
+	if err := os.WriteFile(good, []byte("#!/bin/sh\necho '{\"type\":\"result\",\"name\":\"good\",\"tags\":[\"shell\"]}'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(broken, []byte("#!/bin/sh\necho '{nao e json'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := module.NewRegistry()
+	errs := Register(context.Background(), reg, system.NewReal(), []string{broken, good})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), broken) {
+		t.Errorf("erros = %v; esperava so o do plugin quebrado", errs)
+	}
+	if _, ok := reg.ByName("good"); !ok {
+		t.Error("plugin valido deveria ser registrado mesmo com outro quebrado")
+	}
+}
+
+func TestDiscover_FirstWins(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	for _, dir := range []string{first, second} {
+		if err := os.WriteFile(filepath.Join(dir, Prefix+"a"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(second, Prefix+"b"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Discover([]string{first}, second)
+	want := []string{filepath.Join(first, Prefix+"a"), filepath.Join(second, Prefix+"b")}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Discover() = %v, esperava %v", got, want)
+	}
+}
@@ -0,0 +1,217 @@
+package profile
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ale/blueprint/internal/module"
+)
+
+// Spec descreve um perfil definido pelo usuario em config.toml:
+//
+//	[profiles.backend-laptop]
+//	extends = "full"
+//	remove_tags = ["desktop"]
+//	modules = ["tiling-shell"]
+//
+//	[profiles.backend-laptop.detect]
+//	hostname = "dev-*"
+type Spec struct {
+	Extends        string      `toml:"extends"` // perfil base (built-in ou do usuario)
+	Description    string      `toml:"description"`
+	AddTags        []string    `toml:"add_tags"`    // incluidas (e retiradas das excluidas)
+	RemoveTags     []string    `toml:"remove_tags"` // retiradas das incluidas e excluidas
+	Modules        []string    `toml:"modules"`
+	ExcludeModules []string    `toml:"exclude_modules"`
+	Detect         *DetectRule `toml:"detect"`
+}
+
+// Compose monta os perfis a partir das Specs. Um perfil pode estender um
+// built-in ou outro perfil do usuario; ciclos e bases desconhecidas sao erro.
+// A regra de deteccao nao e herdada. Os perfis saem em ordem alfabetica de
+// nome, que e a ordem em que Detect testa as regras.
+func Compose(specs map[string]Spec) ([]Profile, error) {
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	done := make(map[string]Profile, len(specs))
+	var build func(name string, chain []string) (Profile, error)
+	build = func(name string, chain []string) (Profile, error) {
+		if p, ok := done[name]; ok {
+			return p, nil
+		}
+		for _, c := range chain {
+			if c == name {
+				return Profile{}, fmt.Errorf("perfil %s: ciclo em extends: %s -> %s", chain[0], strings.Join(chain, " -> "), name)
+			}
+		}
+
+		spec := specs[name]
+		var base Profile
+		if spec.Extends != "" {
+			if _, ok := specs[spec.Extends]; ok {
+				var err error
+				if base, err = build(spec.Extends, append(chain, name)); err != nil {
+					return Profile{}, err
+				}
+			} else if builtin, ok := builtinByName(spec.Extends); ok {
+				base = builtin
+			} else {
+				return Profile{}, fmt.Errorf("perfil %s: extends desconhecido: %q", name, spec.Extends)
+			}
+		}
+
+		p := Profile{
+			Name:           name,
+			Description:    spec.Description,
+			Tags:           union(without(base.Tags, spec.RemoveTags), spec.AddTags),
+			ExcludeTags:    union(without(base.ExcludeTags, spec.AddTags), spec.RemoveTags),
+			Modules:        union(without(base.Modules, spec.ExcludeModules), spec.Modules),
+			ExcludeModules: union(without(base.ExcludeModules, spec.Modules), spec.ExcludeModules),
+			Detect:         spec.Detect,
+		}
+		if p.Description == "" {
+			p.Description = fmt.Sprintf("Perfil do usuario (base: %s)", spec.Extends)
+			if spec.Extends == "" {
+				p.Description = "Perfil do usuario"
+			}
+		}
+		done[name] = p
+		return p, nil
+	}
+
+	profiles := make([]Profile, 0, len(names))
+	for _, name := range names {
+		p, err := build(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// builtinByName busca apenas entre os perfis built-in.
+func builtinByName(name string) (Profile, bool) {
+	for _, p := range []Profile{Full, Minimal, Server, WSL} {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// union retorna a seguido dos itens de b que nao estao em a.
+func union(a, b []string) []string {
+	out := append([]string(nil), a...)
+	for _, s := range b {
+		if !contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// without retorna os itens de a que nao estao em remove.
+func without(a, remove []string) []string {
+	var out []string
+	for _, s := range a {
+		if !contains(remove, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Tipos de container aceitos em DetectRule.Container.
+const (
+	ContainerNone      = "none" // maquina host (fora de container)
+	ContainerAny       = "any"  // qualquer container
+	ContainerDistrobox = "distrobox"
+	ContainerToolbox   = "toolbox"
+	ContainerDocker    = "docker"
+)
+
+// DetectRule escolhe um perfil do usuario automaticamente. Todas as
+// condicoes preenchidas precisam bater; uma regra vazia nunca bate.
+type DetectRule struct {
+	Hostname  string            `toml:"hostname"`  // padrao glob (ex: "dev-*")
+	Env       map[string]string `toml:"env"`       // variavel -> padrao glob ("*" = qualquer valor nao vazio)
+	Container string            `toml:"container"` // none, any, distrobox, toolbox, docker
+}
+
+// Validate confere os padroes e o tipo de container.
+func (r *DetectRule) Validate() error {
+	if r.Hostname == "" && len(r.Env) == 0 && r.Container == "" {
+		return fmt.Errorf("regra de deteccao vazia")
+	}
+	if _, err := path.Match(r.Hostname, ""); err != nil {
+		return fmt.Errorf("hostname: padrao invalido %q", r.Hostname)
+	}
+	for name, pattern := range r.Env {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("env.%s: padrao invalido %q", name, pattern)
+		}
+	}
+	switch r.Container {
+	case "", ContainerNone, ContainerAny, ContainerDistrobox, ContainerToolbox, ContainerDocker:
+	default:
+		return fmt.Errorf("container: tipo desconhecido %q (aceitos: none, any, distrobox, toolbox, docker)", r.Container)
+	}
+	return nil
+}
+
+// Matches verifica se o ambiente atual satisfaz a regra.
+func (r *DetectRule) Matches(sys module.System) bool {
+	if r.Hostname == "" && len(r.Env) == 0 && r.Container == "" {
+		return false
+	}
+	if r.Hostname != "" {
+		if ok, _ := path.Match(r.Hostname, hostname(sys)); !ok {
+			return false
+		}
+	}
+	for name, pattern := range r.Env {
+		value := sys.Env(name)
+		if value == "" {
+			return false
+		}
+		if ok, _ := path.Match(pattern, value); !ok {
+			return false
+		}
+	}
+	if r.Container != "" && containerType(sys) != r.Container && !(r.Container == ContainerAny && sys.IsContainer()) {
+		return false
+	}
+	return true
+}
+
+// hostname le o nome da maquina do kernel (funciona tambem com o Mock).
+func hostname(sys module.System) string {
+	data, err := sys.ReadFile("/proc/sys/kernel/hostname")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// containerType identifica o tipo de container em que o blueprint roda.
+func containerType(sys module.System) string {
+	switch {
+	case !sys.IsContainer():
+		return ContainerNone
+	case sys.FileExists("/run/.toolboxenv"):
+		return ContainerToolbox
+	case sys.Env("CONTAINER_ID") != "":
+		return ContainerDistrobox
+	case sys.FileExists("/.dockerenv"):
+		return ContainerDocker
+	default:
+		return ContainerAny
+	}
+}
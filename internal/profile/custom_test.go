@@ -0,0 +1,227 @@
+package profile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/system"
+)
+
+// registerForTest registra perfis do usuario e os remove ao fim do teste.
+func registerForTest(t *testing.T, profiles ...Profile) {
+	t.Helper()
+	t.Cleanup(func() { custom = nil })
+	if err := Register(profiles...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompose_ExtendsBuiltin(t *testing.T) {
+	profiles, err := Compose(map[string]Spec{
+		"backend-laptop": {
+			Extends:        "full",
+			RemoveTags:     []string{"desktop"},
+			AddTags:        []string{"wsl"},
+			Modules:        []string{"tiling-shell"},
+			ExcludeModules: []string{"devbox"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := profiles[0]
+	if want := []string{"shell", "system", "containers", "wsl"}; !reflect.DeepEqual(p.Tags, want) {
+		t.Errorf("Tags = %v, esperava %v", p.Tags, want)
+	}
+	if want := []string{"desktop"}; !reflect.DeepEqual(p.ExcludeTags, want) {
+		t.Errorf("ExcludeTags = %v, esperava %v", p.ExcludeTags, want)
+	}
+	if !reflect.DeepEqual(p.Modules, []string{"tiling-shell"}) || !reflect.DeepEqual(p.ExcludeModules, []string{"devbox"}) {
+		t.Errorf("Modules = %v, ExcludeModules = %v", p.Modules, p.ExcludeModules)
+	}
+	if !strings.Contains(p.Description, "full") {
+		t.Errorf("Description = %q, esperava mencionar a base", p.Description)
+	}
+}
+
+func TestCompose_ExtendsUserProfile(t *testing.T) {
+	profiles, err := Compose(map[string]Spec{
+		"team":  {Extends: "minimal", Modules: []string{"devbox"}},
+		"mine":  {Extends: "team", ExcludeModules: []string{"devbox"}, AddTags: []string{"desktop"}},
+		"other": {Description: "do zero", AddTags: []string{"shell"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]Profile)
+	for _, p := range profiles {
+		byName[p.Name] = p
+	}
+
+	mine := byName["mine"]
+	if len(mine.Modules) != 0 || !reflect.DeepEqual(mine.ExcludeModules, []string{"devbox"}) {
+		t.Errorf("mine: Modules = %v, ExcludeModules = %v", mine.Modules, mine.ExcludeModules)
+	}
+	if contains(mine.ExcludeTags, "desktop") || !contains(mine.Tags, "desktop") {
+		t.Errorf("mine: add_tags deveria tirar desktop das excluidas: %+v", mine)
+	}
+	if other := byName["other"]; other.Description != "do zero" || !reflect.DeepEqual(other.Tags, []string{"shell"}) {
+		t.Errorf("other = %+v", other)
+	}
+}
+
+func TestCompose_Errors(t *testing.T) {
+	tests := map[string]map[string]Spec{
+		"extends desconhecido": {"a": {Extends: "gamer"}},
+		"ciclo em extends":     {"a": {Extends: "b"}, "b": {Extends: "a"}},
+	}
+	for want, specs := range tests {
+		if _, err := Compose(specs); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("esperava erro %q, obteve %v", want, err)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	registerForTest(t, Profile{Name: "team", Tags: []string{"shell"}})
+
+	if _, err := ByName("team"); err != nil {
+		t.Errorf("ByName(team): %v", err)
+	}
+	if all := All(); all[len(all)-1].Name != "team" {
+		t.Errorf("All() deveria terminar com o perfil do usuario: %v", all)
+	}
+	if err := Register(Profile{Name: "full"}); err == nil {
+		t.Error("esperava erro ao redefinir perfil built-in")
+	}
+	if err := Register(Profile{Name: "auto"}); err == nil {
+		t.Error("esperava erro para o nome reservado auto")
+	}
+}
+
+func TestResolve_ModulesByName(t *testing.T) {
+	reg := module.NewRegistry()
+	_ = reg.Register(&stubModule{name: "starship", tags: []string{"shell"}})
+	_ = reg.Register(&stubModule{name: "cedilla", tags: []string{"desktop"}})
+	_ = reg.Register(&stubModule{name: "devbox", tags: []string{"shell"}})
+
+	p := Profile{Tags: []string{"shell"}, Modules: []string{"cedilla"}, ExcludeModules: []string{"devbox"}}
+
+	var got []string
+	for _, m := range Resolve(p, reg) {
+		got = append(got, m.Name())
+	}
+	if want := []string{"starship", "cedilla"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolve() = %v, esperava %v", got, want)
+	}
+}
+
+func TestDetectRule_Matches(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/proc/sys/kernel/hostname"] = []byte("dev-ale\n")
+	mock.EnvVars["BLUEPRINT_TEAM"] = "backend"
+
+	tests := []struct {
+		name string
+		rule DetectRule
+		want bool
+	}{
+		{"hostname bate", DetectRule{Hostname: "dev-*"}, true},
+		{"hostname nao bate", DetectRule{Hostname: "srv-*"}, false},
+		{"env bate", DetectRule{Env: map[string]string{"BLUEPRINT_TEAM": "back*"}}, true},
+		{"env qualquer valor", DetectRule{Env: map[string]string{"BLUEPRINT_TEAM": "*"}}, true},
+		{"env ausente", DetectRule{Env: map[string]string{"OUTRA": "*"}}, false},
+		{"host fora de container", DetectRule{Container: ContainerNone}, true},
+		{"exige container", DetectRule{Container: ContainerAny}, false},
+		{"todas as condicoes", DetectRule{Hostname: "dev-*", Container: ContainerDistrobox}, false},
+		{"regra vazia", DetectRule{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(mock); got != tt.want {
+				t.Errorf("Matches() = %v, esperava %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectRule_ContainerTypes(t *testing.T) {
+	tests := []struct {
+		setup func(*system.Mock)
+		want  string
+	}{
+		{func(m *system.Mock) { m.Files["/run/.toolboxenv"] = nil }, ContainerToolbox},
+		{func(m *system.Mock) { m.EnvVars["CONTAINER_ID"] = "devbox" }, ContainerDistrobox},
+		{func(m *system.Mock) { m.Files["/.dockerenv"] = nil }, ContainerDocker},
+	}
+	for _, tt := range tests {
+		mock := system.NewMock()
+		mock.Container = true
+		tt.setup(mock)
+
+		rule := DetectRule{Container: tt.want}
+		if !rule.Matches(mock) {
+			t.Errorf("esperava container %s", tt.want)
+		}
+		if !(&DetectRule{Container: ContainerAny}).Matches(mock) {
+			t.Errorf("%s deveria bater com container=any", tt.want)
+		}
+	}
+}
+
+func TestDetectRule_Validate(t *testing.T) {
+	invalid := []DetectRule{
+		{},
+		{Hostname: "dev-["},
+		{Env: map[string]string{"X": "["}},
+		{Container: "podman"},
+	}
+	for _, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Validate(%+v): esperava erro", r)
+		}
+	}
+	if err := (&DetectRule{Hostname: "dev-*", Container: ContainerNone}).Validate(); err != nil {
+		t.Errorf("erro inesperado: %v", err)
+	}
+}
+
+func TestDetect_UserProfileFirst(t *testing.T) {
+	registerForTest(t,
+		Profile{Name: "sem-regra", Tags: []string{"shell"}},
+		Profile{Name: "team", Tags: []string{"shell"}, Detect: &DetectRule{Env: map[string]string{"BLUEPRINT_TEAM": "backend"}}},
+	)
+
+	mock := system.NewMock()
+	mock.Container = true
+	if got := Detect(mock); got.Name != "minimal" {
+		t.Errorf("sem regra batendo deveria cair nos built-in, obteve %s", got.Name)
+	}
+
+	mock.EnvVars["BLUEPRINT_TEAM"] = "backend"
+	if got := Detect(mock); got.Name != "team" {
+		t.Errorf("esperava perfil do usuario team, obteve %s", got.Name)
+	}
+}
+
+func TestDetect_AlphabeticalOrder(t *testing.T) {
+	rule := &DetectRule{Env: map[string]string{"BLUEPRINT_TEAM": "backend"}}
+	profiles, err := Compose(map[string]Spec{
+		"zeta":  {Extends: "full", Detect: rule},
+		"alpha": {Extends: "full", Detect: rule},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerForTest(t, profiles...)
+
+	mock := system.NewMock()
+	mock.EnvVars["BLUEPRINT_TEAM"] = "backend"
+	if got := Detect(mock); got.Name != "alpha" {
+		t.Errorf("com duas regras batendo, esperava o primeiro em ordem alfabetica (alpha), obteve %s", got.Name)
+	}
+}
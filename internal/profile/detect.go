@@ -5,11 +5,20 @@ import "github.com/ale/blueprint/internal/module"
 // Detect escolhe o perfil automaticamente baseado no ambiente.
 //
 // Regras:
+//   - Perfil do usuario com DetectRule que bate → esse perfil (em ordem
+//     alfabetica de nome, a ordem de Compose: o TOML nao preserva a ordem
+//     das tabelas)
 //   - Container → minimal (sem desktop, sem system — só shell)
 //   - WSL → wsl (shell + containers, sem desktop/system)
 //   - Sem sessão gráfica ($DISPLAY e $WAYLAND_DISPLAY vazios) → server
 //   - Todo o resto → full
 func Detect(sys module.System) Profile {
+	for _, p := range custom {
+		if p.Detect != nil && p.Detect.Matches(sys) {
+			return p
+		}
+	}
+
 	if sys.IsContainer() {
 		return Minimal
 	}
@@ -3,8 +3,11 @@ package profile
 
 // Profile define quais modulos incluir/excluir por tags.
 type Profile struct {
-	Name        string   // Nome do perfil (full, minimal, server)
-	Description string   // Descricao para exibicao
-	Tags        []string // Tags incluidas
-	ExcludeTags []string // Tags excluidas
+	Name           string      // Nome do perfil (full, minimal, server)
+	Description    string      // Descricao para exibicao
+	Tags           []string    // Tags incluidas
+	ExcludeTags    []string    // Tags excluidas
+	Modules        []string    // Modulos incluidos pelo nome, independente das tags
+	ExcludeModules []string    // Modulos excluidos pelo nome, independente das tags
+	Detect         *DetectRule // Regra para Detect escolher o perfil (apenas perfis do usuario)
 }
@@ -35,9 +35,27 @@ var (
 	}
 )
 
-// All retorna todos os perfis disponiveis.
+// custom guarda os perfis definidos pelo usuario (ver Register).
+var custom []Profile
+
+// All retorna todos os perfis disponiveis: built-in e depois os do usuario.
 func All() []Profile {
-	return []Profile{Full, Minimal, Server, WSL}
+	return append([]Profile{Full, Minimal, Server, WSL}, custom...)
+}
+
+// Register adiciona perfis do usuario. Nomes repetidos (inclusive de
+// perfis built-in) sao erro.
+func Register(profiles ...Profile) error {
+	for _, p := range profiles {
+		if p.Name == "" || p.Name == "auto" {
+			return fmt.Errorf("nome de perfil invalido: %q", p.Name)
+		}
+		if _, err := ByName(p.Name); err == nil {
+			return fmt.Errorf("perfil %q ja existe", p.Name)
+		}
+		custom = append(custom, p)
+	}
+	return nil
 }
 
 // ByName busca um perfil pelo nome.
@@ -3,17 +3,32 @@ package profile
 import "github.com/ale/blueprint/internal/module"
 
 // Resolve filtra os modulos do registry baseado no perfil.
-// Retorna apenas os modulos cujas tags sao incluidas e nao excluidas pelo perfil.
+// Retorna os modulos cujas tags sao incluidas e nao excluidas pelo perfil,
+// mais os incluidos pelo nome (Modules), menos os excluidos pelo nome
+// (ExcludeModules), ordenados de forma que dependencias (module.Dependent)
+// venham antes dos dependentes.
 func Resolve(p Profile, reg *module.Registry) []module.Module {
 	var result []module.Module
 
 	for _, m := range reg.All() {
-		if matchesTags(m.Tags(), p.Tags, p.ExcludeTags) {
+		if contains(p.ExcludeModules, m.Name()) {
+			continue
+		}
+		if contains(p.Modules, m.Name()) || matchesTags(m.Tags(), p.Tags, p.ExcludeTags) {
 			result = append(result, m)
 		}
 	}
 
-	return result
+	return module.SortByDependencies(result)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
 
 // matchesTags verifica se as tags de um modulo sao compativeis com o perfil.
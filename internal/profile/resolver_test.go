@@ -116,6 +116,32 @@ func TestResolve(t *testing.T) {
 	}
 }
 
+// dependentStub adiciona module.Dependent ao stubModule.
+type dependentStub struct {
+	stubModule
+	requires []string
+}
+
+func (d *dependentStub) Requires() []string { return d.requires }
+
+func TestResolve_OrdersByDependencies(t *testing.T) {
+	reg := module.NewRegistry()
+	_ = reg.Register(&dependentStub{
+		stubModule: stubModule{name: "devbox", tags: []string{"containers"}},
+		requires:   []string{"devcontainers"},
+	})
+	_ = reg.Register(&stubModule{name: "devcontainers", tags: []string{"system"}})
+
+	result := Resolve(Full, reg)
+
+	if len(result) != 2 {
+		t.Fatalf("esperava 2 modulos, obteve %d", len(result))
+	}
+	if result[0].Name() != "devcontainers" || result[1].Name() != "devbox" {
+		t.Errorf("dependencia deveria vir antes: %s, %s", result[0].Name(), result[1].Name())
+	}
+}
+
 func TestMatchesTags(t *testing.T) {
 	tests := []struct {
 		name        string
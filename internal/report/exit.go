@@ -0,0 +1,96 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+)
+
+// Codigos de saida de status e apply (documentados no README).
+const (
+	ExitOK          = 0 // tudo instalado / apply sem falhas
+	ExitFailure     = 1 // o proprio blueprint falhou (flag, config, erro interno)
+	ExitDrift       = 2 // modulo ausente ou parcial (conforme --fail-on)
+	ExitModuleError = 3 // erro no check (status) ou modulo falhou (apply)
+	ExitSkippedOnly = 4 // nenhum modulo avaliado: todos pulados pelo guard
+
+	ExitCancelled = 130 // execucao interrompida por Ctrl+C/SIGTERM (128 + SIGINT)
+)
+
+// Condicoes aceitas por --fail-on.
+const (
+	FailPartial = "partial"
+	FailMissing = "missing"
+	FailError   = "error"
+	FailNone    = "none"
+)
+
+// FailOn define quais condicoes fazem status/apply sairem com erro.
+type FailOn struct {
+	Partial bool
+	Missing bool
+	Error   bool
+}
+
+// ParseFailOn le os valores de --fail-on (ex: ["partial", "error"]).
+// "none" desliga todas as condicoes.
+func ParseFailOn(values []string) (FailOn, error) {
+	var f FailOn
+	for _, v := range values {
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case FailPartial:
+			f.Partial = true
+		case FailMissing:
+			f.Missing = true
+		case FailError:
+			f.Error = true
+		case FailNone, "":
+		default:
+			return FailOn{}, fmt.Errorf("--fail-on invalido: %q (aceitos: partial, missing, error, none)", v)
+		}
+	}
+	return f, nil
+}
+
+// ExitCode calcula o codigo de saida de um conjunto de resultados. O estado
+// considerado e o final: re-verificado apos o apply (After) quando houver.
+// Erros tem prioridade sobre drift; se todos os modulos foram pulados, o
+// resultado e ExitSkippedOnly independente de --fail-on. Uma execucao
+// cancelada sempre resulta em ExitCancelled.
+func ExitCode(results []orchestrator.Result, failOn FailOn) int {
+	var evaluated int
+	var errs, drift bool
+	for _, r := range results {
+		if r.Cancelled {
+			return ExitCancelled
+		}
+		if r.Skipped {
+			continue
+		}
+		evaluated++
+		if r.Err != nil {
+			errs = true
+			continue
+		}
+		kind := r.Status.Kind
+		if r.After != nil {
+			kind = r.After.Kind
+		}
+		if (kind == module.Missing && failOn.Missing) || (kind == module.Partial && failOn.Partial) {
+			drift = true
+		}
+	}
+
+	switch {
+	case len(results) > 0 && evaluated == 0:
+		return ExitSkippedOnly
+	case errs && failOn.Error:
+		return ExitModuleError
+	case drift:
+		return ExitDrift
+	default:
+		return ExitOK
+	}
+}
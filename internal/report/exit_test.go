@@ -0,0 +1,63 @@
+package report
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+)
+
+func result(kind module.StatusKind) orchestrator.Result {
+	return orchestrator.Result{Module: stubModule{"m", nil}, Status: module.Status{Kind: kind}}
+}
+
+func TestParseFailOn(t *testing.T) {
+	f, err := ParseFailOn([]string{"partial", " ERROR "})
+	if err != nil || !f.Partial || f.Missing || !f.Error {
+		t.Errorf("ParseFailOn() = %+v, %v", f, err)
+	}
+	if f, _ := ParseFailOn([]string{"none"}); f != (FailOn{}) {
+		t.Errorf("none deveria desligar tudo: %+v", f)
+	}
+	if _, err := ParseFailOn([]string{"drift"}); err == nil {
+		t.Error("esperava erro com condicao invalida")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	all := FailOn{Partial: true, Missing: true, Error: true}
+	failed := result(module.Missing)
+	failed.Err = fmt.Errorf("falhou")
+	skipped := orchestrator.Result{Module: stubModule{"s", nil}, Skipped: true}
+	fixed := result(module.Missing)
+	fixed.After = &module.Status{Kind: module.Installed}
+	cancelled := result(module.Missing)
+	cancelled.Cancelled = true
+
+	tests := []struct {
+		name    string
+		results []orchestrator.Result
+		failOn  FailOn
+		want    int
+	}{
+		{"tudo instalado", []orchestrator.Result{result(module.Installed), skipped}, all, ExitOK},
+		{"parcial", []orchestrator.Result{result(module.Installed), result(module.Partial)}, all, ExitDrift},
+		{"parcial ignorado", []orchestrator.Result{result(module.Partial)}, FailOn{Missing: true, Error: true}, ExitOK},
+		{"ausente", []orchestrator.Result{result(module.Missing)}, FailOn{Missing: true}, ExitDrift},
+		{"erro tem prioridade", []orchestrator.Result{result(module.Partial), failed}, all, ExitModuleError},
+		{"erro ignorado", []orchestrator.Result{failed}, FailOn{Partial: true}, ExitOK},
+		{"so pulados", []orchestrator.Result{skipped, skipped}, FailOn{}, ExitSkippedOnly},
+		{"estado apos apply", []orchestrator.Result{fixed}, all, ExitOK},
+		{"sem modulos", nil, all, ExitOK},
+		{"cancelado tem prioridade", []orchestrator.Result{failed, cancelled}, all, ExitCancelled},
+		{"cancelado sem --fail-on", []orchestrator.Result{cancelled}, FailOn{}, ExitCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.results, tt.failOn); got != tt.want {
+				t.Errorf("ExitCode() = %d, esperava %d", got, tt.want)
+			}
+		})
+	}
+}
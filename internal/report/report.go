@@ -0,0 +1,227 @@
+// Package report define a saida legivel por maquina (--output json|yaml)
+// de "blueprint status" e "blueprint apply". O schema e versionado:
+// campos so sao adicionados; renomear ou remover exige novo SchemaVersion.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/session"
+	"github.com/ale/blueprint/internal/version"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion e a versao do schema do Report.
+const SchemaVersion = 1
+
+// Format e o formato de saida.
+type Format string
+
+const (
+	FormatText Format = "text" // saida para humanos (padrao)
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// ParseFormat valida o valor de --output.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON, FormatYAML:
+		return f, nil
+	default:
+		return "", fmt.Errorf("formato de saida invalido: %q (aceitos: text, json, yaml)", s)
+	}
+}
+
+// Status kinds estaveis do schema (independentes de StatusKind.String,
+// que e texto para humanos).
+var statusNames = map[module.StatusKind]string{
+	module.Installed: "installed",
+	module.Missing:   "missing",
+	module.Partial:   "partial",
+	module.Skipped:   "skipped",
+}
+
+// StatusError e o status de um modulo cujo Check falhou: o estado real e
+// desconhecido e o motivo fica em Module.Error.
+const StatusError = "error"
+
+// Report e o documento emitido por status e apply.
+type Report struct {
+	SchemaVersion int         `json:"schema_version" yaml:"schema_version"`
+	Command       string      `json:"command" yaml:"command"` // "status" ou "apply"
+	Version       string      `json:"version" yaml:"version"` // versao do blueprint
+	Profile       string      `json:"profile" yaml:"profile"`
+	AutoDetected  bool        `json:"auto_detected" yaml:"auto_detected"`
+	Host          string      `json:"host" yaml:"host"`
+	Environment   Environment `json:"environment" yaml:"environment"`
+	DryRun        bool        `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+	ExitCode      int         `json:"exit_code" yaml:"exit_code"` // ver Exit*
+	Modules       []Module    `json:"modules" yaml:"modules"`
+	OutOfProfile  []string    `json:"out_of_profile,omitempty" yaml:"out_of_profile,omitempty"` // modulos registrados fora do perfil
+	LogFile       string      `json:"log_file,omitempty" yaml:"log_file,omitempty"`             // log detalhado da execucao
+
+	// Somente apply: acoes de sessao pendentes (ver SessionActions)
+	Session []SessionAction `json:"session_actions,omitempty" yaml:"session_actions,omitempty"`
+
+	// Somente apply em varias fases: ver Resume
+	Resume *Resume `json:"resume,omitempty" yaml:"resume,omitempty"`
+}
+
+// Resume descreve um apply em varias fases (modulos adiados ate o reboot).
+type Resume struct {
+	Phase    int      `json:"phase" yaml:"phase"`                                     // fase atual (1: primeira execucao)
+	Previous []string `json:"previous_runs,omitempty" yaml:"previous_runs,omitempty"` // IDs no historico das fases anteriores
+	Pending  []string `json:"pending,omitempty" yaml:"pending,omitempty"`             // modulos que ficam para a proxima fase
+}
+
+// SessionAction e uma acao de sessao pendente (reboot, logout, novo
+// terminal) com quem a pediu.
+type SessionAction struct {
+	Action  string          `json:"action" yaml:"action"` // restart_shell, relogin ou reboot
+	Sources []SessionSource `json:"sources" yaml:"sources"`
+}
+
+// SessionSource e um pedido de acao de sessao.
+type SessionSource struct {
+	Module string `json:"module,omitempty" yaml:"module,omitempty"` // vazio: detectado no sistema
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// SessionActions converte as acoes consolidadas por session.Collect.
+func SessionActions(reqs []session.Requirement) []SessionAction {
+	var actions []SessionAction
+	for _, r := range reqs {
+		a := SessionAction{Action: r.Action.Key()}
+		for _, src := range r.Sources {
+			a.Sources = append(a.Sources, SessionSource{Module: src.Module, Reason: src.Reason})
+		}
+		actions = append(actions, a)
+	}
+	return actions
+}
+
+// Environment descreve onde o blueprint rodou.
+type Environment struct {
+	Container bool   `json:"container" yaml:"container"`
+	WSL       bool   `json:"wsl" yaml:"wsl"`
+	Session   string `json:"session,omitempty" yaml:"session,omitempty"` // XDG_SESSION_TYPE
+	Desktop   string `json:"desktop,omitempty" yaml:"desktop,omitempty"` // XDG_CURRENT_DESKTOP
+}
+
+// Module e o resultado de um modulo.
+type Module struct {
+	Name       string   `json:"name" yaml:"name"`
+	Tags       []string `json:"tags" yaml:"tags"`
+	Status     string   `json:"status" yaml:"status"` // installed, missing, partial, skipped ou error (Check falhou)
+	Message    string   `json:"message,omitempty" yaml:"message,omitempty"`
+	SkipReason string   `json:"skip_reason,omitempty" yaml:"skip_reason,omitempty"`
+	Deferred   bool     `json:"deferred,omitempty" yaml:"deferred,omitempty"` // pulado ate o proximo boot (dependencia pediu reboot)
+	Error      string   `json:"error,omitempty" yaml:"error,omitempty"`
+	Cancelled  string   `json:"cancelled,omitempty" yaml:"cancelled,omitempty"` // motivo, se a execucao foi cancelada (Ctrl+C/SIGTERM)
+	TimedOut   bool     `json:"timed_out,omitempty" yaml:"timed_out,omitempty"` // error e um limite de tempo estourado
+
+	// Somente apply
+	Applied      bool     `json:"applied,omitempty" yaml:"applied,omitempty"`
+	After        string   `json:"after,omitempty" yaml:"after,omitempty"` // status re-verificado apos o apply
+	AfterMessage string   `json:"after_message,omitempty" yaml:"after_message,omitempty"`
+	Notes        []string `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Retries      []Retry  `json:"retries,omitempty" yaml:"retries,omitempty"` // tentativas que falharam e foram repetidas
+	DurationSec  float64  `json:"duration_seconds,omitempty" yaml:"duration_seconds,omitempty"`
+}
+
+// Retry e uma tentativa que falhou e foi repetida (module.Attempt).
+type Retry struct {
+	Op      string `json:"op" yaml:"op"`
+	Attempt int    `json:"attempt" yaml:"attempt"`
+	Error   string `json:"error" yaml:"error"`
+}
+
+// New monta o report a partir dos resultados do orquestrador.
+func New(command string, sys module.System, profile string, autoDetected bool, host string, results []orchestrator.Result) Report {
+	r := Report{
+		SchemaVersion: SchemaVersion,
+		Command:       command,
+		Version:       version.Version,
+		Profile:       profile,
+		AutoDetected:  autoDetected,
+		Host:          host,
+		Environment: Environment{
+			Container: sys.IsContainer(),
+			WSL:       sys.IsWSL(),
+			Session:   sys.Env("XDG_SESSION_TYPE"),
+			Desktop:   sys.Env("XDG_CURRENT_DESKTOP"),
+		},
+		Modules: make([]Module, 0, len(results)),
+	}
+	for _, res := range results {
+		r.Modules = append(r.Modules, newModule(res))
+	}
+	return r
+}
+
+func newModule(res orchestrator.Result) Module {
+	m := Module{
+		Name:    res.Module.Name(),
+		Tags:    res.Module.Tags(),
+		Status:  statusNames[res.Status.Kind],
+		Message: res.Status.Message,
+		Applied: res.Applied,
+		Notes:   res.Notes,
+	}
+	if m.Tags == nil {
+		m.Tags = []string{}
+	}
+	if res.Unchecked {
+		m.Status = StatusError
+	}
+	if res.Skipped {
+		m.Status = statusNames[module.Skipped]
+		m.SkipReason = res.Reason
+		m.Deferred = res.Deferred
+	}
+	if res.Cancelled {
+		m.Cancelled = res.Reason
+	}
+	if res.Err != nil {
+		m.Error = res.Err.Error()
+		m.TimedOut = res.TimedOut
+	}
+	if res.After != nil {
+		m.After = statusNames[res.After.Kind]
+		m.AfterMessage = res.After.Message
+	}
+	for _, a := range res.Retries {
+		m.Retries = append(m.Retries, Retry{Op: a.Op, Attempt: a.Number, Error: a.Err.Error()})
+	}
+	if res.Duration > 0 {
+		m.DurationSec = res.Duration.Seconds()
+	}
+	return m
+}
+
+// Write serializa o report no formato pedido.
+func Write(w io.Writer, f Format, r Report) error {
+	switch f {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("formato sem serializacao: %q", f)
+	}
+}
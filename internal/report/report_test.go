@@ -0,0 +1,144 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/session"
+	"github.com/ale/blueprint/internal/system"
+)
+
+type stubModule struct {
+	name string
+	tags []string
+}
+
+func (m stubModule) Name() string        { return m.name }
+func (m stubModule) Description() string { return "" }
+func (m stubModule) Tags() []string      { return m.tags }
+
+func results() []orchestrator.Result {
+	return []orchestrator.Result{
+		{Module: stubModule{"starship", []string{"shell"}}, Status: module.Status{Kind: module.Installed, Message: "ok"}},
+		{Module: stubModule{"devbox", nil}, Status: module.Status{Kind: module.Skipped, Message: "em container"}, Skipped: true, Reason: "em container"},
+		{
+			Module:   stubModule{"cedilla-fix", []string{"desktop"}},
+			Status:   module.Status{Kind: module.Missing},
+			After:    &module.Status{Kind: module.Partial, Message: "falta GTK"},
+			Applied:  true,
+			Err:      fmt.Errorf("falhou"),
+			Duration: 1500 * time.Millisecond,
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": FormatText, "text": FormatText, "JSON": FormatJSON, "yaml": FormatYAML} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("esperava erro com formato invalido")
+	}
+}
+
+func TestNew(t *testing.T) {
+	mock := system.NewMock()
+	mock.Container = true
+	mock.EnvVars["XDG_SESSION_TYPE"] = "wayland"
+
+	r := New("apply", mock, "full", true, "host1", results())
+
+	if r.SchemaVersion != SchemaVersion || r.Command != "apply" || r.Profile != "full" || !r.AutoDetected || r.Host != "host1" {
+		t.Errorf("cabecalho = %+v", r)
+	}
+	if !r.Environment.Container || r.Environment.Session != "wayland" {
+		t.Errorf("Environment = %+v", r.Environment)
+	}
+
+	if m := r.Modules[0]; m.Status != "installed" || m.Message != "ok" {
+		t.Errorf("starship = %+v", m)
+	}
+	if m := r.Modules[1]; m.Status != "skipped" || m.SkipReason != "em container" || m.Tags == nil {
+		t.Errorf("devbox = %+v", m)
+	}
+	if m := r.Modules[2]; m.Status != "missing" || m.Error != "falhou" || m.After != "partial" || m.AfterMessage != "falta GTK" || m.DurationSec != 1.5 {
+		t.Errorf("cedilla-fix = %+v", m)
+	}
+}
+
+func TestNew_CheckError(t *testing.T) {
+	res := []orchestrator.Result{{Module: stubModule{"tiling-shell", nil}, Err: fmt.Errorf("gnome-extensions: exit status 1"), Unchecked: true}}
+
+	m := New("status", system.NewMock(), "full", false, "h", res).Modules[0]
+	if m.Status != StatusError || m.Error != "gnome-extensions: exit status 1" {
+		t.Errorf("Check com erro = %+v; esperava status %q", m, StatusError)
+	}
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, New("status", system.NewMock(), "minimal", false, "h", results())); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("JSON invalido: %v\n%s", err, buf.String())
+	}
+	if doc["schema_version"] != float64(1) || doc["command"] != "status" {
+		t.Errorf("doc = %v", doc)
+	}
+	mods := doc["modules"].([]any)
+	if len(mods) != 3 || mods[1].(map[string]any)["skip_reason"] != "em container" {
+		t.Errorf("modules = %v", mods)
+	}
+}
+
+func TestSessionActions(t *testing.T) {
+	res := []orchestrator.Result{{
+		Module:  stubModule{"cedilla-fix", nil},
+		Session: []module.SessionRequest{{Action: module.SessionRelogin, Reason: "faca logout"}},
+	}}
+	pending := []module.SessionRequest{{Action: module.SessionReboot, Reason: "deployment pendente"}}
+
+	r := New("apply", system.NewMock(), "full", false, "h", res)
+	r.Session = SessionActions(session.Collect(res, pending))
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, r); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Session []SessionAction `json:"session_actions"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Session) != 2 || doc.Session[0].Action != "reboot" || doc.Session[0].Sources[0].Module != "" {
+		t.Fatalf("session_actions = %+v", doc.Session)
+	}
+	if s := doc.Session[1]; s.Action != "relogin" || s.Sources[0].Module != "cedilla-fix" || s.Sources[0].Reason != "faca logout" {
+		t.Errorf("relogin = %+v", s)
+	}
+}
+
+func TestWrite_YAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatYAML, New("status", system.NewMock(), "minimal", false, "h", results())); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"schema_version: 1\n", "profile: minimal\n", "  - name: starship\n", "    status: installed\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("YAML sem %q:\n%s", want, out)
+		}
+	}
+}
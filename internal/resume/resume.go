@@ -0,0 +1,287 @@
+// Package resume continua um apply em varias fases. Quando um modulo pede
+// reboot, os que dependem dele sao adiados (orchestrator.DeferredBy); a fila
+// e gravada no diretorio de estado e um gatilho de uso unico (autostart do
+// desktop ou unit systemd de usuario) roda "blueprint apply --resume" no
+// proximo login.
+package resume
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/schedule"
+)
+
+// stateFile e o nome do estado dentro do diretorio de estado.
+const stateFile = "resume.json"
+
+// Nomes do gatilho de retomada.
+const (
+	AutostartName = "blueprint-resume.desktop"
+	ServiceName   = "blueprint-resume.service"
+)
+
+// State e a fila de modulos que espera o reboot.
+type State struct {
+	Profile   string    `json:"profile"`
+	Modules   []string  `json:"modules"`           // modulos adiados, aplicados na proxima fase
+	Set       []string  `json:"set,omitempty"`     // --set da execucao original (modulo.chave=valor)
+	Phase     int       `json:"phase"`             // numero da proxima fase (2 apos a primeira execucao)
+	Runs      []string  `json:"runs"`              // IDs no historico das fases anteriores
+	BootID    string    `json:"boot_id,omitempty"` // boot em que a fila foi gravada (ver Rebooted)
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BootIDPath identifica o boot atual: o kernel gera um valor novo a cada boot.
+const BootIDPath = "/proc/sys/kernel/random/boot_id"
+
+// BootID retorna o identificador do boot atual ("" se indisponivel).
+func BootID(sys module.System) string {
+	data, err := sys.ReadFile(BootIDPath)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Rebooted retorna true se o sistema reiniciou desde que a fila foi
+// gravada. Um logout/login no mesmo boot nao conta: o deployment pedido pela
+// fase anterior (ex: podman-docker) ainda nao esta ativo. Sem boot_id
+// (gravado ou atual) nao ha como saber, e a retomada e aceita.
+func (s State) Rebooted(sys module.System) bool {
+	current := BootID(sys)
+	return s.BootID == "" || current == "" || current != s.BootID
+}
+
+// Deferred retorna os modulos adiados ate o reboot, na ordem de execucao.
+func Deferred(results []orchestrator.Result) []string {
+	var names []string
+	for _, r := range results {
+		if r.Deferred {
+			names = append(names, r.Module.Name())
+		}
+	}
+	return names
+}
+
+// Next monta o estado da proxima fase a partir da fase atual (prev e nil na
+// primeira execucao). runID e o ID da fase atual no historico (vazio em
+// dry-run).
+func Next(prev *State, profile string, set []string, runID string, deferred []string) State {
+	next := State{Profile: profile, Modules: deferred, Set: set, Phase: 2, CreatedAt: time.Now()}
+	if prev != nil {
+		next.Phase = prev.Phase + 1
+		next.Runs = append(next.Runs, prev.Runs...)
+	}
+	if runID != "" {
+		next.Runs = append(next.Runs, runID)
+	}
+	return next
+}
+
+// Store le e grava o estado da retomada.
+type Store struct {
+	sys  module.System
+	path string
+}
+
+// NewStore cria um Store com o estado em dir.
+func NewStore(sys module.System, dir string) *Store {
+	return &Store{sys: sys, path: filepath.Join(dir, stateFile)}
+}
+
+// Path retorna o caminho do estado.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load le o estado. ok e false se nao ha apply esperando retomada.
+func (s *Store) Load() (state State, ok bool, err error) {
+	if !s.sys.FileExists(s.path) {
+		return State{}, false, nil
+	}
+	data, err := s.sys.ReadFile(s.path)
+	if err != nil {
+		return State{}, false, fmt.Errorf("ler %s: %w", s.path, err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, false, fmt.Errorf("%s: estado invalido: %w", s.path, err)
+	}
+	return state, true, nil
+}
+
+// Save grava o estado.
+func (s *Store) Save(state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serializar estado da retomada: %w", err)
+	}
+	if err := s.sys.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("criar diretorio de estado: %w", err)
+	}
+	if err := s.sys.WriteFile(s.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("gravar %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Clear apaga o estado. Estado inexistente nao e erro.
+func (s *Store) Clear() error {
+	if !s.sys.FileExists(s.path) {
+		return nil
+	}
+	if err := s.sys.Remove(s.path); err != nil {
+		return fmt.Errorf("remover %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Spec descreve o comando executado pelo gatilho.
+type Spec struct {
+	Exe     string // caminho absoluto do binario do blueprint
+	RepoDir string // exportado como BLUEPRINT_DIR (vazio: nao exporta)
+
+	// Graphical usa uma entrada de autostart com terminal, para o apply
+	// retomado abrir no TUI; sem sessao grafica, uma unit systemd de
+	// usuario roda o apply em modo headless (saida no journal).
+	Graphical bool
+}
+
+// AutostartPath retorna o caminho da entrada de autostart
+// ($XDG_CONFIG_HOME/autostart, padrao ~/.config/autostart).
+func AutostartPath(sys module.System) string {
+	dir := sys.Env("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = filepath.Join(sys.HomeDir(), ".config")
+	}
+	return filepath.Join(dir, "autostart", AutostartName)
+}
+
+// ServicePath retorna o caminho da unit systemd de usuario.
+func ServicePath(sys module.System) string {
+	return filepath.Join(schedule.UnitDir(sys), ServiceName)
+}
+
+// command retorna o comando do gatilho (sem BLUEPRINT_DIR).
+func (spec Spec) command() []string {
+	if spec.Graphical {
+		return []string{spec.Exe, "apply", "--resume"}
+	}
+	return []string{spec.Exe, "apply", "--resume", "--headless"}
+}
+
+// Autostart gera a entrada de autostart do desktop.
+func Autostart(spec Spec) string {
+	command := spec.command()
+	if spec.RepoDir != "" {
+		command = append([]string{"env", "BLUEPRINT_DIR=" + spec.RepoDir}, command...)
+	}
+	var exec []string
+	for _, a := range command {
+		exec = append(exec, quoteDesktop(a))
+	}
+
+	return "# Gerado por blueprint apply; removido ao retomar a execucao.\n" +
+		"[Desktop Entry]\n" +
+		"Type=Application\n" +
+		"Name=blueprint: continuar o apply\n" +
+		"Comment=Aplica os modulos que esperavam o reboot\n" +
+		"Exec=" + strings.Join(exec, " ") + "\n" +
+		"Terminal=true\n" +
+		"NoDisplay=true\n" +
+		"X-GNOME-Autostart-enabled=true\n" +
+		"X-GNOME-Autostart-Delay=5\n"
+}
+
+// Service gera a unit systemd de usuario.
+func Service(spec Spec) string {
+	var exec []string
+	for _, a := range spec.command() {
+		exec = append(exec, schedule.Quote(a))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Gerado por blueprint apply; removido ao retomar a execucao.\n")
+	sb.WriteString("[Unit]\n")
+	sb.WriteString("Description=blueprint: continuar o apply apos o reboot\n\n")
+	sb.WriteString("[Service]\n")
+	sb.WriteString("Type=oneshot\n")
+	if spec.RepoDir != "" {
+		fmt.Fprintf(&sb, "Environment=%s\n", schedule.Quote("BLUEPRINT_DIR="+spec.RepoDir))
+	}
+	fmt.Fprintf(&sb, "ExecStart=%s\n\n", strings.Join(exec, " "))
+	sb.WriteString("[Install]\n")
+	sb.WriteString("WantedBy=default.target\n")
+	return sb.String()
+}
+
+// quoteDesktop protege um argumento da chave Exec de um .desktop (aspas
+// duplas quando ha espacos ou caracteres reservados; % vira %%).
+func quoteDesktop(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if s != "" && !strings.ContainsAny(s, " \t\n\"'\\$`<>~|&;*?#()") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// Install grava o gatilho que retoma o apply no proximo login.
+func Install(ctx context.Context, sys module.System, spec Spec) error {
+	if spec.Graphical {
+		path := AutostartPath(sys)
+		if err := sys.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("erro ao criar %s: %w", filepath.Dir(path), err)
+		}
+		if err := sys.WriteFile(path, []byte(Autostart(spec)), 0o644); err != nil {
+			return fmt.Errorf("erro ao escrever %s: %w", AutostartName, err)
+		}
+		return nil
+	}
+
+	path := ServicePath(sys)
+	if err := sys.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("erro ao criar %s: %w", filepath.Dir(path), err)
+	}
+	if err := sys.WriteFile(path, []byte(Service(spec)), 0o644); err != nil {
+		return fmt.Errorf("erro ao escrever %s: %w", ServiceName, err)
+	}
+	if _, err := sys.Exec(ctx, "systemctl", "--user", "daemon-reload"); err != nil {
+		return fmt.Errorf("systemctl --user daemon-reload falhou: %w", err)
+	}
+	if _, err := sys.Exec(ctx, "systemctl", "--user", "enable", ServiceName); err != nil {
+		return fmt.Errorf("erro ao ativar %s: %w", ServiceName, err)
+	}
+	return nil
+}
+
+// Remove apaga o gatilho (o que estiver instalado). Gatilho ausente nao e
+// erro.
+func Remove(ctx context.Context, sys module.System) error {
+	if path := AutostartPath(sys); sys.FileExists(path) {
+		if err := sys.Remove(path); err != nil {
+			return fmt.Errorf("erro ao remover %s: %w", path, err)
+		}
+	}
+
+	path := ServicePath(sys)
+	if !sys.FileExists(path) {
+		return nil
+	}
+	if _, err := sys.Exec(ctx, "systemctl", "--user", "disable", ServiceName); err != nil {
+		return fmt.Errorf("erro ao desativar %s: %w", ServiceName, err)
+	}
+	if err := sys.Remove(path); err != nil {
+		return fmt.Errorf("erro ao remover %s: %w", path, err)
+	}
+	if _, err := sys.Exec(ctx, "systemctl", "--user", "daemon-reload"); err != nil {
+		return fmt.Errorf("systemctl --user daemon-reload falhou: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package resume
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/system"
+)
+
+type stubModule struct{ name string }
+
+func (m stubModule) Name() string        { return m.name }
+func (m stubModule) Description() string { return "" }
+func (m stubModule) Tags() []string      { return nil }
+
+func TestDeferredAndNext(t *testing.T) {
+	results := []orchestrator.Result{
+		{Module: stubModule{"devcontainers"}, Applied: true},
+		{Module: stubModule{"devbox"}, Skipped: true, Deferred: true},
+		{Module: stubModule{"starship"}, Skipped: true},
+	}
+	deferred := Deferred(results)
+	if len(deferred) != 1 || deferred[0] != "devbox" {
+		t.Fatalf("Deferred() = %v", deferred)
+	}
+
+	first := Next(nil, "full", []string{"devbox.image=x"}, "20260101-100000", deferred)
+	if first.Phase != 2 || first.Profile != "full" || len(first.Runs) != 1 || first.Set[0] != "devbox.image=x" {
+		t.Errorf("primeira fase = %+v", first)
+	}
+	second := Next(&first, "full", nil, "20260101-110000", []string{"vscode"})
+	if second.Phase != 3 || strings.Join(second.Runs, ",") != "20260101-100000,20260101-110000" {
+		t.Errorf("segunda fase = %+v", second)
+	}
+}
+
+func TestStore(t *testing.T) {
+	mock := system.NewMock()
+	store := NewStore(mock, "/state")
+
+	if _, ok, err := store.Load(); ok || err != nil {
+		t.Fatalf("sem estado: ok=%v err=%v", ok, err)
+	}
+	if err := store.Save(State{Profile: "full", Modules: []string{"devbox"}, Phase: 2}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	state, ok, err := store.Load()
+	if !ok || err != nil || state.Profile != "full" || state.Modules[0] != "devbox" {
+		t.Errorf("Load() = %+v, %v, %v", state, ok, err)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if mock.FileExists(store.Path()) {
+		t.Error("estado deveria ser apagado")
+	}
+	if err := store.Clear(); err != nil {
+		t.Errorf("Clear sem estado nao deveria falhar: %v", err)
+	}
+}
+
+func TestState_Rebooted(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files[BootIDPath] = []byte("b1\n")
+	state := State{Phase: 2, BootID: BootID(mock)}
+
+	if state.Rebooted(mock) {
+		t.Error("mesmo boot (logout/login) nao e reboot")
+	}
+	mock.Files[BootIDPath] = []byte("b2\n")
+	if !state.Rebooted(mock) {
+		t.Error("boot_id diferente deveria contar como reboot")
+	}
+	if !(State{Phase: 2}).Rebooted(mock) {
+		t.Error("estado sem boot_id (versao anterior) deveria ser aceito")
+	}
+}
+
+func TestStore_Invalid(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/state/resume.json"] = []byte("{")
+	if _, _, err := NewStore(mock, "/state").Load(); err == nil {
+		t.Error("esperava erro com estado invalido")
+	}
+}
+
+func TestAutostart(t *testing.T) {
+	entry := Autostart(Spec{Exe: "/home/test/bin/blueprint", RepoDir: "/home/test/meu blueprint", Graphical: true})
+	for _, want := range []string{
+		"Exec=env \"BLUEPRINT_DIR=/home/test/meu blueprint\" /home/test/bin/blueprint apply --resume\n",
+		"Terminal=true\n",
+	} {
+		if !strings.Contains(entry, want) {
+			t.Errorf("autostart sem %q:\n%s", want, entry)
+		}
+	}
+}
+
+func TestInstallAndRemove_Graphical(t *testing.T) {
+	mock := system.NewMock()
+	if err := Install(context.Background(), mock, Spec{Exe: "/bin/blueprint", Graphical: true}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if _, ok := mock.Files["/home/test/.config/autostart/"+AutostartName]; !ok {
+		t.Error("autostart nao escrito")
+	}
+	if len(mock.ExecLog) != 0 {
+		t.Errorf("autostart nao deveria executar comandos: %v", mock.ExecLog)
+	}
+
+	if err := Remove(context.Background(), mock); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(mock.Files) != 0 {
+		t.Errorf("autostart deveria ser removido: %v", mock.Files)
+	}
+}
+
+func TestInstallAndRemove_Headless(t *testing.T) {
+	mock := system.NewMock()
+	if err := Install(context.Background(), mock, Spec{Exe: "/bin/blueprint"}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	service := string(mock.Files["/home/test/.config/systemd/user/"+ServiceName])
+	if !strings.Contains(service, "ExecStart=/bin/blueprint apply --resume --headless\n") || !strings.Contains(service, "WantedBy=default.target") {
+		t.Errorf("service inesperado:\n%s", service)
+	}
+	if got := strings.Join(mock.ExecLog, "|"); got != "systemctl --user daemon-reload|systemctl --user enable "+ServiceName {
+		t.Errorf("ExecLog = %v", mock.ExecLog)
+	}
+
+	mock.ExecLog = nil
+	if err := Remove(context.Background(), mock); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(mock.Files) != 0 || mock.ExecLog[0] != "systemctl --user disable "+ServiceName {
+		t.Errorf("service deveria ser desativado e removido: %v / %v", mock.Files, mock.ExecLog)
+	}
+}
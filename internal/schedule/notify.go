@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ale/blueprint/internal/module"
+)
+
+// ActionApply e o identificador da acao "Reaplicar" na notificacao.
+const ActionApply = "apply"
+
+// Notification e uma notificacao de desktop com uma acao opcional.
+type Notification struct {
+	Title       string
+	Body        string
+	ActionLabel string // rotulo do botao (vazio: sem acao)
+}
+
+// Notify envia a notificacao e, se houver acao, espera o usuario responder
+// (o notify-send com --action so retorna quando a notificacao e fechada).
+// Retorna true se a acao foi escolhida. Sem notify-send, usa o D-Bus direto
+// (gdbus), sem suporte a acao. Quem roda dentro do service oneshot do timer
+// usa Detach para nao prender a unit.
+func Notify(ctx context.Context, sys module.System, n Notification) (bool, error) {
+	if sys.CommandExists("notify-send") {
+		args := []string{"--app-name=blueprint", "--icon=preferences-system", "--urgency=normal"}
+		if n.ActionLabel != "" {
+			args = append(args, "--action="+ActionApply+"="+n.ActionLabel)
+		}
+		args = append(args, n.Title, n.Body)
+		out, err := sys.Exec(ctx, "notify-send", args...)
+		if err != nil {
+			return false, fmt.Errorf("notify-send falhou: %w", err)
+		}
+		return strings.TrimSpace(out) == ActionApply, nil
+	}
+
+	if sys.CommandExists("gdbus") {
+		_, err := sys.Exec(ctx, "gdbus", "call", "--session",
+			"--dest", "org.freedesktop.Notifications",
+			"--object-path", "/org/freedesktop/Notifications",
+			"--method", "org.freedesktop.Notifications.Notify",
+			"blueprint", "0", "preferences-system", n.Title, n.Body, "[]", "{}", "-1")
+		if err != nil {
+			return false, fmt.Errorf("notificacao via D-Bus falhou: %w", err)
+		}
+		return false, nil
+	}
+
+	return false, fmt.Errorf("nem notify-send nem gdbus disponiveis")
+}
+
+// NotifyUnit e a unit transitoria que mostra a notificacao com acao.
+const NotifyUnit = "blueprint-drift-notify.service"
+
+// Detach executa command (ex: blueprint schedule notify) numa unit
+// transitoria do systemd de usuario, sem esperar: o service oneshot do timer
+// termina na hora, em vez de ficar ativo ate o usuario fechar a notificacao
+// (o que faria o timer pular as proximas execucoes). Com a notificacao
+// anterior ainda aberta, a unit ja existe e o systemd-run falha.
+func Detach(ctx context.Context, sys module.System, command []string) error {
+	if !sys.CommandExists("systemd-run") {
+		return fmt.Errorf("systemd-run nao encontrado")
+	}
+	args := []string{"--user", "--collect", "--quiet", "--unit=" + NotifyUnit}
+	if dir := sys.Env("BLUEPRINT_DIR"); dir != "" {
+		args = append(args, "--setenv=BLUEPRINT_DIR="+dir)
+	}
+	args = append(append(args, "--"), command...)
+	if out, err := sys.Exec(ctx, "systemd-run", args...); err != nil {
+		return fmt.Errorf("erro ao iniciar a notificacao: %s: %w", strings.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// terminals sao os emuladores tentados, em ordem, para abrir o apply.
+// Cada entrada e o comando e os argumentos que precedem o comando a executar.
+var terminals = [][]string{
+	{"xdg-terminal-exec"},
+	{"ptyxis", "--"},
+	{"kgx", "--"},
+	{"gnome-terminal", "--"},
+	{"konsole", "-e"},
+	{"xterm", "-e"},
+}
+
+// OpenTerminal abre um terminal executando command (ex: blueprint apply).
+func OpenTerminal(ctx context.Context, sys module.System, command []string) error {
+	for _, t := range terminals {
+		if !sys.CommandExists(t[0]) {
+			continue
+		}
+		args := append(append([]string{}, t[1:]...), command...)
+		if _, err := sys.Exec(ctx, t[0], args...); err != nil {
+			return fmt.Errorf("erro ao abrir %s: %w", t[0], err)
+		}
+		return nil
+	}
+	return fmt.Errorf("nenhum terminal encontrado; rode manualmente: %s", strings.Join(command, " "))
+}
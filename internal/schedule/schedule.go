@@ -0,0 +1,184 @@
+// Package schedule instala um timer systemd de usuario que roda
+// "blueprint schedule run" periodicamente. A verificacao compara o estado
+// atual com o historico e, se alguma configuracao se desfez (drift),
+// envia uma notificacao de desktop com a acao de reaplicar os modulos.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ale/blueprint/internal/history"
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+)
+
+// Nomes das units systemd.
+const (
+	ServiceName = "blueprint-drift.service"
+	TimerName   = "blueprint-drift.timer"
+)
+
+// DefaultOnCalendar e a frequencia padrao da verificacao (sintaxe OnCalendar
+// do systemd).
+const DefaultOnCalendar = "daily"
+
+// Spec descreve o que o timer executa.
+type Spec struct {
+	Exe        string   // caminho absoluto do binario do blueprint
+	Args       []string // argumentos (ex: ["schedule", "run", "-p", "full"])
+	RepoDir    string   // exportado como BLUEPRINT_DIR (vazio: nao exporta)
+	OnCalendar string   // frequencia (padrao: DefaultOnCalendar)
+}
+
+// UnitDir retorna o diretorio de units de usuario
+// ($XDG_CONFIG_HOME/systemd/user, padrao ~/.config/systemd/user).
+func UnitDir(sys module.System) string {
+	if dir := sys.Env("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "systemd", "user")
+	}
+	return filepath.Join(sys.HomeDir(), ".config", "systemd", "user")
+}
+
+// Units gera o conteudo do service e do timer.
+func Units(spec Spec) (service, timer string) {
+	onCalendar := spec.OnCalendar
+	if onCalendar == "" {
+		onCalendar = DefaultOnCalendar
+	}
+
+	var exec []string
+	for _, a := range append([]string{spec.Exe}, spec.Args...) {
+		exec = append(exec, Quote(a))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Gerado por blueprint schedule install; remova com blueprint schedule remove.\n")
+	sb.WriteString("[Unit]\n")
+	sb.WriteString("Description=blueprint: verificacao de drift da configuracao\n")
+	sb.WriteString("After=graphical-session.target\n\n")
+	sb.WriteString("[Service]\n")
+	sb.WriteString("Type=oneshot\n")
+	if spec.RepoDir != "" {
+		fmt.Fprintf(&sb, "Environment=%s\n", Quote("BLUEPRINT_DIR="+spec.RepoDir))
+	}
+	fmt.Fprintf(&sb, "ExecStart=%s\n", strings.Join(exec, " "))
+	service = sb.String()
+
+	timer = "# Gerado por blueprint schedule install; remova com blueprint schedule remove.\n" +
+		"[Unit]\n" +
+		"Description=blueprint: verificacao periodica de drift\n\n" +
+		"[Timer]\n" +
+		"OnCalendar=" + onCalendar + "\n" +
+		"Persistent=true\n" +
+		"RandomizedDelaySec=15min\n\n" +
+		"[Install]\n" +
+		"WantedBy=timers.target\n"
+	return service, timer
+}
+
+// Quote protege um argumento para ExecStart/Environment (aspas duplas
+// quando ha espacos, aspas, barras invertidas ou variaveis).
+func Quote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$%;") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `$$`, `%`, `%%`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// Install escreve as units e ativa o timer.
+func Install(ctx context.Context, sys module.System, spec Spec) error {
+	dir := UnitDir(sys)
+	service, timer := Units(spec)
+
+	if err := sys.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("erro ao criar %s: %w", dir, err)
+	}
+	if err := sys.WriteFile(filepath.Join(dir, ServiceName), []byte(service), 0o644); err != nil {
+		return fmt.Errorf("erro ao escrever %s: %w", ServiceName, err)
+	}
+	if err := sys.WriteFile(filepath.Join(dir, TimerName), []byte(timer), 0o644); err != nil {
+		return fmt.Errorf("erro ao escrever %s: %w", TimerName, err)
+	}
+	if _, err := sys.Exec(ctx, "systemctl", "--user", "daemon-reload"); err != nil {
+		return fmt.Errorf("systemctl --user daemon-reload falhou: %w", err)
+	}
+	if _, err := sys.Exec(ctx, "systemctl", "--user", "enable", "--now", TimerName); err != nil {
+		return fmt.Errorf("erro ao ativar %s: %w", TimerName, err)
+	}
+	return nil
+}
+
+// Remove desativa o timer e apaga as units. Units ausentes nao sao erro.
+func Remove(ctx context.Context, sys module.System) error {
+	dir := UnitDir(sys)
+	timerPath := filepath.Join(dir, TimerName)
+	servicePath := filepath.Join(dir, ServiceName)
+
+	if sys.FileExists(timerPath) {
+		if _, err := sys.Exec(ctx, "systemctl", "--user", "disable", "--now", TimerName); err != nil {
+			return fmt.Errorf("erro ao desativar %s: %w", TimerName, err)
+		}
+	}
+	for _, path := range []string{timerPath, servicePath} {
+		if !sys.FileExists(path) {
+			continue
+		}
+		if err := sys.Remove(path); err != nil {
+			return fmt.Errorf("erro ao remover %s: %w", path, err)
+		}
+	}
+	if _, err := sys.Exec(ctx, "systemctl", "--user", "daemon-reload"); err != nil {
+		return fmt.Errorf("systemctl --user daemon-reload falhou: %w", err)
+	}
+	return nil
+}
+
+// Drifted retorna os modulos cuja configuracao se desfez: parciais, ou
+// ausentes que o historico registra como instalados na ultima execucao em
+// que apareceram. Modulos nunca aplicados nao contam, para nao notificar
+// todo dia sobre algo que o usuario nunca quis.
+func Drifted(results []orchestrator.Result, runs []history.Run) []orchestrator.Result {
+	known := lastKnown(runs)
+	installed := module.Installed.String()
+
+	var drifted []orchestrator.Result
+	for _, r := range results {
+		if r.Skipped || r.Cancelled || r.Err != nil {
+			continue
+		}
+		switch r.Status.Kind {
+		case module.Partial:
+			drifted = append(drifted, r)
+		case module.Missing:
+			if known[r.Module.Name()] == installed {
+				drifted = append(drifted, r)
+			}
+		}
+	}
+	return drifted
+}
+
+// lastKnown retorna o ultimo estado registrado de cada modulo (o estado
+// apos o apply, ou o encontrado antes se o modulo nao foi re-verificado).
+func lastKnown(runs []history.Run) map[string]string {
+	sorted := append([]history.Run(nil), runs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartedAt.Before(sorted[j].StartedAt) })
+
+	known := make(map[string]string)
+	for _, run := range sorted {
+		for _, e := range run.Entries {
+			switch {
+			case e.After != nil:
+				known[e.Module] = e.After.Kind
+			case e.Before != nil:
+				known[e.Module] = e.Before.Kind
+			}
+		}
+	}
+	return known
+}
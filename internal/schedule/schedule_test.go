@@ -0,0 +1,178 @@
+package schedule
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ale/blueprint/internal/history"
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/system"
+)
+
+type stubModule struct{ name string }
+
+func (m stubModule) Name() string        { return m.name }
+func (m stubModule) Description() string { return "" }
+func (m stubModule) Tags() []string      { return nil }
+
+func TestUnits(t *testing.T) {
+	service, timer := Units(Spec{
+		Exe:     "/home/test/blueprint/bin/blueprint",
+		Args:    []string{"schedule", "run", "--profile", "full"},
+		RepoDir: "/home/test/meu blueprint",
+	})
+
+	for _, want := range []string{
+		"Type=oneshot\n",
+		"Environment=\"BLUEPRINT_DIR=/home/test/meu blueprint\"\n",
+		"ExecStart=/home/test/blueprint/bin/blueprint schedule run --profile full\n",
+	} {
+		if !strings.Contains(service, want) {
+			t.Errorf("service sem %q:\n%s", want, service)
+		}
+	}
+	if !strings.Contains(timer, "OnCalendar=daily\n") || !strings.Contains(timer, "WantedBy=timers.target\n") {
+		t.Errorf("timer inesperado:\n%s", timer)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"simples":    "simples",
+		"com espaco": `"com espaco"`,
+		`a"b`:        `"a\"b"`,
+		"50%":        `"50%%"`,
+		"$HOME":      `"$$HOME"`,
+	}
+	for in, want := range tests {
+		if got := Quote(in); got != want {
+			t.Errorf("Quote(%q) = %s, esperava %s", in, got, want)
+		}
+	}
+}
+
+func TestInstallAndRemove(t *testing.T) {
+	mock := system.NewMock()
+	mock.EnvVars["XDG_CONFIG_HOME"] = "/home/test/.config"
+
+	if err := Install(context.Background(), mock, Spec{Exe: "/bin/blueprint", Args: []string{"schedule", "run"}, OnCalendar: "hourly"}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	timer := string(mock.Files["/home/test/.config/systemd/user/"+TimerName])
+	if !strings.Contains(timer, "OnCalendar=hourly") {
+		t.Errorf("timer = %q", timer)
+	}
+	if _, ok := mock.Files["/home/test/.config/systemd/user/"+ServiceName]; !ok {
+		t.Error("service nao escrito")
+	}
+	if got := strings.Join(mock.ExecLog, "|"); got != "systemctl --user daemon-reload|systemctl --user enable --now "+TimerName {
+		t.Errorf("ExecLog = %v", mock.ExecLog)
+	}
+
+	mock.ExecLog = nil
+	if err := Remove(context.Background(), mock); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(mock.Files) != 0 {
+		t.Errorf("units deveriam ser removidas: %v", mock.Files)
+	}
+	if mock.ExecLog[0] != "systemctl --user disable --now "+TimerName {
+		t.Errorf("ExecLog = %v", mock.ExecLog)
+	}
+}
+
+func TestDrifted(t *testing.T) {
+	at := func(kind module.StatusKind, name string) orchestrator.Result {
+		return orchestrator.Result{Module: stubModule{name}, Status: module.Status{Kind: kind}}
+	}
+	installed := &history.Status{Kind: module.Installed.String()}
+	missing := &history.Status{Kind: module.Missing.String()}
+	now := time.Now()
+	runs := []history.Run{
+		// Fora de ordem: a execucao mais recente vale
+		{StartedAt: now, Entries: []history.Entry{{Module: "removido", After: missing}}},
+		{StartedAt: now.Add(-time.Hour), Entries: []history.Entry{
+			{Module: "tiling-shell", After: installed},
+			{Module: "removido", After: installed},
+		}},
+	}
+
+	results := []orchestrator.Result{
+		at(module.Missing, "tiling-shell"), // instalado antes: drift
+		at(module.Missing, "nunca-aplicado"),
+		at(module.Missing, "removido"), // removido via blueprint remove
+		at(module.Partial, "cedilla-fix"),
+		at(module.Installed, "starship"),
+		{Module: stubModule{"devbox"}, Status: module.Status{Kind: module.Skipped}, Skipped: true},
+	}
+
+	var names []string
+	for _, r := range Drifted(results, runs) {
+		names = append(names, r.Module.Name())
+	}
+	if got := strings.Join(names, ","); got != "tiling-shell,cedilla-fix" {
+		t.Errorf("Drifted() = %s", got)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	mock := system.NewMock()
+	mock.Commands["notify-send"] = true
+	cmd := "notify-send --app-name=blueprint --icon=preferences-system --urgency=normal --action=apply=Reaplicar titulo corpo"
+	mock.ExecResults[cmd] = system.ExecResult{Output: "apply\n"}
+
+	clicked, err := Notify(context.Background(), mock, Notification{Title: "titulo", Body: "corpo", ActionLabel: "Reaplicar"})
+	if err != nil || !clicked {
+		t.Errorf("Notify() = %v, %v; ExecLog = %v", clicked, err, mock.ExecLog)
+	}
+
+	// Sem notify-send: D-Bus, sem acao
+	mock = system.NewMock()
+	mock.Commands["gdbus"] = true
+	clicked, err = Notify(context.Background(), mock, Notification{Title: "t", Body: "b", ActionLabel: "Reaplicar"})
+	if err != nil || clicked || !strings.HasPrefix(mock.ExecLog[0], "gdbus call --session") {
+		t.Errorf("Notify() = %v, %v; ExecLog = %v", clicked, err, mock.ExecLog)
+	}
+
+	if _, err := Notify(context.Background(), system.NewMock(), Notification{}); err == nil {
+		t.Error("esperava erro sem notify-send e gdbus")
+	}
+}
+
+func TestDetach(t *testing.T) {
+	mock := system.NewMock()
+	mock.Commands["systemd-run"] = true
+	mock.EnvVars["BLUEPRINT_DIR"] = "/home/ale/blueprint"
+
+	if err := Detach(context.Background(), mock, []string{"/bin/blueprint", "schedule", "notify"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "systemd-run --user --collect --quiet --unit=blueprint-drift-notify.service --setenv=BLUEPRINT_DIR=/home/ale/blueprint -- /bin/blueprint schedule notify"
+	if len(mock.ExecLog) != 1 || mock.ExecLog[0] != want {
+		t.Errorf("ExecLog = %v", mock.ExecLog)
+	}
+
+	if err := Detach(context.Background(), system.NewMock(), []string{"blueprint"}); err == nil {
+		t.Error("esperava erro sem systemd-run")
+	}
+}
+
+func TestOpenTerminal(t *testing.T) {
+	mock := system.NewMock()
+	mock.Commands["gnome-terminal"] = true
+	mock.Commands["xterm"] = true
+
+	if err := OpenTerminal(context.Background(), mock, []string{"blueprint", "apply"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(mock.ExecLog) != 1 || mock.ExecLog[0] != "gnome-terminal -- blueprint apply" {
+		t.Errorf("ExecLog = %v", mock.ExecLog)
+	}
+
+	if err := OpenTerminal(context.Background(), system.NewMock(), []string{"blueprint"}); err == nil {
+		t.Error("esperava erro sem terminal")
+	}
+}
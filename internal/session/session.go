@@ -0,0 +1,144 @@
+// Package session consolida as acoes de sessao (novo terminal, logout/login,
+// reboot) pedidas pelos modulos, detecta um deployment rpm-ostree pendente e
+// executa o logout ou o reboot pedido por --logout/--reboot.
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+)
+
+// Source e quem pediu uma acao de sessao e por que.
+type Source struct {
+	Module string // vazio: detectado no sistema (ex: deployment rpm-ostree)
+	Reason string
+}
+
+// String descreve o pedido como exibido no resumo ("modulo: motivo").
+func (s Source) String() string {
+	if s.Module == "" {
+		return "sistema: " + s.Reason
+	}
+	return s.Module + ": " + s.Reason
+}
+
+// Requirement e uma acao de sessao pendente com todos os pedidos que a geraram.
+type Requirement struct {
+	Action  module.SessionAction
+	Sources []Source
+}
+
+// ReasonPendingDeployment e o motivo registrado quando o rpm-ostree tem um
+// deployment novo esperando o reboot.
+const ReasonPendingDeployment = "deployment rpm-ostree pendente (aplicado no proximo boot)"
+
+// Pending detecta acoes de sessao pendentes fora dos modulos: hoje, um
+// deployment rpm-ostree que so entra em vigor no proximo boot (o primeiro
+// deployment da lista nao e o que esta rodando).
+func Pending(ctx context.Context, sys module.System) []module.SessionRequest {
+	if !sys.CommandExists("rpm-ostree") {
+		return nil
+	}
+	out, err := sys.Exec(ctx, "rpm-ostree", "status", "--json")
+	if err != nil {
+		return nil
+	}
+	var status struct {
+		Deployments []struct {
+			Booted bool `json:"booted"`
+		} `json:"deployments"`
+	}
+	if err := json.Unmarshal([]byte(out), &status); err != nil || len(status.Deployments) == 0 {
+		return nil
+	}
+	if status.Deployments[0].Booted {
+		return nil
+	}
+	return []module.SessionRequest{{Action: module.SessionReboot, Reason: ReasonPendingDeployment}}
+}
+
+// Collect agrupa as acoes pedidas pelos modulos (Result.Session) e as
+// pendentes do sistema (ver Pending), da mais para a menos abrangente.
+func Collect(results []orchestrator.Result, pending []module.SessionRequest) []Requirement {
+	byAction := make(map[module.SessionAction]*Requirement)
+	add := func(req module.SessionRequest, src Source) {
+		r, ok := byAction[req.Action]
+		if !ok {
+			r = &Requirement{Action: req.Action}
+			byAction[req.Action] = r
+		}
+		r.Sources = append(r.Sources, src)
+	}
+	for _, res := range results {
+		for _, req := range res.Session {
+			add(req, Source{Module: res.Module.Name(), Reason: req.Reason})
+		}
+	}
+	for _, req := range pending {
+		add(req, Source{Reason: req.Reason})
+	}
+
+	reqs := make([]Requirement, 0, len(byAction))
+	for _, r := range byAction {
+		reqs = append(reqs, *r)
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Action > reqs[j].Action })
+	return reqs
+}
+
+// Strongest retorna a acao mais abrangente pedida (zero se nenhuma).
+func Strongest(reqs []Requirement) module.SessionAction {
+	var strongest module.SessionAction
+	for _, r := range reqs {
+		strongest = max(strongest, r.Action)
+	}
+	return strongest
+}
+
+// Choose decide o que executar ao final com --reboot/--logout: reboot so se
+// algum modulo ou o sistema pediu reboot; logout se pediram logout ou algo
+// mais abrangente. Retorna zero se nada deve ser executado.
+func Choose(reqs []Requirement, reboot, logout bool) module.SessionAction {
+	strongest := Strongest(reqs)
+	switch {
+	case reboot && strongest >= module.SessionReboot:
+		return module.SessionReboot
+	case logout && strongest >= module.SessionRelogin:
+		return module.SessionRelogin
+	default:
+		return 0
+	}
+}
+
+// Perform executa o reboot ou o logout da sessao atual.
+func Perform(ctx context.Context, sys module.System, action module.SessionAction) error {
+	switch action {
+	case module.SessionReboot:
+		if _, err := sys.Exec(ctx, "systemctl", "reboot"); err != nil {
+			return fmt.Errorf("systemctl reboot falhou: %w", err)
+		}
+		return nil
+	case module.SessionRelogin:
+		if sys.CommandExists("gnome-session-quit") {
+			if _, err := sys.Exec(ctx, "gnome-session-quit", "--logout", "--no-prompt"); err != nil {
+				return fmt.Errorf("gnome-session-quit falhou: %w", err)
+			}
+			return nil
+		}
+		id := sys.Env("XDG_SESSION_ID")
+		if id == "" {
+			return fmt.Errorf("sessao atual desconhecida (sem XDG_SESSION_ID); faca logout manualmente")
+		}
+		if _, err := sys.Exec(ctx, "loginctl", "terminate-session", id); err != nil {
+			return fmt.Errorf("loginctl terminate-session falhou: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("acao de sessao nao executavel: %s", action)
+	}
+}
@@ -0,0 +1,146 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/system"
+)
+
+type stubModule struct{ name string }
+
+func (m stubModule) Name() string        { return m.name }
+func (m stubModule) Description() string { return "" }
+func (m stubModule) Tags() []string      { return nil }
+
+func TestPending(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		pending bool
+	}{
+		{"deployment novo", `{"deployments": [{"booted": false}, {"booted": true}]}`, nil, true},
+		{"rodando o mais novo", `{"deployments": [{"booted": true}, {"booted": false}]}`, nil, false},
+		{"erro", "", fmt.Errorf("exit 1"), false},
+		{"json invalido", "nope", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := system.NewMock()
+			mock.Commands["rpm-ostree"] = true
+			mock.ExecResults["rpm-ostree status --json"] = system.ExecResult{Output: tt.output, Err: tt.err}
+
+			got := Pending(context.Background(), mock)
+			if (len(got) == 1) != tt.pending {
+				t.Fatalf("Pending() = %+v, esperava pendente=%v", got, tt.pending)
+			}
+			if tt.pending && got[0].Action != module.SessionReboot {
+				t.Errorf("acao = %v, esperava reboot", got[0].Action)
+			}
+		})
+	}
+}
+
+func TestPending_WithoutRpmOstree(t *testing.T) {
+	mock := system.NewMock()
+	if got := Pending(context.Background(), mock); got != nil {
+		t.Errorf("sem rpm-ostree nao ha pendencia: %+v", got)
+	}
+	if len(mock.ExecLog) != 0 {
+		t.Errorf("nao deveria executar comandos: %v", mock.ExecLog)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	results := []orchestrator.Result{
+		{Module: stubModule{"cedilla"}, Session: []module.SessionRequest{{Action: module.SessionRelogin, Reason: "logout"}}},
+		{Module: stubModule{"starship"}, Session: []module.SessionRequest{{Action: module.SessionRestartShell, Reason: "terminal"}}},
+		{Module: stubModule{"tiling-shell"}, Session: []module.SessionRequest{{Action: module.SessionRelogin, Reason: "re-login"}}},
+		{Module: stubModule{"devbox"}},
+	}
+	pending := []module.SessionRequest{{Action: module.SessionReboot, Reason: ReasonPendingDeployment}}
+
+	reqs := Collect(results, pending)
+	if len(reqs) != 3 {
+		t.Fatalf("esperava 3 acoes, obteve %+v", reqs)
+	}
+	if reqs[0].Action != module.SessionReboot || reqs[0].Sources[0].Module != "" {
+		t.Errorf("reboot do sistema deveria vir primeiro: %+v", reqs[0])
+	}
+	if reqs[1].Action != module.SessionRelogin || len(reqs[1].Sources) != 2 {
+		t.Errorf("logout deveria agrupar cedilla e tiling-shell: %+v", reqs[1])
+	}
+	if reqs[2].Action != module.SessionRestartShell || reqs[2].Sources[0].Module != "starship" {
+		t.Errorf("novo terminal deveria vir por ultimo: %+v", reqs[2])
+	}
+	if Strongest(reqs) != module.SessionReboot {
+		t.Errorf("Strongest = %v", Strongest(reqs))
+	}
+	if Collect(nil, nil) == nil || len(Collect(nil, nil)) != 0 {
+		t.Error("sem pedidos, Collect deveria retornar lista vazia")
+	}
+}
+
+func TestChoose(t *testing.T) {
+	relogin := []Requirement{{Action: module.SessionRelogin}}
+	reboot := []Requirement{{Action: module.SessionReboot}, {Action: module.SessionRelogin}}
+	shell := []Requirement{{Action: module.SessionRestartShell}}
+
+	tests := []struct {
+		name           string
+		reqs           []Requirement
+		reboot, logout bool
+		want           module.SessionAction
+	}{
+		{"sem flags", reboot, false, false, 0},
+		{"reboot pedido", reboot, true, false, module.SessionReboot},
+		{"reboot sem pedido", relogin, true, false, 0},
+		{"logout pedido", relogin, false, true, module.SessionRelogin},
+		{"logout com reboot pendente", reboot, false, true, module.SessionRelogin},
+		{"ambos com reboot pendente", reboot, true, true, module.SessionReboot},
+		{"so novo terminal", shell, true, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Choose(tt.reqs, tt.reboot, tt.logout); got != tt.want {
+				t.Errorf("Choose() = %v, esperava %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPerform(t *testing.T) {
+	mock := system.NewMock()
+	if err := Perform(context.Background(), mock, module.SessionReboot); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	mock.Commands["gnome-session-quit"] = true
+	if err := Perform(context.Background(), mock, module.SessionRelogin); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	expected := []string{"systemctl reboot", "gnome-session-quit --logout --no-prompt"}
+	if len(mock.ExecLog) != 2 || mock.ExecLog[0] != expected[0] || mock.ExecLog[1] != expected[1] {
+		t.Errorf("comandos = %v, esperava %v", mock.ExecLog, expected)
+	}
+}
+
+func TestPerform_LogoutViaLoginctl(t *testing.T) {
+	mock := system.NewMock()
+	if err := Perform(context.Background(), mock, module.SessionRelogin); err == nil {
+		t.Error("sem gnome-session-quit nem XDG_SESSION_ID deveria falhar")
+	}
+
+	mock.EnvVars["XDG_SESSION_ID"] = "3"
+	if err := Perform(context.Background(), mock, module.SessionRelogin); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(mock.ExecLog) != 1 || mock.ExecLog[0] != "loginctl terminate-session 3" {
+		t.Errorf("comandos = %v", mock.ExecLog)
+	}
+}
@@ -0,0 +1,142 @@
+// Package sysfile instala arquivos de sistema (sudoers, regras udev,
+// configuracao do GDM) de forma segura, compartilhado pelos modulos que
+// escrevem em /etc. O conteudo nunca passa por um nome fixo no home: vai
+// para um arquivo oculto no proprio diretorio de destino, ja com dono e modo
+// finais (System.WriteFilePrivileged parte de um temporario privado), e
+// validado como root; so entao substitui o destino com um rename atomico e o
+// contexto SELinux e restaurado.
+package sysfile
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/system"
+)
+
+// File descreve um arquivo de sistema.
+type File struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+	Owner   string // "usuario:grupo" (vazio: root:root)
+
+	// Validate confere o arquivo preparado antes de ele substituir o
+	// destino (ex: Visudo, UdevVerify). Nil nao valida.
+	Validate Validator
+}
+
+// Validator valida o arquivo preparado em path, ja com o conteudo, o modo e
+// o dono finais. Um erro cancela a instalacao.
+type Validator func(ctx context.Context, sys module.System, path string) error
+
+// Visudo valida um sudoers (visudo -c -f): um arquivo invalido em
+// /etc/sudoers.d quebra o sudo do sistema inteiro.
+func Visudo(ctx context.Context, sys module.System, path string) error {
+	return run(ctx, sys, "visudo", "-c", "-f", path)
+}
+
+// UdevVerify valida regras udev (udevadm verify). O subcomando so existe a
+// partir do systemd 254; em versoes anteriores a validacao e pulada.
+func UdevVerify(ctx context.Context, sys module.System, path string) error {
+	if _, err := sys.Exec(ctx, "udevadm", "verify", "--help"); err != nil {
+		return nil
+	}
+	return run(ctx, sys, "udevadm", "verify", path)
+}
+
+// run executa um validador como root, com a saida no erro.
+func run(ctx context.Context, sys module.System, name string, args ...string) error {
+	out, err := sys.ExecPrivileged(ctx, name, args...)
+	if err == nil {
+		return nil
+	}
+	if out = strings.TrimSpace(out); out != "" {
+		return fmt.Errorf("%s: %w: %s", name, err, out)
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+// StagedPath retorna onde o arquivo e preparado antes do rename: um nome
+// oculto no mesmo diretorio (mesmo sistema de arquivos, para o rename ser
+// atomico), ignorado pelo sudo (nomes com ".") e pelo udev (ocultos).
+func StagedPath(path string) string {
+	return filepath.Join(filepath.Dir(path), ".blueprint-"+filepath.Base(path))
+}
+
+// Install instala f e retorna false, sem escrever nada, se o destino ja tem
+// o conteudo, o modo e o dono pedidos.
+func Install(ctx context.Context, sys module.System, f File) (changed bool, err error) {
+	if Unchanged(ctx, sys, f) {
+		return false, nil
+	}
+
+	staged := StagedPath(f.Path)
+	if err := sys.WriteFilePrivileged(ctx, staged, f.Content, f.Mode, f.Owner); err != nil {
+		return false, fmt.Errorf("erro ao preparar %s: %w", f.Path, err)
+	}
+	if f.Validate != nil {
+		if err := f.Validate(ctx, sys, staged); err != nil {
+			_, _ = sys.ExecPrivileged(ctx, "rm", "-f", staged)
+			return false, fmt.Errorf("validacao de %s falhou: %w", f.Path, err)
+		}
+	}
+	if out, err := sys.ExecPrivileged(ctx, "mv", "-f", staged, f.Path); err != nil {
+		_, _ = sys.ExecPrivileged(ctx, "rm", "-f", staged)
+		return false, fmt.Errorf("erro ao instalar %s: %s: %w", f.Path, strings.TrimSpace(out), err)
+	}
+
+	// Fedora/Bluefin: o arquivo novo herda o contexto SELinux do diretorio;
+	// restorecon aplica o que a politica define para o caminho final
+	if sys.CommandExists("restorecon") {
+		if err := run(ctx, sys, "restorecon", f.Path); err != nil {
+			return true, fmt.Errorf("contexto SELinux de %s: %w", f.Path, err)
+		}
+	}
+	return true, nil
+}
+
+// Unchanged retorna true se o destino ja tem o conteudo, o modo e o dono de
+// f. Arquivos que o usuario nao le (ex: /etc/sudoers.d) sao lidos como root.
+func Unchanged(ctx context.Context, sys module.System, f File) bool {
+	if !sameContent(ctx, sys, f) {
+		return false
+	}
+	user, group := system.FileOwner(f.Owner)
+	out, err := sys.ExecPrivileged(ctx, "stat", "-c", "%a %U:%G", f.Path)
+	return err == nil && strings.TrimSpace(out) == fmt.Sprintf("%o %s:%s", f.Mode.Perm(), user, group)
+}
+
+// sameContent compara o destino com f.Content.
+func sameContent(ctx context.Context, sys module.System, f File) bool {
+	data, err := sys.ReadFile(f.Path)
+	if err == nil {
+		return bytes.Equal(data, f.Content)
+	}
+	if !errors.Is(err, fs.ErrPermission) {
+		return false
+	}
+	// A saida de Exec vem sem espacos nas pontas, o que esconderia
+	// diferencas de espacos e quebras de linha: compara o sha256
+	out, err := sys.ExecPrivileged(ctx, "sha256sum", f.Path)
+	if err != nil {
+		return false
+	}
+	sum, _, _ := strings.Cut(strings.TrimSpace(out), " ")
+	return sum == checksum(f.Content)
+}
+
+// checksum retorna o sha256 de data em hexadecimal, como o sha256sum.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,128 @@
+package sysfile
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/ale/blueprint/internal/system"
+)
+
+// unreadable simula um arquivo que o usuario nao pode ler (ex: /etc/sudoers.d).
+type unreadable struct {
+	*system.Mock
+	path string
+}
+
+func (u unreadable) ReadFile(path string) ([]byte, error) {
+	if path == u.path {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrPermission}
+	}
+	return u.Mock.ReadFile(path)
+}
+
+func TestStagedPath(t *testing.T) {
+	if got := StagedPath("/etc/sudoers.d/nopasswd-ale"); got != "/etc/sudoers.d/.blueprint-nopasswd-ale" {
+		t.Errorf("StagedPath() = %s", got)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	mock := system.NewMock()
+	mock.Commands["restorecon"] = true
+
+	changed, err := Install(context.Background(), mock, File{Path: "/etc/x.conf", Content: []byte("a\n"), Mode: 0o640, Owner: "root:gdm"})
+	if err != nil || !changed {
+		t.Fatalf("Install() = %v, %v", changed, err)
+	}
+	want := []string{
+		"sudo install -m 0640 -o root -g gdm /etc/.blueprint-x.conf",
+		"sudo mv -f /etc/.blueprint-x.conf /etc/x.conf",
+		"sudo restorecon /etc/x.conf",
+	}
+	if strings.Join(mock.ExecLog, "|") != strings.Join(want, "|") {
+		t.Errorf("ExecLog = %v, esperava %v", mock.ExecLog, want)
+	}
+}
+
+func TestInstall_Unchanged(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/etc/x.conf"] = []byte("a\n")
+	mock.ExecResults["sudo stat -c %a %U:%G /etc/x.conf"] = system.ExecResult{Output: "644 root:root"}
+
+	changed, err := Install(context.Background(), mock, File{Path: "/etc/x.conf", Content: []byte("a\n"), Mode: 0o644})
+	if err != nil || changed {
+		t.Fatalf("Install() = %v, %v", changed, err)
+	}
+	if len(mock.ExecLog) != 1 {
+		t.Errorf("nada deveria ser escrito: %v", mock.ExecLog)
+	}
+}
+
+func TestInstall_WrongModeRewrites(t *testing.T) {
+	mock := system.NewMock()
+	mock.Files["/etc/sudoers.d/x"] = []byte("a\n")
+	mock.ExecResults["sudo stat -c %a %U:%G /etc/sudoers.d/x"] = system.ExecResult{Output: "644 root:root"}
+
+	changed, err := Install(context.Background(), mock, File{Path: "/etc/sudoers.d/x", Content: []byte("a\n"), Mode: 0o440})
+	if err != nil || !changed {
+		t.Errorf("modo diferente deveria reinstalar: %v, %v", changed, err)
+	}
+}
+
+func TestInstall_UnreadableReadsAsRoot(t *testing.T) {
+	mock := system.NewMock()
+	content := []byte("ale ALL=(ALL) NOPASSWD: ALL\n")
+	mock.ExecResults["sudo sha256sum /etc/sudoers.d/x"] = system.ExecResult{Output: checksum(content) + "  /etc/sudoers.d/x"}
+	mock.ExecResults["sudo stat -c %a %U:%G /etc/sudoers.d/x"] = system.ExecResult{Output: "440 root:root"}
+	sys := unreadable{mock, "/etc/sudoers.d/x"}
+
+	changed, err := Install(context.Background(), sys, File{Path: "/etc/sudoers.d/x", Content: content, Mode: 0o440})
+	if err != nil || changed {
+		t.Errorf("Install() = %v, %v; esperava sem alteracoes", changed, err)
+	}
+}
+
+func TestInstall_UnreadableWhitespaceRewrites(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["sudo sha256sum /etc/sudoers.d/x"] = system.ExecResult{Output: checksum([]byte("ale ALL=(ALL) NOPASSWD: ALL")) + "  /etc/sudoers.d/x"}
+	mock.ExecResults["sudo stat -c %a %U:%G /etc/sudoers.d/x"] = system.ExecResult{Output: "440 root:root"}
+	sys := unreadable{mock, "/etc/sudoers.d/x"}
+
+	changed, err := Install(context.Background(), sys, File{Path: "/etc/sudoers.d/x", Content: []byte("ale ALL=(ALL) NOPASSWD: ALL\n"), Mode: 0o440})
+	if err != nil || !changed {
+		t.Errorf("diferenca so na quebra de linha deveria reinstalar: %v, %v", changed, err)
+	}
+}
+
+func TestInstall_ValidationFails(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["sudo visudo -c -f /etc/sudoers.d/.blueprint-x"] = system.ExecResult{Output: "syntax error near line 1", Err: fmt.Errorf("exit status 1")}
+
+	_, err := Install(context.Background(), mock, File{Path: "/etc/sudoers.d/x", Content: []byte("x"), Mode: 0o440, Validate: Visudo})
+	if err == nil || !strings.Contains(err.Error(), "syntax error near line 1") {
+		t.Fatalf("esperava erro do visudo, obteve %v", err)
+	}
+	want := []string{
+		"sudo install -m 0440 -o root -g root /etc/sudoers.d/.blueprint-x",
+		"sudo visudo -c -f /etc/sudoers.d/.blueprint-x",
+		"sudo rm -f /etc/sudoers.d/.blueprint-x",
+	}
+	if strings.Join(mock.ExecLog, "|") != strings.Join(want, "|") {
+		t.Errorf("ExecLog = %v, esperava %v", mock.ExecLog, want)
+	}
+}
+
+func TestUdevVerify_SkipsOldUdevadm(t *testing.T) {
+	mock := system.NewMock()
+	mock.ExecResults["udevadm verify --help"] = system.ExecResult{Err: fmt.Errorf("unknown command verify")}
+
+	if err := UdevVerify(context.Background(), mock, "/etc/udev/rules.d/.blueprint-x.rules"); err != nil {
+		t.Errorf("sem udevadm verify a validacao deveria ser pulada: %v", err)
+	}
+	if len(mock.ExecLog) != 1 {
+		t.Errorf("ExecLog = %v", mock.ExecLog)
+	}
+}
@@ -0,0 +1,373 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ale/blueprint/internal/module"
+)
+
+// ManifestFile e o indice das copias dentro do diretorio de backup.
+const ManifestFile = "manifest.json"
+
+// BackupEntry descreve o estado original de um arquivo antes da execucao.
+type BackupEntry struct {
+	Path       string      `json:"path"`                 // arquivo original
+	Existed    bool        `json:"existed"`              // false: o arquivo foi criado pela execucao
+	Copy       string      `json:"copy,omitempty"`       // copia do conteudo (dentro do diretorio de backup)
+	Link       string      `json:"link,omitempty"`       // destino, se o original era um symlink
+	Mode       os.FileMode `json:"mode,omitempty"`       // permissoes do original (0: desconhecidas, restaura com 0644)
+	Privileged bool        `json:"privileged,omitempty"` // copiado/restaurado como root
+}
+
+// Backup envolve um System e guarda uma copia de cada arquivo antes de ele
+// ser sobrescrito, substituido por symlink ou removido — inclusive arquivos
+// de sistema (WriteFilePrivileged e cp/mv/install/tee/rm como root). So o
+// primeiro snapshot de cada arquivo e mantido (o estado anterior a
+// execucao). As copias e o manifest ficam em dir (tipicamente
+// <estado>/backups/<run-id>).
+type Backup struct {
+	inner   module.System
+	dir     string
+	mu      sync.Mutex // modulos podem rodar em paralelo
+	entries []BackupEntry
+	seen    map[string]bool
+}
+
+// NewBackup cria um System que faz backup antes de cada escrita. Um dir que
+// ja tem manifest pertence a outra execucao e e recusado: o manifest novo
+// sobrescreveria o dela, junto com as copias de mesmo indice.
+func NewBackup(inner module.System, dir string) (*Backup, error) {
+	if inner.FileExists(filepath.Join(dir, ManifestFile)) {
+		return nil, fmt.Errorf("diretorio de backup ja usado por outra execucao: %s", dir)
+	}
+	return &Backup{inner: inner, dir: dir, seen: make(map[string]bool)}, nil
+}
+
+// Dir retorna o diretorio de backup.
+func (b *Backup) Dir() string { return b.dir }
+
+// Entries retorna os arquivos registrados ate agora.
+func (b *Backup) Entries() []BackupEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]BackupEntry(nil), b.entries...)
+}
+
+// Operacoes de leitura delegam para o sistema envolvido
+func (b *Backup) FileExists(path string) bool          { return b.inner.FileExists(path) }
+func (b *Backup) ReadFile(path string) ([]byte, error) { return b.inner.ReadFile(path) }
+func (b *Backup) HomeDir() string                      { return b.inner.HomeDir() }
+func (b *Backup) IsContainer() bool                    { return b.inner.IsContainer() }
+func (b *Backup) IsWSL() bool                          { return b.inner.IsWSL() }
+func (b *Backup) Env(key string) string                { return b.inner.Env(key) }
+func (b *Backup) CommandExists(name string) bool       { return b.inner.CommandExists(name) }
+func (b *Backup) ReadLink(path string) (string, error) { return b.inner.ReadLink(path) }
+
+func (b *Backup) FileMode(path string) (os.FileMode, error) { return b.inner.FileMode(path) }
+
+func (b *Backup) MkdirAll(path string, perm os.FileMode) error {
+	return b.inner.MkdirAll(path, perm)
+}
+
+func (b *Backup) ExecStream(ctx context.Context, callback func(line string), name string, args ...string) error {
+	if err := b.snapshotCommand(ctx, name, args); err != nil {
+		return err
+	}
+	return b.inner.ExecStream(ctx, callback, name, args...)
+}
+
+func (b *Backup) ExecInput(ctx context.Context, input []byte, callback func(line string), name string, args ...string) error {
+	if err := b.snapshotCommand(ctx, name, args); err != nil {
+		return err
+	}
+	return b.inner.ExecInput(ctx, input, callback, name, args...)
+}
+
+// Exec faz backup dos destinos de comandos privilegiados que escrevem
+// arquivos (sudo cp/mv/install/tee/rm) antes de executa-los.
+func (b *Backup) Exec(ctx context.Context, name string, args ...string) (string, error) {
+	if err := b.snapshotCommand(ctx, name, args); err != nil {
+		return "", err
+	}
+	return b.inner.Exec(ctx, name, args...)
+}
+
+// ExecPrivileged faz backup dos destinos de cp/mv/install/tee/rm antes de
+// executar o comando como root.
+func (b *Backup) ExecPrivileged(ctx context.Context, name string, args ...string) (string, error) {
+	for _, dest := range privilegedTargets(append([]string{name}, args...)) {
+		if err := b.snapshotPrivileged(ctx, dest); err != nil {
+			return "", err
+		}
+	}
+	return b.inner.ExecPrivileged(ctx, name, args...)
+}
+
+func (b *Backup) WriteFilePrivileged(ctx context.Context, path string, data []byte, perm os.FileMode, owner string) error {
+	if err := b.snapshotPrivileged(ctx, path); err != nil {
+		return err
+	}
+	return b.inner.WriteFilePrivileged(ctx, path, data, perm, owner)
+}
+
+// Operacoes de escrita fazem snapshot antes de delegar
+func (b *Backup) WriteFile(path string, data []byte, perm os.FileMode) error {
+	if err := b.snapshot(path); err != nil {
+		return err
+	}
+	return b.inner.WriteFile(path, data, perm)
+}
+
+func (b *Backup) Chmod(path string, perm os.FileMode) error {
+	if err := b.snapshot(path); err != nil {
+		return err
+	}
+	return b.inner.Chmod(path, perm)
+}
+
+func (b *Backup) Symlink(oldname, newname string) error {
+	if err := b.snapshot(newname); err != nil {
+		return err
+	}
+	return b.inner.Symlink(oldname, newname)
+}
+
+func (b *Backup) AppendFile(path string, data []byte, perm os.FileMode) error {
+	if err := b.snapshot(path); err != nil {
+		return err
+	}
+	return b.inner.AppendFile(path, data, perm)
+}
+
+func (b *Backup) AppendToFileIfMissing(path, line string) (bool, error) {
+	if err := b.snapshot(path); err != nil {
+		return false, err
+	}
+	return b.inner.AppendToFileIfMissing(path, line)
+}
+
+func (b *Backup) RemoveLineFromFile(path, line string) (bool, error) {
+	if err := b.snapshot(path); err != nil {
+		return false, err
+	}
+	return b.inner.RemoveLineFromFile(path, line)
+}
+
+func (b *Backup) Remove(path string) error {
+	if err := b.snapshot(path); err != nil {
+		return err
+	}
+	return b.inner.Remove(path)
+}
+
+// snapshot registra o estado atual de um arquivo do usuario, com as
+// permissoes. Diretorios nao sao copiados; um arquivo que nao da para copiar
+// e erro, e a escrita nao acontece: o rollback nao conseguiria desfaze-la.
+func (b *Backup) snapshot(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.seen[path] {
+		return nil
+	}
+
+	entry := BackupEntry{Path: path}
+	if target, err := b.inner.ReadLink(path); err == nil {
+		entry.Existed = true
+		entry.Link = target
+	} else if b.inner.FileExists(path) {
+		mode, err := b.inner.FileMode(path)
+		if err != nil {
+			return fmt.Errorf("backup de %s: %w", path, err)
+		}
+		if mode.IsDir() {
+			return nil
+		}
+		data, err := b.inner.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("backup de %s: %w", path, err)
+		}
+		entry.Existed = true
+		entry.Mode = mode.Perm()
+		entry.Copy = b.copyPath(path)
+		if err := b.inner.MkdirAll(b.dir, 0o700); err != nil {
+			return fmt.Errorf("backup de %s: %w", path, err)
+		}
+		if err := b.inner.WriteFile(entry.Copy, data, 0o600); err != nil {
+			return fmt.Errorf("backup de %s: %w", path, err)
+		}
+	}
+
+	return b.record(entry)
+}
+
+// snapshotCommand faz backup dos destinos de um "sudo ..." executado via
+// Exec (ex: comandos declarados em um modulo YAML).
+func (b *Backup) snapshotCommand(ctx context.Context, name string, args []string) error {
+	if name != "sudo" {
+		return nil
+	}
+	for _, dest := range privilegedTargets(args) {
+		if err := b.snapshotPrivileged(ctx, dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotPrivileged copia um arquivo do sistema como root, preservando dono e modo.
+func (b *Backup) snapshotPrivileged(ctx context.Context, path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.seen[path] {
+		return nil
+	}
+
+	entry := BackupEntry{Path: path, Privileged: true}
+	if _, err := b.inner.ExecPrivileged(ctx, "test", "-f", path); err == nil {
+		entry.Existed = true
+		entry.Copy = b.copyPath(path)
+		if err := b.inner.MkdirAll(b.dir, 0o700); err != nil {
+			return fmt.Errorf("backup de %s: %w", path, err)
+		}
+		if out, err := b.inner.ExecPrivileged(ctx, "cp", "-a", path, entry.Copy); err != nil {
+			return fmt.Errorf("backup de %s: %s: %w", path, out, err)
+		}
+	}
+
+	return b.record(entry)
+}
+
+// record adiciona a entrada e regrava o manifest (chamado com mu travado).
+// O manifest e regravado a cada entrada para sobreviver a uma interrupcao.
+func (b *Backup) record(entry BackupEntry) error {
+	b.seen[entry.Path] = true
+	b.entries = append(b.entries, entry)
+
+	data, err := json.MarshalIndent(b.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serializar manifest de backup: %w", err)
+	}
+	if err := b.inner.MkdirAll(b.dir, 0o700); err != nil {
+		return fmt.Errorf("criar diretorio de backup: %w", err)
+	}
+	if err := b.inner.WriteFile(filepath.Join(b.dir, ManifestFile), data, 0o600); err != nil {
+		return fmt.Errorf("gravar manifest de backup: %w", err)
+	}
+	return nil
+}
+
+// copyPath gera o nome da copia: indice + nome base (ex: "002-custom.conf").
+func (b *Backup) copyPath(path string) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%03d-%s", len(b.entries)+1, filepath.Base(path)))
+}
+
+// privilegedTargets extrai os arquivos escritos ou apagados por um comando
+// executado como root: o destino de cp/mv/install e os argumentos de tee/rm.
+func privilegedTargets(args []string) []string {
+	// Pula opcoes do proprio sudo (ex: -n)
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	var operands []string
+	for _, a := range args[1:] {
+		if !strings.HasPrefix(a, "-") {
+			operands = append(operands, a)
+		}
+	}
+
+	switch args[0] {
+	case "cp", "mv", "install":
+		if len(operands) < 2 {
+			return nil
+		}
+		return operands[len(operands)-1:]
+	case "tee", "rm":
+		return operands
+	}
+	return nil
+}
+
+// LoadBackup le o manifest de um diretorio de backup.
+func LoadBackup(sys module.System, dir string) ([]BackupEntry, error) {
+	path := filepath.Join(dir, ManifestFile)
+	if !sys.FileExists(path) {
+		return nil, fmt.Errorf("nenhum backup em %s", dir)
+	}
+	data, err := sys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ler manifest de backup: %w", err)
+	}
+	var entries []BackupEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: manifest invalido: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Restore devolve um arquivo ao estado registrado: restaura o conteudo (com
+// as permissoes originais) ou o symlink original, ou apaga o arquivo se ele
+// nao existia antes da execucao.
+func Restore(ctx context.Context, sys module.System, entry BackupEntry) error {
+	if entry.Privileged {
+		if !entry.Existed {
+			if out, err := sys.ExecPrivileged(ctx, "rm", "-f", entry.Path); err != nil {
+				return fmt.Errorf("remover %s: %s: %w", entry.Path, out, err)
+			}
+			return nil
+		}
+		if out, err := sys.ExecPrivileged(ctx, "cp", "-a", entry.Copy, entry.Path); err != nil {
+			return fmt.Errorf("restaurar %s: %s: %w", entry.Path, out, err)
+		}
+		return nil
+	}
+
+	// Um symlink no lugar precisa sair antes: escrever nele alteraria o destino
+	if _, err := sys.ReadLink(entry.Path); err == nil || (!entry.Existed && sys.FileExists(entry.Path)) {
+		if err := sys.Remove(entry.Path); err != nil {
+			return fmt.Errorf("remover %s: %w", entry.Path, err)
+		}
+	}
+
+	switch {
+	case !entry.Existed:
+		return nil
+	case entry.Link != "":
+		if err := sys.Symlink(entry.Link, entry.Path); err != nil {
+			return fmt.Errorf("restaurar link %s: %w", entry.Path, err)
+		}
+	default:
+		data, err := sys.ReadFile(entry.Copy)
+		if err != nil {
+			return fmt.Errorf("ler copia de %s: %w", entry.Path, err)
+		}
+		if err := sys.MkdirAll(filepath.Dir(entry.Path), 0o755); err != nil {
+			return fmt.Errorf("criar diretorio de %s: %w", entry.Path, err)
+		}
+		perm := entry.Mode
+		if perm == 0 {
+			perm = 0o644
+		}
+		if err := sys.WriteFile(entry.Path, data, perm); err != nil {
+			return fmt.Errorf("restaurar %s: %w", entry.Path, err)
+		}
+		// WriteFile so aplica o modo ao criar o arquivo (e com o umask)
+		if entry.Mode != 0 {
+			if err := sys.Chmod(entry.Path, entry.Mode); err != nil {
+				return fmt.Errorf("restaurar permissoes de %s: %w", entry.Path, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBackup_WriteFileSnapshotsOriginal(t *testing.T) {
+	mock := NewMock()
+	mock.Files["/home/test/.XCompose"] = []byte("original")
+	b := newBackup(t, mock, "/state/backups/r1")
+
+	_ = b.WriteFile("/home/test/.XCompose", []byte("novo"), 0o644)
+	_ = b.WriteFile("/home/test/.XCompose", []byte("novo de novo"), 0o644)
+
+	entries := b.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("esperava 1 entrada (so o primeiro snapshot), obteve %d", len(entries))
+	}
+	e := entries[0]
+	if !e.Existed || e.Copy == "" {
+		t.Fatalf("entrada = %+v", e)
+	}
+	if got := string(mock.Files[e.Copy]); got != "original" {
+		t.Errorf("copia = %q, esperava conteudo original", got)
+	}
+	if _, ok := mock.Files["/state/backups/r1/"+ManifestFile]; !ok {
+		t.Error("esperava manifest gravado")
+	}
+}
+
+func TestBackup_SymlinkOverExistingFile(t *testing.T) {
+	mock := NewMock()
+	mock.Files["/home/test/.config/starship.toml"] = []byte("meu config")
+	b := newBackup(t, mock, "/bk")
+
+	_ = b.Symlink("/repo/starship.toml", "/home/test/.config/starship.toml")
+
+	e := b.Entries()[0]
+	if string(mock.Files[e.Copy]) != "meu config" {
+		t.Errorf("esperava copia do arquivo substituido, entrada = %+v", e)
+	}
+}
+
+func TestBackup_RecordsCreatedFile(t *testing.T) {
+	mock := NewMock()
+	b := newBackup(t, mock, "/bk")
+
+	_, _ = b.AppendToFileIfMissing("/home/test/.bashrc", "eval x")
+
+	e := b.Entries()[0]
+	if e.Existed || e.Copy != "" {
+		t.Errorf("arquivo novo nao deveria ter copia: %+v", e)
+	}
+}
+
+func TestBackup_PrivilegedCopy(t *testing.T) {
+	mock := NewMock()
+	b := newBackup(t, mock, "/bk")
+
+	_, _ = b.Exec(context.Background(), "sudo", "cp", "/tmp/custom.conf", "/etc/gdm/custom.conf")
+
+	want := []string{
+		"sudo test -f /etc/gdm/custom.conf",
+		"sudo cp -a /etc/gdm/custom.conf /bk/001-custom.conf",
+		"sudo cp /tmp/custom.conf /etc/gdm/custom.conf",
+	}
+	if !reflect.DeepEqual(mock.ExecLog, want) {
+		t.Errorf("ExecLog = %v, esperava %v", mock.ExecLog, want)
+	}
+	if e := b.Entries()[0]; !e.Privileged || !e.Existed {
+		t.Errorf("entrada = %+v", e)
+	}
+}
+
+func TestBackup_PrivilegedMissingFile(t *testing.T) {
+	mock := NewMock()
+	mock.ExecResults["sudo test -f /etc/udev/rules.d/99-x.rules"] = ExecResult{Err: errors.New("exit 1")}
+	b := newBackup(t, mock, "/bk")
+
+	_, _ = b.Exec(context.Background(), "sudo", "cp", "/tmp/x", "/etc/udev/rules.d/99-x.rules")
+
+	if e := b.Entries()[0]; e.Existed || e.Copy != "" {
+		t.Errorf("arquivo inexistente nao deveria ter copia: %+v", e)
+	}
+}
+
+func TestBackup_WriteFilePrivileged(t *testing.T) {
+	mock := NewMock()
+	b := newBackup(t, mock, "/bk")
+
+	if err := b.WriteFilePrivileged(context.Background(), "/etc/sudoers.d/x", []byte("x\n"), 0o440, ""); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = b.ExecPrivileged(context.Background(), "rm", "-f", "/etc/udev/rules.d/99-x.rules")
+
+	want := []string{
+		"sudo test -f /etc/sudoers.d/x",
+		"sudo cp -a /etc/sudoers.d/x /bk/001-x",
+		"sudo install -m 0440 -o root -g root /etc/sudoers.d/x",
+		"sudo test -f /etc/udev/rules.d/99-x.rules",
+		"sudo cp -a /etc/udev/rules.d/99-x.rules /bk/002-99-x.rules",
+		"sudo rm -f /etc/udev/rules.d/99-x.rules",
+	}
+	if !reflect.DeepEqual(mock.ExecLog, want) {
+		t.Errorf("ExecLog = %v, esperava %v", mock.ExecLog, want)
+	}
+	if len(b.Entries()) != 2 || !b.Entries()[0].Privileged {
+		t.Errorf("entradas = %+v", b.Entries())
+	}
+}
+
+func TestBackup_IgnoresNonWritingCommands(t *testing.T) {
+	mock := NewMock()
+	b := newBackup(t, mock, "/bk")
+
+	_, _ = b.Exec(context.Background(), "sudo", "udevadm", "control", "--reload-rules")
+	_, _ = b.Exec(context.Background(), "cp", "/a", "/b")
+
+	if len(b.Entries()) != 0 {
+		t.Errorf("nao esperava backups, obteve %+v", b.Entries())
+	}
+}
+
+func TestPrivilegedTargets(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"cp", "/tmp/a", "/etc/a"}, []string{"/etc/a"}},
+		{[]string{"-n", "install", "-m", "0440", "/tmp/a", "/etc/a"}, []string{"/etc/a"}},
+		{[]string{"rm", "-f", "/etc/a", "/etc/b"}, []string{"/etc/a", "/etc/b"}},
+		{[]string{"tee", "-a", "/etc/a"}, []string{"/etc/a"}},
+		{[]string{"chmod", "0440", "/etc/a"}, nil},
+		{[]string{"cp", "/tmp/a"}, nil},
+	}
+	for _, tt := range tests {
+		if got := privilegedTargets(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("privilegedTargets(%v) = %v, esperava %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRestore_RoundTrip(t *testing.T) {
+	mock := NewMock()
+	mock.Files["/home/test/.XCompose"] = []byte("original")
+	mock.Symlinks["/home/test/.config/starship.toml"] = "/antigo/starship.toml"
+	b := newBackup(t, mock, "/bk")
+
+	_ = b.WriteFile("/home/test/.XCompose", []byte("alterado"), 0o644)
+	_ = b.Symlink("/repo/starship.toml", "/home/test/.config/starship.toml")
+	_ = b.WriteFile("/home/test/.cache/novo", []byte("criado"), 0o644)
+
+	entries, err := LoadBackup(mock, "/bk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if err := Restore(context.Background(), mock, e); err != nil {
+			t.Fatalf("Restore(%s): %v", e.Path, err)
+		}
+	}
+
+	if got := string(mock.Files["/home/test/.XCompose"]); got != "original" {
+		t.Errorf(".XCompose = %q, esperava original", got)
+	}
+	if got := mock.Symlinks["/home/test/.config/starship.toml"]; got != "/antigo/starship.toml" {
+		t.Errorf("starship.toml -> %q, esperava link original", got)
+	}
+	if mock.FileExists("/home/test/.cache/novo") {
+		t.Error("arquivo criado pela execucao deveria ser apagado")
+	}
+}
+
+func TestRestore_KeepsMode(t *testing.T) {
+	mock := NewMock()
+	mock.Files["/home/test/.local/bin/run"] = []byte("#!/bin/sh\n")
+	mock.Modes["/home/test/.local/bin/run"] = 0o755
+	mock.Files["/home/test/.netrc"] = []byte("machine x\n")
+	mock.Modes["/home/test/.netrc"] = 0o600
+	b := newBackup(t, mock, "/bk")
+
+	_ = b.WriteFile("/home/test/.local/bin/run", []byte("alterado"), 0o644)
+	_ = b.Remove("/home/test/.netrc")
+	_ = b.Chmod("/home/test/.local/bin/run", 0o644)
+
+	entries, err := LoadBackup(mock, "/bk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if err := Restore(context.Background(), mock, e); err != nil {
+			t.Fatalf("Restore(%s): %v", e.Path, err)
+		}
+	}
+
+	for path, want := range map[string]os.FileMode{"/home/test/.local/bin/run": 0o755, "/home/test/.netrc": 0o600} {
+		if got, _ := mock.FileMode(path); got != want {
+			t.Errorf("%s: modo %04o, esperava %04o", path, got, want)
+		}
+	}
+}
+
+func TestBackup_UnreadableFileBlocksWrite(t *testing.T) {
+	mock := NewMock()
+	mock.Files["/home/test/.config/x"] = []byte("original")
+	sys := unreadableFile{mock, "/home/test/.config/x"}
+	b := newBackup(t, mock, "/bk")
+	b.inner = sys
+
+	if err := b.WriteFile("/home/test/.config/x", []byte("novo"), 0o644); err == nil {
+		t.Error("esperava erro: o arquivo nao pode ser copiado")
+	}
+	if got := string(mock.Files["/home/test/.config/x"]); got != "original" {
+		t.Errorf("a escrita nao deveria acontecer sem backup: %q", got)
+	}
+}
+
+// unreadableFile simula um arquivo sem permissao de leitura.
+type unreadableFile struct {
+	*Mock
+	path string
+}
+
+func (u unreadableFile) ReadFile(path string) ([]byte, error) {
+	if path == u.path {
+		return nil, fs.ErrPermission
+	}
+	return u.Mock.ReadFile(path)
+}
+
+func TestRestore_Privileged(t *testing.T) {
+	mock := NewMock()
+
+	_ = Restore(context.Background(), mock, BackupEntry{Path: "/etc/a", Existed: true, Copy: "/bk/001-a", Privileged: true})
+	_ = Restore(context.Background(), mock, BackupEntry{Path: "/etc/b", Privileged: true})
+
+	want := []string{"sudo cp -a /bk/001-a /etc/a", "sudo rm -f /etc/b"}
+	if !reflect.DeepEqual(mock.ExecLog, want) {
+		t.Errorf("ExecLog = %v, esperava %v", mock.ExecLog, want)
+	}
+}
+
+func TestLoadBackup_Missing(t *testing.T) {
+	if _, err := LoadBackup(NewMock(), "/bk"); err == nil {
+		t.Error("esperava erro sem manifest")
+	}
+}
+
+func newBackup(t *testing.T, mock *Mock, dir string) *Backup {
+	t.Helper()
+	b, err := NewBackup(mock, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestNewBackup_RefusesUsedDir(t *testing.T) {
+	mock := NewMock()
+	mock.Files["/bk/manifest.json"] = []byte("[]")
+
+	if _, err := NewBackup(mock, "/bk"); err == nil {
+		t.Error("esperava erro com manifest de outra execucao no diretorio")
+	}
+}
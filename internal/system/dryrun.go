@@ -4,21 +4,35 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/ale/blueprint/internal/diff"
 	"github.com/ale/blueprint/internal/module"
 )
 
 // DryRun envolve um System real mas intercepta operacoes de escrita,
-// logando o que faria sem executar.
+// logando o que faria sem executar. Escritas em arquivos sao mostradas como
+// diff unificado contra o conteudo atual.
 type DryRun struct {
 	inner module.System
 	log   func(msg string)
+
+	// Color colore os diffs com ANSI (terminal).
+	Color bool
+
+	// pending guarda o conteudo que cada arquivo teria apos as escritas
+	// simuladas, para que escritas seguidas no mesmo arquivo (ou um
+	// "sudo cp" de um arquivo temporario) gerem o diff certo e leituras
+	// posteriores vejam o conteudo simulado.
+	pending map[string][]byte
+	mu      sync.Mutex // modulos podem rodar em paralelo (--jobs)
 }
 
 // NewDryRun cria um System que loga ao inves de executar.
 func NewDryRun(inner module.System, log func(msg string)) *DryRun {
-	return &DryRun{inner: inner, log: log}
+	return &DryRun{inner: inner, log: log, pending: make(map[string][]byte)}
 }
 
 // Exec loga o comando mas nao o executa. Retorna ("", nil).
@@ -27,6 +41,7 @@ func NewDryRun(inner module.System, log func(msg string)) *DryRun {
 // tivesse sucesso. Isso e intencional — dry-run pula toda escrita.
 func (d *DryRun) Exec(_ context.Context, name string, args ...string) (string, error) {
 	d.log(fmt.Sprintf("[dry-run] executaria: %s %s", name, strings.Join(args, " ")))
+	d.copyDiff(name, args)
 	return "", nil
 }
 
@@ -35,18 +50,57 @@ func (d *DryRun) ExecStream(_ context.Context, _ func(line string), name string,
 	return nil
 }
 
+func (d *DryRun) ExecInput(_ context.Context, _ []byte, _ func(line string), name string, args ...string) error {
+	d.log(fmt.Sprintf("[dry-run] executaria (stdin): %s %s", name, strings.Join(args, " ")))
+	return nil
+}
+
+// ExecPrivileged loga o comando que rodaria como root, sem executa-lo.
+func (d *DryRun) ExecPrivileged(_ context.Context, name string, args ...string) (string, error) {
+	d.log(fmt.Sprintf("[dry-run] executaria como root: %s %s", name, strings.Join(args, " ")))
+	d.copyDiff(name, args)
+	return "", nil
+}
+
 // Operacoes de leitura delegam para o sistema real
-func (d *DryRun) FileExists(path string) bool        { return d.inner.FileExists(path) }
-func (d *DryRun) ReadFile(path string) ([]byte, error) { return d.inner.ReadFile(path) }
-func (d *DryRun) HomeDir() string                     { return d.inner.HomeDir() }
-func (d *DryRun) IsContainer() bool                   { return d.inner.IsContainer() }
-func (d *DryRun) IsWSL() bool                         { return d.inner.IsWSL() }
-func (d *DryRun) Env(key string) string               { return d.inner.Env(key) }
+func (d *DryRun) FileExists(path string) bool          { return d.inner.FileExists(path) }
+func (d *DryRun) HomeDir() string                      { return d.inner.HomeDir() }
+func (d *DryRun) IsContainer() bool                    { return d.inner.IsContainer() }
+func (d *DryRun) IsWSL() bool                          { return d.inner.IsWSL() }
+func (d *DryRun) Env(key string) string                { return d.inner.Env(key) }
 func (d *DryRun) CommandExists(name string) bool       { return d.inner.CommandExists(name) }
+func (d *DryRun) ReadLink(path string) (string, error) { return d.inner.ReadLink(path) }
+
+func (d *DryRun) FileMode(path string) (os.FileMode, error) { return d.inner.FileMode(path) }
+
+// ReadFile considera as escritas simuladas: um passo seguinte do mesmo
+// modulo le o que o passo anterior teria escrito.
+func (d *DryRun) ReadFile(path string) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.current(path)
+}
 
 // Operacoes de escrita sao logadas mas nao executadas
-func (d *DryRun) WriteFile(path string, _ []byte, _ os.FileMode) error {
+func (d *DryRun) WriteFile(path string, data []byte, _ os.FileMode) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	d.log(fmt.Sprintf("[dry-run] escreveria arquivo: %s", path))
+	d.logDiff(path, data)
+	return nil
+}
+
+func (d *DryRun) WriteFilePrivileged(_ context.Context, path string, data []byte, perm os.FileMode, owner string) error {
+	user, group := FileOwner(owner)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log(fmt.Sprintf("[dry-run] escreveria arquivo de sistema: %s (modo %04o, dono %s:%s)", path, perm.Perm(), user, group))
+	d.logDiff(path, data)
+	return nil
+}
+
+func (d *DryRun) Chmod(path string, perm os.FileMode) error {
+	d.log(fmt.Sprintf("[dry-run] alteraria permissoes: %s (modo %04o)", path, perm.Perm()))
 	return nil
 }
 
@@ -57,15 +111,139 @@ func (d *DryRun) MkdirAll(path string, _ os.FileMode) error {
 
 func (d *DryRun) Symlink(oldname, newname string) error {
 	d.log(fmt.Sprintf("[dry-run] criaria symlink: %s -> %s", newname, oldname))
+	current, err := d.inner.ReadLink(newname)
+	switch {
+	case err == nil && current == oldname:
+		d.log("  (link ja aponta para o destino)")
+	case err == nil:
+		d.logText(fmt.Sprintf("--- %s (symlink)\n+++ %s (symlink)\n-%s\n+%s\n", newname, newname, current, oldname))
+	case d.inner.FileExists(newname):
+		d.logText(fmt.Sprintf("--- %s (arquivo)\n+++ %s (symlink)\n-(arquivo existente)\n+%s\n", newname, newname, oldname))
+	}
+	return nil
+}
+
+func (d *DryRun) AppendFile(path string, data []byte, _ os.FileMode) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log(fmt.Sprintf("[dry-run] acrescentaria ao arquivo: %s", path))
+	current, _ := d.current(path)
+	d.logDiff(path, append(append([]byte{}, current...), data...))
 	return nil
 }
 
 func (d *DryRun) AppendToFileIfMissing(path, line string) (bool, error) {
-	// Verifica se ja existe (leitura real)
-	data, err := d.inner.ReadFile(path)
-	if err == nil && strings.Contains(string(data), line) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Verifica se ja existe (leitura real, mais escritas simuladas)
+	data, _ := d.current(path)
+	if strings.Contains(string(data), line) {
 		return false, nil
 	}
 	d.log(fmt.Sprintf("[dry-run] adicionaria ao %s: %s", path, line))
+
+	content := string(data)
+	if len(content) > 0 && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	d.logDiff(path, []byte(content+line+"\n"))
+	return true, nil
+}
+
+func (d *DryRun) RemoveLineFromFile(path, line string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Verifica se a linha existe (leitura real, mais escritas simuladas)
+	data, err := d.current(path)
+	if err != nil {
+		return false, nil
+	}
+	content, removed := removeLine(string(data), line)
+	if !removed {
+		return false, nil
+	}
+	d.log(fmt.Sprintf("[dry-run] removeria do %s: %s", path, line))
+	d.logDiff(path, []byte(content))
 	return true, nil
 }
+
+func (d *DryRun) Remove(path string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log(fmt.Sprintf("[dry-run] removeria: %s", path))
+	delete(d.pending, path)
+	return nil
+}
+
+// current retorna o conteudo do arquivo considerando as escritas simuladas
+// (chamado com mu travado, como logDiff e copyDiff).
+func (d *DryRun) current(path string) ([]byte, error) {
+	if data, ok := d.pending[path]; ok {
+		return data, nil
+	}
+	return d.inner.ReadFile(path)
+}
+
+// logDiff registra o novo conteudo do arquivo e loga o diff contra o atual.
+func (d *DryRun) logDiff(path string, data []byte) {
+	old, err := d.current(path)
+	oldName := path
+	if err != nil {
+		old, oldName = nil, "/dev/null"
+	}
+	d.pending[path] = data
+
+	text := diff.Unified(oldName, path, old, data)
+	if text == "" {
+		d.log("  (conteudo identico, sem alteracoes)")
+		return
+	}
+	d.logText(text)
+}
+
+// logText loga um diff linha a linha, colorido se Color estiver ativo.
+func (d *DryRun) logText(text string) {
+	if d.Color {
+		text = diff.Colorize(text)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		d.log("  " + line)
+	}
+}
+
+// copyDiff reconhece copias de um arquivo escrito em dry-run para o destino
+// final ("[sudo] cp tmp /etc/..." ou o "mv" de um arquivo preparado, via
+// Exec ou ExecPrivileged) e mostra o diff contra o destino.
+func (d *DryRun) copyDiff(name string, args []string) {
+	if name == "sudo" && len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name != "cp" && name != "mv" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var paths []string
+	for _, a := range args {
+		if !strings.HasPrefix(a, "-") {
+			paths = append(paths, a)
+		}
+	}
+	if len(paths) != 2 {
+		return
+	}
+	data, ok := d.pending[paths[0]]
+	if !ok {
+		return
+	}
+	dst := paths[1]
+	if strings.HasSuffix(dst, "/") {
+		dst = filepath.Join(dst, filepath.Base(paths[0]))
+	}
+	if name == "mv" {
+		delete(d.pending, paths[0])
+	}
+	d.logDiff(dst, data)
+}
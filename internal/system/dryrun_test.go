@@ -0,0 +1,203 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestDryRun() (*DryRun, *Mock, *[]string) {
+	mock := NewMock()
+	var logs []string
+	d := NewDryRun(mock, func(msg string) { logs = append(logs, msg) })
+	return d, mock, &logs
+}
+
+func TestDryRun_WriteFileDiff(t *testing.T) {
+	d, mock, logs := newTestDryRun()
+	mock.Files["/home/test/.XCompose"] = []byte("include \"%L\"\n")
+
+	if err := d.WriteFile("/home/test/.XCompose", []byte("include \"%L\"\n<dead_acute> <c> : \"ç\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.Join(*logs, "\n")
+	for _, want := range []string{
+		"[dry-run] escreveria arquivo: /home/test/.XCompose",
+		"  --- /home/test/.XCompose",
+		"  @@ -1 +1,2 @@",
+		`   include "%L"`,
+		`  +<dead_acute> <c> : "ç"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log sem %q:\n%s", want, out)
+		}
+	}
+	if got := string(mock.Files["/home/test/.XCompose"]); got != "include \"%L\"\n" {
+		t.Errorf("dry-run nao deveria escrever: %q", got)
+	}
+}
+
+func TestDryRun_NewFileAndUnchanged(t *testing.T) {
+	d, mock, logs := newTestDryRun()
+	mock.Files["/same"] = []byte("x\n")
+
+	d.WriteFile("/new", []byte("a\n"), 0o644)
+	d.WriteFile("/same", []byte("x\n"), 0o644)
+
+	out := strings.Join(*logs, "\n")
+	if !strings.Contains(out, "--- /dev/null") || !strings.Contains(out, "  +a") {
+		t.Errorf("esperava diff de arquivo novo:\n%s", out)
+	}
+	if !strings.Contains(out, "sem alteracoes") {
+		t.Errorf("esperava aviso de conteudo identico:\n%s", out)
+	}
+}
+
+func TestDryRun_AppendAccumulates(t *testing.T) {
+	d, mock, logs := newTestDryRun()
+	mock.Files["/home/test/.bashrc"] = []byte("# bashrc")
+
+	if ok, _ := d.AppendToFileIfMissing("/home/test/.bashrc", "eval one"); !ok {
+		t.Fatal("esperava adicionar")
+	}
+	if ok, _ := d.AppendToFileIfMissing("/home/test/.bashrc", "eval one"); ok {
+		t.Error("segunda chamada deveria ver a linha simulada")
+	}
+	d.AppendToFileIfMissing("/home/test/.bashrc", "eval two")
+
+	out := strings.Join(*logs, "\n")
+	if !strings.Contains(out, "  +eval one") || !strings.Contains(out, "   eval one\n  +eval two") {
+		t.Errorf("diffs inesperados:\n%s", out)
+	}
+}
+
+func TestDryRun_ReadFileSeesPending(t *testing.T) {
+	d, mock, _ := newTestDryRun()
+	mock.Files["/home/test/.bashrc"] = []byte("# bashrc\n")
+
+	d.AppendToFileIfMissing("/home/test/.bashrc", "eval one")
+	data, err := d.ReadFile("/home/test/.bashrc")
+	if err != nil || string(data) != "# bashrc\neval one\n" {
+		t.Errorf("ReadFile() = %q, %v; esperava o conteudo simulado", data, err)
+	}
+	if string(mock.Files["/home/test/.bashrc"]) != "# bashrc\n" {
+		t.Error("dry-run nao deveria escrever")
+	}
+}
+
+// Roda com go test -race: --dry-run --jobs N escreve de varias goroutines.
+func TestDryRun_Parallel(t *testing.T) {
+	mock := NewMock()
+	mock.Files["/home/test/.bashrc"] = []byte("# bashrc\n")
+	var mu sync.Mutex
+	var logs []string
+	d := NewDryRun(mock, func(msg string) {
+		mu.Lock()
+		defer mu.Unlock()
+		logs = append(logs, msg)
+	})
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := fmt.Sprintf("/tmp/f%d", i)
+			d.WriteFile(path, []byte("x\n"), 0o644)
+			d.AppendToFileIfMissing("/home/test/.bashrc", fmt.Sprintf("line %d", i))
+			d.ReadFile("/home/test/.bashrc")
+			d.Exec(context.Background(), "cp", path, "/etc/")
+			d.Remove(path)
+		}()
+	}
+	wg.Wait()
+
+	data, _ := d.ReadFile("/home/test/.bashrc")
+	for i := range 8 {
+		if !strings.Contains(string(data), fmt.Sprintf("line %d\n", i)) {
+			t.Errorf("linha %d perdida:\n%s", i, data)
+		}
+	}
+}
+
+func TestDryRun_SudoCopyDiff(t *testing.T) {
+	d, mock, logs := newTestDryRun()
+	mock.Files["/etc/gdm/custom.conf"] = []byte("[daemon]\n")
+
+	d.WriteFile("/home/test/.cache/blueprint-gdm-custom.conf", []byte("[daemon]\nAutomaticLoginEnable=True\n"), 0o644)
+	*logs = nil
+	d.Exec(context.Background(), "sudo", "cp", "/home/test/.cache/blueprint-gdm-custom.conf", "/etc/gdm/custom.conf")
+
+	out := strings.Join(*logs, "\n")
+	if !strings.Contains(out, "+++ /etc/gdm/custom.conf") || !strings.Contains(out, "  +AutomaticLoginEnable=True") {
+		t.Errorf("esperava diff do destino:\n%s", out)
+	}
+	if len(mock.ExecLog) != 0 {
+		t.Errorf("dry-run nao deveria executar: %v", mock.ExecLog)
+	}
+}
+
+func TestDryRun_Privileged(t *testing.T) {
+	d, mock, logs := newTestDryRun()
+	mock.Files["/etc/gdm/custom.conf"] = []byte("[daemon]\n")
+
+	d.WriteFilePrivileged(context.Background(), "/etc/gdm/custom.conf", []byte("[daemon]\nAutomaticLoginEnable=True\n"), 0o644, "")
+	d.ExecPrivileged(context.Background(), "udevadm", "control", "--reload-rules")
+
+	out := strings.Join(*logs, "\n")
+	for _, want := range []string{
+		"[dry-run] escreveria arquivo de sistema: /etc/gdm/custom.conf (modo 0644, dono root:root)",
+		"  +AutomaticLoginEnable=True",
+		"[dry-run] executaria como root: udevadm control --reload-rules",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log sem %q:\n%s", want, out)
+		}
+	}
+	if len(mock.ExecLog) != 0 || string(mock.Files["/etc/gdm/custom.conf"]) != "[daemon]\n" {
+		t.Errorf("dry-run nao deveria executar nem escrever: %v", mock.ExecLog)
+	}
+}
+
+func TestDryRun_StagedMoveDiff(t *testing.T) {
+	d, mock, logs := newTestDryRun()
+	mock.Files["/etc/gdm/custom.conf"] = []byte("[daemon]\n")
+
+	d.WriteFilePrivileged(context.Background(), "/etc/gdm/.blueprint-custom.conf", []byte("[daemon]\nAutomaticLoginEnable=True\n"), 0o644, "")
+	*logs = nil
+	d.ExecPrivileged(context.Background(), "mv", "-f", "/etc/gdm/.blueprint-custom.conf", "/etc/gdm/custom.conf")
+
+	out := strings.Join(*logs, "\n")
+	if !strings.Contains(out, "--- /etc/gdm/custom.conf") || !strings.Contains(out, "  +AutomaticLoginEnable=True") {
+		t.Errorf("esperava diff do destino:\n%s", out)
+	}
+}
+
+func TestDryRun_Symlink(t *testing.T) {
+	d, mock, logs := newTestDryRun()
+	mock.Symlinks["/home/test/.config/app"] = "/old/app"
+
+	d.Symlink("/new/app", "/home/test/.config/app")
+	out := strings.Join(*logs, "\n")
+	if !strings.Contains(out, "  -/old/app") || !strings.Contains(out, "  +/new/app") {
+		t.Errorf("esperava mudanca do destino do link:\n%s", out)
+	}
+
+	*logs = nil
+	d.Symlink("/old/app", "/home/test/.config/app")
+	if out := strings.Join(*logs, "\n"); !strings.Contains(out, "ja aponta") {
+		t.Errorf("log = %s", out)
+	}
+}
+
+func TestDryRun_Color(t *testing.T) {
+	d, _, logs := newTestDryRun()
+	d.Color = true
+	d.WriteFile("/new", []byte("a\n"), 0o644)
+	if out := strings.Join(*logs, "\n"); !strings.Contains(out, "\033[32m+a") {
+		t.Errorf("esperava diff colorido:\n%q", out)
+	}
+}
@@ -17,6 +17,10 @@ type Mock struct {
 	// Files simula o filesystem em memoria
 	Files map[string][]byte
 
+	// Modes guarda as permissoes dos arquivos de Files (ausente: 0644).
+	// WriteFile registra o modo ao criar o arquivo, como o os.WriteFile.
+	Modes map[string]os.FileMode
+
 	// Symlinks registra links criados (newname -> oldname)
 	Symlinks map[string]string
 
@@ -38,6 +42,9 @@ type Mock struct {
 	// ExecLog registra todos os comandos executados
 	ExecLog []string
 
+	// Inputs registra o stdin enviado via ExecInput ("comando args" -> input)
+	Inputs map[string][]byte
+
 	// WriteFileErr faz WriteFile retornar esse erro (se nao nil)
 	WriteFileErr error
 
@@ -46,6 +53,21 @@ type Mock struct {
 
 	// SymlinkErr faz Symlink retornar esse erro (se nao nil)
 	SymlinkErr error
+
+	// RemoveErr faz Remove retornar esse erro (se nao nil)
+	RemoveErr error
+
+	// Privilege e o backend simulado: comandos de ExecPrivileged entram no
+	// ExecLog e em ExecResults como "<Privilege> comando args" (padrao "sudo").
+	// WriteFilePrivileged entra como "<Privilege> install -m MODO -o DONO -g
+	// GRUPO destino".
+	Privilege string
+
+	// Authenticated registra que Authenticate foi chamado
+	Authenticated bool
+
+	// AuthenticateErr faz Authenticate retornar esse erro (se nao nil)
+	AuthenticateErr error
 }
 
 // ExecResult armazena o resultado simulado de um comando.
@@ -59,10 +81,13 @@ func NewMock() *Mock {
 	return &Mock{
 		ExecResults: make(map[string]ExecResult),
 		Files:       make(map[string][]byte),
+		Modes:       make(map[string]os.FileMode),
 		Symlinks:    make(map[string]string),
 		Home:        "/home/test",
 		EnvVars:     make(map[string]string),
 		Commands:    make(map[string]bool),
+		Inputs:      make(map[string][]byte),
+		Privilege:   "sudo",
 	}
 }
 
@@ -93,6 +118,12 @@ func (m *Mock) ExecStream(_ context.Context, callback func(line string), name st
 	return nil
 }
 
+func (m *Mock) ExecInput(ctx context.Context, input []byte, callback func(line string), name string, args ...string) error {
+	key := strings.TrimSpace(name + " " + strings.Join(args, " "))
+	m.Inputs[key] = input
+	return m.ExecStream(ctx, callback, name, args...)
+}
+
 func (m *Mock) FileExists(path string) bool {
 	if _, ok := m.Files[path]; ok {
 		return true
@@ -111,10 +142,31 @@ func (m *Mock) ReadFile(path string) ([]byte, error) {
 	return data, nil
 }
 
-func (m *Mock) WriteFile(path string, data []byte, _ os.FileMode) error {
+func (m *Mock) FileMode(path string) (os.FileMode, error) {
+	if _, ok := m.Files[path]; !ok {
+		return 0, fmt.Errorf("arquivo nao encontrado: %s", path)
+	}
+	if mode, ok := m.Modes[path]; ok {
+		return mode, nil
+	}
+	return 0o644, nil
+}
+
+func (m *Mock) Chmod(path string, perm os.FileMode) error {
+	if _, ok := m.Files[path]; !ok {
+		return fmt.Errorf("arquivo nao encontrado: %s", path)
+	}
+	m.Modes[path] = perm
+	return nil
+}
+
+func (m *Mock) WriteFile(path string, data []byte, perm os.FileMode) error {
 	if m.WriteFileErr != nil {
 		return m.WriteFileErr
 	}
+	if _, ok := m.Files[path]; !ok {
+		m.Modes[path] = perm
+	}
 	m.Files[path] = data
 	return nil
 }
@@ -154,6 +206,17 @@ func (m *Mock) CommandExists(name string) bool {
 	return m.Commands[name]
 }
 
+func (m *Mock) AppendFile(path string, data []byte, perm os.FileMode) error {
+	if m.WriteFileErr != nil {
+		return m.WriteFileErr
+	}
+	if _, ok := m.Files[path]; !ok {
+		m.Modes[path] = perm
+	}
+	m.Files[path] = append(append([]byte{}, m.Files[path]...), data...)
+	return nil
+}
+
 func (m *Mock) AppendToFileIfMissing(path, line string) (bool, error) {
 	data, ok := m.Files[path]
 	if ok && strings.Contains(string(data), line) {
@@ -172,3 +235,60 @@ func (m *Mock) AppendToFileIfMissing(path, line string) (bool, error) {
 	m.Files[path] = []byte(existing)
 	return true, nil
 }
+
+func (m *Mock) RemoveLineFromFile(path, line string) (bool, error) {
+	data, ok := m.Files[path]
+	if !ok {
+		return false, nil
+	}
+	content, removed := removeLine(string(data), line)
+	if removed {
+		m.Files[path] = []byte(content)
+	}
+	return removed, nil
+}
+
+func (m *Mock) Remove(path string) error {
+	if m.RemoveErr != nil {
+		return m.RemoveErr
+	}
+	if !m.FileExists(path) {
+		return fmt.Errorf("arquivo nao encontrado: %s", path)
+	}
+	delete(m.Files, path)
+	delete(m.Modes, path)
+	delete(m.Symlinks, path)
+	return nil
+}
+
+func (m *Mock) ReadLink(path string) (string, error) {
+	target, ok := m.Symlinks[path]
+	if !ok {
+		return "", fmt.Errorf("nao e um symlink: %s", path)
+	}
+	return target, nil
+}
+
+func (m *Mock) ExecPrivileged(ctx context.Context, name string, args ...string) (string, error) {
+	return m.Exec(ctx, m.Privilege, append([]string{name}, args...)...)
+}
+
+// WriteFilePrivileged registra o "install" no ExecLog (com modo e dono) e,
+// se ExecResults nao simular uma falha, grava o conteudo em Files.
+func (m *Mock) WriteFilePrivileged(ctx context.Context, path string, data []byte, perm os.FileMode, owner string) error {
+	args := append(modeArgs(perm, owner), path)
+	if _, err := m.ExecPrivileged(ctx, "install", args...); err != nil {
+		return err
+	}
+	m.Files[path] = data
+	return nil
+}
+
+func (m *Mock) Authenticate(_ context.Context) error {
+	m.Authenticated = true
+	return m.AuthenticateErr
+}
+
+func (m *Mock) KeepAlive(_ context.Context) (stop func()) {
+	return func() {}
+}
@@ -129,3 +129,77 @@ func TestMock_ExecStream_Callback(t *testing.T) {
 		t.Errorf("esperava 3 linhas, obteve %d: %v", len(lines), lines)
 	}
 }
+
+func TestMock_ExecInput_RecordsInput(t *testing.T) {
+	mock := NewMock()
+	mock.ExecResults["plugin"] = ExecResult{Output: "resposta"}
+
+	var lines []string
+	err := mock.ExecInput(nil, []byte("pedido"), func(line string) {
+		lines = append(lines, line)
+	}, "plugin")
+
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if string(mock.Inputs["plugin"]) != "pedido" {
+		t.Errorf("input = %q", mock.Inputs["plugin"])
+	}
+	if len(lines) != 1 || lines[0] != "resposta" {
+		t.Errorf("linhas = %v", lines)
+	}
+}
+
+func TestMock_RemoveLineFromFile(t *testing.T) {
+	mock := NewMock()
+	mock.Files["/test/file"] = []byte("primeira\nremover\nsegunda\n")
+
+	removed, err := mock.RemoveLineFromFile("/test/file", "remover")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if !removed {
+		t.Error("deveria retornar true ao remover linha existente")
+	}
+	if got := string(mock.Files["/test/file"]); got != "primeira\nsegunda\n" {
+		t.Errorf("conteudo inesperado: %q", got)
+	}
+}
+
+func TestMock_RemoveLineFromFile_Missing(t *testing.T) {
+	mock := NewMock()
+	mock.Files["/test/file"] = []byte("primeira\n")
+
+	removed, err := mock.RemoveLineFromFile("/test/file", "outra")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if removed {
+		t.Error("nao deveria remover linha inexistente")
+	}
+
+	removed, _ = mock.RemoveLineFromFile("/nao/existe", "linha")
+	if removed {
+		t.Error("arquivo inexistente nao deveria ter linha removida")
+	}
+}
+
+func TestMock_RemoveAndReadLink(t *testing.T) {
+	mock := NewMock()
+	_ = mock.Symlink("/source", "/home/test/link")
+
+	target, err := mock.ReadLink("/home/test/link")
+	if err != nil || target != "/source" {
+		t.Errorf("ReadLink inesperado: %q, %v", target, err)
+	}
+
+	if err := mock.Remove("/home/test/link"); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if mock.FileExists("/home/test/link") {
+		t.Error("link deveria ter sido removido")
+	}
+	if err := mock.Remove("/home/test/link"); err == nil {
+		t.Error("remover arquivo inexistente deveria retornar erro")
+	}
+}
@@ -0,0 +1,103 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ale/blueprint/internal/module"
+)
+
+// Privilege e um backend de elevacao usado por ExecPrivileged e
+// WriteFilePrivileged: o comando executado como root vira
+// "<Name> <Args...> comando args...".
+type Privilege struct {
+	Name string   // sudo, pkexec ou run0
+	Args []string // opcoes do backend antes do comando
+
+	// Prompts indica que o backend pode pedir a senha no terminal (o
+	// agente textual do polkit). Os comandos dele ficam no grupo de
+	// processos do blueprint: num grupo proprio, fora do primeiro plano do
+	// terminal, a leitura da senha pararia o processo com SIGTTIN.
+	Prompts bool
+}
+
+// Backends de elevacao suportados. O sudo roda sem prompt (-n): a senha e
+// pedida uma vez antes da execucao (Authenticator.Authenticate) e a
+// credencial e renovada durante execucoes longas (KeepAlive). pkexec e run0
+// pedem autorizacao ao polkit, que mostra o proprio dialogo.
+var (
+	Sudo   = Privilege{Name: "sudo", Args: []string{"-n"}}
+	Pkexec = Privilege{Name: "pkexec", Prompts: true}
+	Run0   = Privilege{Name: "run0", Prompts: true}
+)
+
+// Privileges lista os backends na ordem de preferencia da deteccao.
+func Privileges() []Privilege {
+	return []Privilege{Sudo, Run0, Pkexec}
+}
+
+// PrivilegeNames e o texto de ajuda da flag --privilege.
+const PrivilegeNames = "auto, sudo, pkexec ou run0"
+
+// ParsePrivilege resolve o backend pelo nome; "auto" (ou vazio) escolhe o
+// primeiro disponivel no PATH (sudo, run0, pkexec). Sem nenhum, fica com o
+// sudo: o erro aparece no primeiro comando privilegiado.
+func ParsePrivilege(name string, sys module.System) (Privilege, error) {
+	if name == "" || name == "auto" {
+		for _, p := range Privileges() {
+			if sys.CommandExists(p.Name) {
+				return p, nil
+			}
+		}
+		return Sudo, nil
+	}
+	for _, p := range Privileges() {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Privilege{}, fmt.Errorf("backend de privilegio invalido: %q (use %s)", name, PrivilegeNames)
+}
+
+// command monta o comando elevado. Quem ja e root executa direto.
+func (p Privilege) command(root bool, name string, args []string) (string, []string) {
+	if root || p.Name == "" {
+		return name, args
+	}
+	argv := append(append(append([]string{}, p.Args...), name), args...)
+	return p.Name, argv
+}
+
+// Authenticator e implementado pelos Systems que precisam autenticar o
+// backend de elevacao antes da execucao, para que o prompt de senha nao
+// apareca no meio do TUI nem trave um comando sem terminal.
+type Authenticator interface {
+	// Authenticate pede a senha uma vez, se o backend precisar.
+	Authenticate(ctx context.Context) error
+
+	// KeepAlive mantem a credencial valida ate ctx terminar ou stop ser
+	// chamado (ex: durante um bluefin-update que passa do timeout do sudo).
+	KeepAlive(ctx context.Context) (stop func())
+}
+
+// FileOwner separa "usuario:grupo" (padrao root:root; sem grupo, o grupo
+// tem o nome do usuario).
+func FileOwner(owner string) (user, group string) {
+	if owner == "" {
+		return "root", "root"
+	}
+	user, group, ok := strings.Cut(owner, ":")
+	if !ok || group == "" {
+		group = user
+	}
+	return user, group
+}
+
+// modeArgs monta as opcoes de modo e dono do "install" que coloca um
+// arquivo de sistema no lugar.
+func modeArgs(perm os.FileMode, owner string) []string {
+	user, group := FileOwner(owner)
+	return []string{"-m", fmt.Sprintf("%04o", perm.Perm()), "-o", user, "-g", group}
+}
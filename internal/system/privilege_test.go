@@ -0,0 +1,81 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePrivilege(t *testing.T) {
+	mock := NewMock()
+	mock.Commands["pkexec"] = true
+	mock.Commands["run0"] = true
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"auto", "run0"},
+		{"", "run0"},
+		{"sudo", "sudo"},
+		{"pkexec", "pkexec"},
+	}
+	for _, tt := range tests {
+		p, err := ParsePrivilege(tt.name, mock)
+		if err != nil || p.Name != tt.want {
+			t.Errorf("ParsePrivilege(%q) = %v, %v; esperava %s", tt.name, p.Name, err, tt.want)
+		}
+	}
+
+	if p, _ := ParsePrivilege("auto", NewMock()); p.Name != "sudo" {
+		t.Errorf("sem backend no PATH deveria ficar com sudo, obteve %s", p.Name)
+	}
+	if _, err := ParsePrivilege("doas", mock); err == nil {
+		t.Error("esperava erro com backend desconhecido")
+	}
+}
+
+func TestPrivilege_Command(t *testing.T) {
+	name, args := Sudo.command(false, "udevadm", []string{"trigger"})
+	if name != "sudo" || !reflect.DeepEqual(args, []string{"-n", "udevadm", "trigger"}) {
+		t.Errorf("sudo: %s %v", name, args)
+	}
+	name, args = Pkexec.command(false, "rm", []string{"-f", "/etc/x"})
+	if name != "pkexec" || !reflect.DeepEqual(args, []string{"rm", "-f", "/etc/x"}) {
+		t.Errorf("pkexec: %s %v", name, args)
+	}
+	name, args = Run0.command(true, "rm", []string{"-f", "/etc/x"})
+	if name != "rm" || !reflect.DeepEqual(args, []string{"-f", "/etc/x"}) {
+		t.Errorf("root deveria executar direto: %s %v", name, args)
+	}
+}
+
+func TestFileOwner(t *testing.T) {
+	tests := []struct{ owner, user, group string }{
+		{"", "root", "root"},
+		{"root:root", "root", "root"},
+		{"gdm", "gdm", "gdm"},
+		{"root:wheel", "root", "wheel"},
+	}
+	for _, tt := range tests {
+		if user, group := FileOwner(tt.owner); user != tt.user || group != tt.group {
+			t.Errorf("FileOwner(%q) = %s:%s", tt.owner, user, group)
+		}
+	}
+}
+
+func TestProcAttr(t *testing.T) {
+	tests := []struct {
+		priv    Privilege
+		setpgid bool
+	}{
+		{Privilege{}, true}, // comando sem elevacao
+		{Sudo, true},        // -n: nunca pede senha
+		{Pkexec, false},
+		{Run0, false},
+	}
+	for _, tt := range tests {
+		if got := procAttr(tt.priv); got.Setpgid != tt.setpgid || got.Foreground {
+			t.Errorf("procAttr(%q) = %+v, esperava Setpgid=%v", tt.priv.Name, got, tt.setpgid)
+		}
+	}
+}
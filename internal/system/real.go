@@ -2,30 +2,79 @@ package system
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
 )
 
+// KillGrace e quanto um comando cancelado tem, apos o SIGTERM, para
+// terminar antes de o grupo de processos receber SIGKILL.
+const KillGrace = 5 * time.Second
+
+// DefaultIdleTimeout e quanto um comando de ExecStream/ExecInput pode ficar
+// sem produzir saida antes de ser encerrado (ver SetIdleTimeout).
+const DefaultIdleTimeout = 15 * time.Minute
+
 // Real implementa System usando chamadas reais ao SO.
-type Real struct{}
+type Real struct {
+	log  *slog.Logger
+	idle time.Duration
+	priv Privilege
+	root bool // ja roda como root: comandos privilegiados executam direto
+}
 
 // NewReal cria uma implementacao real do System.
 func NewReal() *Real {
-	return &Real{}
+	return &Real{log: slog.New(slog.DiscardHandler), idle: DefaultIdleTimeout, priv: Sudo, root: os.Geteuid() == 0}
+}
+
+// SetPrivilege troca o backend de elevacao (padrao: sudo).
+func (r *Real) SetPrivilege(p Privilege) {
+	r.priv = p
+}
+
+// SetIdleTimeout encerra comandos de ExecStream/ExecInput que passarem d
+// sem imprimir uma linha (ex: um rpm-ostree travado). O erro retornado e um
+// *module.TimeoutError. Zero desliga o limite.
+func (r *Real) SetIdleTimeout(d time.Duration) {
+	r.idle = d
+}
+
+// SetLogger registra cada comando (codigo de saida, duracao, stderr) e cada
+// escrita em arquivo no logger (ver internal/logging).
+func (r *Real) SetLogger(l *slog.Logger) {
+	r.log = l
 }
 
 func (r *Real) Exec(ctx context.Context, name string, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
+	return r.exec(ctx, procAttr(Privilege{}), name, args)
+}
+
+// exec executa um comando com os atributos de processo dados e retorna a
+// saida combinada.
+func (r *Real) exec(ctx context.Context, attr *syscall.SysProcAttr, name string, args []string) (string, error) {
+	start := time.Now()
+	cmd := command(ctx, attr, name, args...)
 	out, err := cmd.CombinedOutput()
-	return strings.TrimSpace(string(out)), err
+	output := strings.TrimSpace(string(out))
+	r.logExec(name, args, start, err, output)
+	return output, err
 }
 
 func (r *Real) ExecStream(ctx context.Context, callback func(line string), name string, args ...string) error {
-	cmd := exec.CommandContext(ctx, name, args...)
+	ctx, idle := r.watchIdle(ctx, name)
+	defer idle.stop()
+	cmd := command(ctx, procAttr(Privilege{}), name, args...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("erro ao criar pipe: %w", err)
@@ -36,12 +85,53 @@ func (r *Real) ExecStream(ctx context.Context, callback func(line string), name
 		return fmt.Errorf("erro ao iniciar comando: %w", err)
 	}
 
+	start := time.Now()
 	scanner := bufio.NewScanner(stdout)
 	for scanner.Scan() {
+		idle.reset()
+		r.log.Debug("saida", "cmd", name, "linha", scanner.Text())
 		callback(scanner.Text())
 	}
 
-	return cmd.Wait()
+	err = idle.check(cmd.Wait())
+	r.logExec(name, args, start, err, "")
+	return err
+}
+
+func (r *Real) ExecInput(ctx context.Context, input []byte, callback func(line string), name string, args ...string) error {
+	ctx, idle := r.watchIdle(ctx, name)
+	defer idle.stop()
+	cmd := command(ctx, procAttr(Privilege{}), name, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("erro ao criar pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("erro ao iniciar comando: %w", err)
+	}
+
+	start := time.Now()
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		idle.reset()
+		r.log.Debug("saida", "cmd", name, "linha", scanner.Text())
+		callback(scanner.Text())
+	}
+
+	err = idle.check(cmd.Wait())
+	r.logExec(name, args, start, err, strings.TrimSpace(stderr.String()))
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
 }
 
 func (r *Real) FileExists(path string) bool {
@@ -53,8 +143,24 @@ func (r *Real) ReadFile(path string) ([]byte, error) {
 	return os.ReadFile(path)
 }
 
+func (r *Real) FileMode(path string) (os.FileMode, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Mode(), nil
+}
+
+func (r *Real) Chmod(path string, perm os.FileMode) error {
+	err := os.Chmod(path, perm)
+	r.logWrite("alterar permissoes", path, err, "modo", perm)
+	return err
+}
+
 func (r *Real) WriteFile(path string, data []byte, perm os.FileMode) error {
-	return os.WriteFile(path, data, perm)
+	err := os.WriteFile(path, data, perm)
+	r.logWrite("escrever arquivo", path, err, "bytes", len(data), "modo", perm)
+	return err
 }
 
 func (r *Real) MkdirAll(path string, perm os.FileMode) error {
@@ -68,7 +174,9 @@ func (r *Real) Symlink(oldname, newname string) error {
 			return fmt.Errorf("erro ao remover link existente: %w", err)
 		}
 	}
-	return os.Symlink(oldname, newname)
+	err := os.Symlink(oldname, newname)
+	r.logWrite("criar symlink", newname, err, "destino", oldname)
+	return err
 }
 
 func (r *Real) HomeDir() string {
@@ -111,6 +219,18 @@ func (r *Real) CommandExists(name string) bool {
 	return err == nil
 }
 
+func (r *Real) AppendFile(path string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err == nil {
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	r.logWrite("acrescentar ao arquivo", path, err, "bytes", len(data))
+	return err
+}
+
 func (r *Real) AppendToFileIfMissing(path, line string) (bool, error) {
 	// Garante que o diretorio pai existe
 	dir := filepath.Dir(path)
@@ -149,3 +269,256 @@ func (r *Real) AppendToFileIfMissing(path, line string) (bool, error) {
 
 	return true, nil
 }
+
+func (r *Real) RemoveLineFromFile(path, line string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+
+	content, removed := removeLine(string(data), line)
+	if !removed {
+		return false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, []byte(content), info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("erro ao escrever %s: %w", path, err)
+	}
+	return true, nil
+}
+
+func (r *Real) Remove(path string) error {
+	err := os.Remove(path)
+	r.logWrite("remover", path, err)
+	return err
+}
+
+// ExecPrivileged executa o comando como root pelo backend configurado.
+func (r *Real) ExecPrivileged(ctx context.Context, name string, args ...string) (string, error) {
+	if r.root {
+		return r.Exec(ctx, name, args...)
+	}
+	name, args = r.priv.command(r.root, name, args)
+	return r.exec(ctx, procAttr(r.priv), name, args)
+}
+
+// WriteFilePrivileged grava data em um arquivo temporario privado (0600,
+// so o usuario le) e o instala no destino como root com "install", que
+// aplica modo e dono na mesma operacao.
+func (r *Real) WriteFilePrivileged(ctx context.Context, path string, data []byte, perm os.FileMode, owner string) error {
+	tmp, err := os.CreateTemp("", "blueprint-*")
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo temporario: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao escrever arquivo temporario: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo temporario: %w", err)
+	}
+
+	args := append(modeArgs(perm, owner), tmp.Name(), path)
+	out, err := r.ExecPrivileged(ctx, "install", args...)
+	r.logWrite("escrever arquivo de sistema", path, err, "bytes", len(data), "modo", perm, "dono", owner)
+	if err != nil {
+		if out != "" {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
+
+// Authenticate pede a senha do sudo uma vez, no terminal, antes de o TUI
+// ocupar a tela (os comandos depois rodam com sudo -n). pkexec e run0 pedem
+// autorizacao ao polkit em cada comando; root nao precisa de nada.
+func (r *Real) Authenticate(ctx context.Context) error {
+	if r.root || r.priv.Name != Sudo.Name {
+		return nil
+	}
+	if _, err := r.Exec(ctx, "sudo", "-n", "true"); err == nil {
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, "sudo", "-v", "-p", "Blueprint precisa de acesso root para configurar o sistema.\nSenha de %u: ")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sudo -v falhou: %w", err)
+	}
+	return nil
+}
+
+// KeepAliveInterval e a frequencia com que KeepAlive renova a credencial
+// do sudo (o timeout padrao do sudo e de 5 minutos).
+const KeepAliveInterval = time.Minute
+
+// KeepAlive renova a credencial do sudo (sudo -n -v) a cada
+// KeepAliveInterval, para que um modulo privilegiado no fim de uma execucao
+// longa (ex: depois do bluefin-update) nao falhe por senha expirada.
+func (r *Real) KeepAlive(ctx context.Context) (stop func()) {
+	if r.root || r.priv.Name != Sudo.Name {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(KeepAliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.Exec(ctx, "sudo", "-n", "-v")
+			}
+		}
+	}()
+	return cancel
+}
+
+// procAttr retorna os atributos dos processos executados via p (Privilege{}
+// para comandos sem elevacao): um grupo de processos proprio, exceto quando
+// o backend pode pedir a senha no terminal (ver Privilege.Prompts).
+func procAttr(p Privilege) *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: !p.Prompts}
+}
+
+// command prepara um comando com os atributos attr. Quando ctx e cancelado
+// (Ctrl+C, SIGTERM), o processo recebe SIGTERM e, apos KillGrace, SIGKILL.
+// Num grupo proprio (Setpgid) o sinal vai para o grupo inteiro — assim
+// filhos como "distrobox enter ... setup-dev.sh" ou "rpm-ostree upgrade" nao
+// ficam rodando orfaos.
+func command(ctx context.Context, attr *syscall.SysProcAttr, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = attr
+	cmd.Cancel = func() error {
+		pid := cmd.Process.Pid
+		if attr.Setpgid {
+			pid = -pid
+		}
+		time.AfterFunc(KillGrace, func() {
+			syscall.Kill(pid, syscall.SIGKILL)
+		})
+		return syscall.Kill(pid, syscall.SIGTERM)
+	}
+	// Netos que herdaram stdout/stderr nao seguram o Wait para sempre
+	cmd.WaitDelay = 2 * KillGrace
+	return cmd
+}
+
+// idleWatch cancela o contexto de um comando que fica sem produzir saida.
+type idleWatch struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	timer  *time.Timer
+	limit  time.Duration
+}
+
+// watchIdle deriva de ctx um contexto cancelado apos r.idle sem chamadas a
+// reset. Sem limite configurado, o watch nao faz nada.
+func (r *Real) watchIdle(ctx context.Context, name string) (context.Context, *idleWatch) {
+	w := &idleWatch{limit: r.idle}
+	w.ctx, w.cancel = context.WithCancelCause(ctx)
+	if w.limit > 0 {
+		w.timer = time.AfterFunc(w.limit, func() {
+			w.cancel(&module.TimeoutError{Op: name + " sem saida", After: w.limit})
+		})
+	}
+	return w.ctx, w
+}
+
+// reset reinicia a contagem (uma linha de saida chegou).
+func (w *idleWatch) reset() {
+	if w.timer != nil {
+		w.timer.Reset(w.limit)
+	}
+}
+
+// check troca o erro do comando morto pelo *module.TimeoutError quando foi
+// o limite de inatividade que o encerrou.
+func (w *idleWatch) check(err error) error {
+	var timeoutErr *module.TimeoutError
+	if err != nil && errors.As(context.Cause(w.ctx), &timeoutErr) {
+		return timeoutErr
+	}
+	return err
+}
+
+func (w *idleWatch) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.cancel(nil)
+}
+
+// logExec registra um comando executado. Falhas vao em nivel warn com a
+// saida (ou o stderr) do comando.
+func (r *Real) logExec(name string, args []string, start time.Time, err error, output string) {
+	attrs := []any{
+		"cmd", strings.TrimSpace(name + " " + strings.Join(args, " ")),
+		"exit_code", exitCode(err),
+		"duracao", time.Since(start).Round(time.Millisecond),
+	}
+	if err == nil {
+		r.log.Info("comando", attrs...)
+		return
+	}
+	attrs = append(attrs, "erro", err.Error())
+	if output != "" {
+		attrs = append(attrs, "saida", output)
+	}
+	r.log.Warn("comando falhou", attrs...)
+}
+
+// logWrite registra uma alteracao no sistema de arquivos.
+func (r *Real) logWrite(op, path string, err error, attrs ...any) {
+	attrs = append([]any{"path", path}, attrs...)
+	if err != nil {
+		r.log.Warn(op+" falhou", append(attrs, "erro", err.Error())...)
+		return
+	}
+	r.log.Debug(op, attrs...)
+}
+
+// exitCode extrai o codigo de saida de um erro de exec (-1 se o comando nem
+// chegou a terminar, ex: nao encontrado ou cancelado).
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+func (r *Real) ReadLink(path string) (string, error) {
+	return os.Readlink(path)
+}
+
+// removeLine remove de content as linhas iguais a line (ignorando espacos nas pontas).
+// Compartilhado entre Real e Mock para manter o mesmo comportamento.
+func removeLine(content, line string) (string, bool) {
+	target := strings.TrimSpace(line)
+	lines := strings.SplitAfter(content, "\n")
+	kept := lines[:0]
+	removed := false
+	for _, l := range lines {
+		if l != "" && strings.TrimSpace(l) == target {
+			removed = true
+			continue
+		}
+		kept = append(kept, l)
+	}
+	return strings.Join(kept, ""), removed
+}
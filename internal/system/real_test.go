@@ -0,0 +1,144 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ale/blueprint/internal/module"
+)
+
+func TestReal_CancelKillsProcessGroup(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep nao disponivel")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// O shell inicia um neto em background e espera; cancelar deve matar os dois
+	pids := make(chan int, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- NewReal().ExecStream(ctx, func(line string) {
+			if pid, err := strconv.Atoi(strings.TrimSpace(line)); err == nil {
+				pids <- pid
+			}
+		}, "sh", "-c", "sleep 30 & echo $!; wait")
+	}()
+
+	var child int
+	select {
+	case child = <-pids:
+	case <-time.After(5 * time.Second):
+		t.Fatal("o shell nao informou o pid do filho")
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("esperava erro de um comando cancelado")
+		}
+	case <-time.After(KillGrace):
+		t.Fatal("ExecStream nao retornou apos o cancelamento")
+	}
+
+	// O neto morre junto (ou vira zumbi esperando ser coletado pelo init)
+	deadline := time.Now().Add(2 * time.Second)
+	for alive(child) {
+		if time.Now().After(deadline) {
+			t.Fatalf("processo %d continuou rodando apos o cancelamento", child)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// alive verifica se o processo existe e nao e zumbi.
+func alive(pid int) bool {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	// Formato: pid (comm) estado ...
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestReal_IdleTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep nao disponivel")
+	}
+	r := NewReal()
+	r.SetIdleTimeout(200 * time.Millisecond)
+
+	// Linhas a cada 100ms mantem o comando vivo; depois ele trava em silencio
+	var lines []string
+	start := time.Now()
+	err := r.ExecStream(context.Background(), func(line string) {
+		lines = append(lines, line)
+	}, "sh", "-c", "for i in 1 2 3; do echo $i; sleep 0.1; done; sleep 30")
+
+	var timeoutErr *module.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("err = %v, esperava *module.TimeoutError", err)
+	}
+	if timeoutErr.After != 200*time.Millisecond || !strings.HasPrefix(timeoutErr.Op, "sh") {
+		t.Errorf("timeout = %+v", timeoutErr)
+	}
+	if len(lines) != 3 {
+		t.Errorf("linhas = %v, esperava as 3 antes do travamento", lines)
+	}
+	if elapsed := time.Since(start); elapsed > KillGrace {
+		t.Errorf("comando levou %s para ser encerrado", elapsed)
+	}
+}
+
+func TestReal_IdleTimeoutDisabled(t *testing.T) {
+	r := NewReal()
+	r.SetIdleTimeout(0)
+	if err := r.ExecStream(context.Background(), func(string) {}, "sh", "-c", "sleep 0.2; echo ok"); err != nil {
+		t.Fatalf("ExecStream: %v", err)
+	}
+}
+
+func TestReal_FileModeAndChmod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run")
+	r := NewReal()
+	if err := r.WriteFile(path, []byte("#!/bin/sh\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Chmod(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	mode, err := r.FileMode(path)
+	if err != nil || mode.Perm() != 0o755 || mode.IsDir() {
+		t.Errorf("FileMode() = %v, %v; esperava 0755", mode, err)
+	}
+	if mode, _ := r.FileMode(filepath.Dir(path)); !mode.IsDir() {
+		t.Error("FileMode de um diretorio deveria ter IsDir")
+	}
+}
+
+func TestReal_AppendFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	r := NewReal()
+	for _, line := range []string{"a\n", "b\n"} {
+		if err := r.AppendFile(path, []byte(line), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "a\nb\n" {
+		t.Errorf("conteudo = %q", data)
+	}
+	if mode, _ := r.FileMode(path); mode.Perm() != 0o600 {
+		t.Errorf("modo = %04o, esperava 0600", mode.Perm())
+	}
+}
@@ -1,9 +1,15 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/ale/blueprint/internal/history"
 	"github.com/ale/blueprint/internal/module"
+	"github.com/ale/blueprint/internal/orchestrator"
 	"github.com/ale/blueprint/internal/profile"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -15,25 +21,72 @@ const (
 	screenWelcome screen = iota
 	screenProfileSelect
 	screenModuleConfirm
+	screenOptions
 	screenExecute
 	screenSummary
 )
 
+// action define a operacao executada pelo TUI.
+type action int
+
+const (
+	actionApply  action = iota // Guard -> Check -> Apply
+	actionRemove               // Guard -> Check -> Revert
+)
+
+// Options ajusta a execucao iniciada pelo TUI.
+type Options struct {
+	Jobs      int                     // modulos executados em paralelo (ver orchestrator.SetJobs)
+	Observers []orchestrator.Observer // recebem os eventos da execucao (ex: historico)
+	Timeout   time.Duration           // limite padrao por modulo (ver orchestrator.SetTimeout)
+	Logger    *slog.Logger            // log da execucao (ver orchestrator.SetLogger)
+	LogPath   string                  // arquivo de log exibido no resumo
+
+	// Pending detecta acoes de sessao pendentes fora dos modulos (ex:
+	// session.Pending); o resultado entra na secao de acoes do resumo.
+	Pending func(context.Context) []module.SessionRequest
+
+	// Previous sao as fases anteriores de um apply retomado apos o reboot
+	// (blueprint apply --resume): o TUI pula a escolha do perfil e mostra
+	// os resultados delas no resumo.
+	Previous []history.Run
+
+	// Resolve monta a lista de modulos de um perfil (padrao: profile.Resolve).
+	// Permite aplicar a configuracao do usuario tambem na troca de perfil.
+	Resolve func(profile.Profile) []module.Module
+}
+
+// ErrCancelled e retornado por Run e RunRemove quando a execucao foi
+// cancelada (Ctrl+C/esc no TUI ou sinal no processo).
+var ErrCancelled = errors.New("execucao cancelada")
+
+// ErrFailed e retornado por Run quando algum modulo terminou com erro. O
+// codigo de saida vem dos resultados (report.ExitCode), como no headless.
+var ErrFailed = errors.New("execucao concluida com erros")
+
+// cancelMsg avisa que o contexto recebido por Run foi cancelado (SIGTERM).
+type cancelMsg struct{}
+
 // model e o Model raiz do Bubble Tea (state machine de telas).
 type model struct {
+	ctx          context.Context
 	screen       screen
+	action       action
 	registry     *module.Registry
 	modules      []module.Module
 	sys          module.System
+	opts         Options
 	profile      profile.Profile
 	autoDetected bool
 	width        int
 	height       int
+	quitAfterRun bool // sinal recebido durante a execucao: sair ao terminar
 
 	// Estado das telas
 	welcome       welcomeModel
 	profileSelect profileSelectModel
 	moduleConfirm moduleConfirmModel
+	options       optionsModel
 	execute       executeModel
 	summary       summaryModel
 }
@@ -41,8 +94,15 @@ type model struct {
 // Run inicia o TUI interativo.
 // Recebe o registry completo para que a troca de perfil no TUI funcione corretamente.
 // Se autoDetected=true, pula a selecao de perfil e vai direto para confirmacao de modulos.
-func Run(registry *module.Registry, sys module.System, prof profile.Profile, autoDetected bool) error {
-	modules := profile.Resolve(prof, registry)
+// Cancelar ctx (ou Ctrl+C/esc durante a execucao) interrompe os modulos e
+// retorna ErrCancelled.
+func Run(ctx context.Context, registry *module.Registry, sys module.System, prof profile.Profile, autoDetected bool, opts Options) error {
+	if opts.Resolve == nil {
+		opts.Resolve = func(p profile.Profile) []module.Module {
+			return profile.Resolve(p, registry)
+		}
+	}
+	modules := opts.Resolve(prof)
 
 	// Se o perfil foi auto-detectado, pula direto para confirmacao de modulos
 	initialScreen := screenWelcome
@@ -51,10 +111,12 @@ func Run(registry *module.Registry, sys module.System, prof profile.Profile, aut
 	}
 
 	m := model{
+		ctx:          ctx,
 		screen:       initialScreen,
 		registry:     registry,
 		modules:      modules,
 		sys:          sys,
+		opts:         opts,
 		profile:      prof,
 		autoDetected: autoDetected,
 		welcome:      newWelcomeModel(),
@@ -66,32 +128,124 @@ func Run(registry *module.Registry, sys module.System, prof profile.Profile, aut
 		m.moduleConfirm.autoDetected = true
 	}
 
+	// Apply retomado: o perfil foi escolhido na primeira fase
+	if len(opts.Previous) > 0 {
+		m.screen = screenModuleConfirm
+		m.moduleConfirm = newModuleConfirmModel(modules)
+		m.moduleConfirm.profile = prof
+		m.moduleConfirm.title = fmt.Sprintf("Continuar apply apos o reboot (fase %d)", len(opts.Previous)+1)
+	}
+
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	finalModel, err := p.Run()
+	fm, ok := finalModel.(model)
+	if ok {
+		fm.execute.wait()
+	}
 	if err != nil {
 		return fmt.Errorf("erro no TUI: %w", err)
 	}
 
 	// Verifica se houve erros na execucao
-	if fm, ok := finalModel.(model); ok && fm.summary.hasErrors {
-		return fmt.Errorf("execucao concluida com erros")
+	if ok {
+		if fm.cancelled() {
+			return ErrCancelled
+		}
+		if fm.summary.hasErrors {
+			return ErrFailed
+		}
+	}
+
+	return nil
+}
+
+// RunRemove inicia o TUI de remocao: confirma os modulos, executa
+// Guard -> Check -> Revert e mostra o que foi restaurado.
+func RunRemove(ctx context.Context, modules []module.Module, sys module.System, opts Options) error {
+	modules = orchestrator.RemovalOrder(modules)
+
+	m := model{
+		ctx:           ctx,
+		screen:        screenModuleConfirm,
+		action:        actionRemove,
+		modules:       modules,
+		sys:           sys,
+		opts:          opts,
+		moduleConfirm: newModuleConfirmModel(modules),
+	}
+	m.moduleConfirm.title = "Remover modulos"
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	fm, ok := finalModel.(model)
+	if ok {
+		fm.execute.wait()
+	}
+	if err != nil {
+		return fmt.Errorf("erro no TUI: %w", err)
+	}
+
+	if ok {
+		if fm.cancelled() {
+			return ErrCancelled
+		}
+		if fm.summary.hasErrors {
+			return fmt.Errorf("remocao concluida com erros")
+		}
 	}
 
 	return nil
 }
 
+// cancelled indica se a execucao foi cancelada (com ou sem resumo parcial).
+func (m model) cancelled() bool {
+	switch m.screen {
+	case screenExecute:
+		return m.execute.cancelling
+	case screenSummary:
+		return m.summary.cancelled
+	}
+	return false
+}
+
 func (m model) Init() tea.Cmd {
-	return m.welcome.Init()
+	return tea.Batch(m.welcome.Init(), waitForCancel(m.ctx))
+}
+
+// waitForCancel entrega cancelMsg quando ctx for cancelado.
+func waitForCancel(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		<-ctx.Done()
+		return cancelMsg{}
+	}
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	// Ctrl+C sai de qualquer tela
+	running := m.screen == screenExecute && !m.execute.done
+
+	// Ctrl+C sai de qualquer tela. Durante a execucao, o primeiro Ctrl+C
+	// cancela os modulos e mostra o resumo parcial; o segundo fecha o TUI
+	// sem o resumo (Run ainda espera o comando interrompido terminar).
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		if keyMsg.String() == "ctrl+c" {
+			if running && !m.execute.cancelling {
+				m.execute = m.execute.cancel()
+				return m, nil
+			}
 			return m, tea.Quit
 		}
 	}
 
+	// Sinal (SIGTERM): cancela a execucao e sai assim que ela terminar
+	if _, ok := msg.(cancelMsg); ok {
+		if running {
+			m.execute = m.execute.cancel()
+			m.quitAfterRun = true
+			return m, nil
+		}
+		return m, tea.Quit
+	}
+
 	if ws, ok := msg.(tea.WindowSizeMsg); ok {
 		m.width = ws.Width
 		m.height = ws.Height
@@ -106,6 +260,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateProfileSelect(msg)
 	case screenModuleConfirm:
 		return m.updateModuleConfirm(msg)
+	case screenOptions:
+		return m.updateOptions(msg)
 	case screenExecute:
 		return m.updateExecute(msg)
 	case screenSummary:
@@ -123,6 +279,8 @@ func (m model) View() string {
 		return m.profileSelect.View()
 	case screenModuleConfirm:
 		return m.moduleConfirm.View()
+	case screenOptions:
+		return m.options.View()
 	case screenExecute:
 		return m.execute.View()
 	case screenSummary:
@@ -153,7 +311,7 @@ func (m model) updateProfileSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.profileSelect.done {
 		m.profile = m.profileSelect.selected
 		// Resolve modulos a partir do registry completo
-		m.modules = profile.Resolve(m.profile, m.registry)
+		m.modules = m.opts.Resolve(m.profile)
 
 		m.screen = screenModuleConfirm
 		m.moduleConfirm = newModuleConfirmModel(m.modules)
@@ -169,17 +327,42 @@ func (m model) updateModuleConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.moduleConfirm, cmd = m.moduleConfirm.Update(msg)
 
 	if m.moduleConfirm.done {
-		selectedModules := m.moduleConfirm.selectedModules()
-		m.screen = screenExecute
-		m.execute = newExecuteModel(selectedModules, m.sys)
-		m.execute.width = m.width
-		m.execute.height = m.height
-		return m, m.execute.Init()
+		// No apply, modulos com opcoes passam pela tela de opcoes antes
+		if m.action == actionApply {
+			m.options = newOptionsModel(m.moduleConfirm.selectedModules())
+			if !m.options.empty() {
+				m.screen = screenOptions
+				return m, m.options.Init()
+			}
+		}
+		return m.startExecute()
+	}
+
+	return m, cmd
+}
+
+func (m model) updateOptions(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.options, cmd = m.options.Update(msg)
+
+	if m.options.done {
+		return m.startExecute()
 	}
 
 	return m, cmd
 }
 
+// startExecute inicia a execucao dos modulos selecionados.
+func (m model) startExecute() (tea.Model, tea.Cmd) {
+	m.screen = screenExecute
+	m.execute = newExecuteModel(m.ctx, m.moduleConfirm.selectedModules(), m.sys, m.opts, m.moduleConfirm.deselectedNames())
+	m.execute.action = m.action
+	m.execute.width = m.width
+	m.execute.height = m.height
+	m.execute.start()
+	return m, m.execute.Init()
+}
+
 func (m model) updateExecute(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	m.execute, cmd = m.execute.Update(msg)
@@ -187,7 +370,13 @@ func (m model) updateExecute(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.execute.done {
 		m.screen = screenSummary
 		m.summary = newSummaryModel(m.execute.results)
-		return m, nil
+		m.summary.logPath = m.opts.LogPath
+		m.summary.action = m.action
+		m.summary.previous = m.opts.Previous
+		if m.quitAfterRun {
+			return m, tea.Quit
+		}
+		return m, m.summary.detectPending(m.ctx, m.opts.Pending)
 	}
 
 	return m, cmd
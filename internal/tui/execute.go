@@ -14,74 +14,14 @@ import (
 
 // Eventos enviados pela goroutine de processamento.
 
-type logStyle int
-
-const (
-	logInfo logStyle = iota
-	logSuccess
-	logWarn
-	logError
-	logStep
-)
-
+// logEvent e uma linha do painel de log.
 type logEvent struct {
-	style logStyle
+	level orchestrator.LogLevel
 	text  string
 }
 
-type resultEvent struct {
-	index  int
-	result orchestrator.Result
-}
-
 type allDoneEvent struct{}
 
-// setRunningEvent marca um modulo como "running".
-type setRunningEvent struct {
-	index int
-}
-
-// channelReporter implementa module.Reporter enviando eventos pelo channel.
-type channelReporter struct {
-	ch chan<- any
-}
-
-func (r *channelReporter) Info(msg string) {
-	r.ch <- logEvent{style: logInfo, text: msg}
-}
-
-func (r *channelReporter) Success(msg string) {
-	r.ch <- logEvent{style: logSuccess, text: msg}
-}
-
-func (r *channelReporter) Warn(msg string) {
-	r.ch <- logEvent{style: logWarn, text: msg}
-}
-
-func (r *channelReporter) Error(msg string) {
-	r.ch <- logEvent{style: logError, text: msg}
-}
-
-func (r *channelReporter) Step(current, total int, msg string) {
-	r.ch <- logEvent{style: logStep, text: fmt.Sprintf("[%d/%d] %s", current, total, msg)}
-}
-
-// notingReporter captura mensagens Info como notes alem de repassar ao reporter.
-type notingReporter struct {
-	inner *channelReporter
-	notes []string
-}
-
-func (r *notingReporter) Info(msg string) {
-	r.notes = append(r.notes, msg)
-	r.inner.Info(msg)
-}
-
-func (r *notingReporter) Success(msg string)                  { r.inner.Success(msg) }
-func (r *notingReporter) Warn(msg string)                     { r.inner.Warn(msg) }
-func (r *notingReporter) Error(msg string)                    { r.inner.Error(msg) }
-func (r *notingReporter) Step(current, total int, msg string) { r.inner.Step(current, total, msg) }
-
 // moduleStatus representa o estado de um modulo durante a execucao.
 type moduleStatus int
 
@@ -91,6 +31,8 @@ const (
 	statusDone
 	statusSkipped
 	statusError
+	statusTimedOut
+	statusCancelled
 )
 
 // moduleState rastreia o estado de cada modulo durante a execucao.
@@ -98,90 +40,181 @@ type moduleState struct {
 	mod     module.Module
 	status  moduleStatus
 	message string // resumo (ex: "ja instalado", "sem sessao grafica")
+	result  *orchestrator.Result
 }
 
 // executeModel mostra o progresso da execucao com atualizacao em tempo real.
 type executeModel struct {
-	states  []moduleState
-	sys     module.System
-	spinner spinner.Model
-	ch      chan any
-	allLogs []logEvent // buffer acumulativo de TODOS os logs (nunca limpa)
-	results []orchestrator.Result
-	done    bool
-	width   int
-	height  int
+	ctx        context.Context    // cancelado por Ctrl+C/esc ou sinal no processo
+	stop       context.CancelFunc // cancela ctx
+	cancelling bool               // cancelamento pedido, esperando os modulos pararem
+	action     action
+	states     []moduleState
+	index      map[string]int // nome do modulo -> posicao em states
+	sys        module.System
+	opts       Options
+	deselected []string // modulos desmarcados no TUI (bloqueiam dependentes)
+	spinner    spinner.Model
+	ch         chan orchestrator.Event
+	allLogs    []logEvent // buffer acumulativo de TODOS os logs (nunca limpa)
+	results    []orchestrator.Result
+	done       bool
+	width      int
+	height     int
 }
 
-func newExecuteModel(modules []module.Module, sys module.System) executeModel {
+func newExecuteModel(parent context.Context, modules []module.Module, sys module.System, opts Options, deselected []string) executeModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = highlightStyle
 
 	states := make([]moduleState, len(modules))
+	index := make(map[string]int, len(modules))
 	for i, mod := range modules {
 		states[i] = moduleState{mod: mod, status: statusPending}
+		index[mod.Name()] = i
 	}
 
+	ctx, stop := context.WithCancel(parent)
 	return executeModel{
-		states:  states,
-		sys:     sys,
-		spinner: s,
-		ch:      make(chan any, 16),
+		ctx:        ctx,
+		stop:       stop,
+		states:     states,
+		index:      index,
+		sys:        sys,
+		opts:       opts,
+		deselected: deselected,
+		spinner:    s,
+		ch:         make(chan orchestrator.Event, 16),
 	}
 }
 
+// Init anima o spinner e passa a ler os eventos; a execucao em si comeca em
+// start, fora dos comandos do bubbletea.
 func (m executeModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
-		m.processModules(),
 		m.waitForEvent(),
 	)
 }
 
+// start executa os modulos em uma goroutine propria. Ela sempre termina
+// fechando m.ch, o que permite a wait esperar por ela mesmo que o TUI saia
+// antes de os comandos pendentes do bubbletea rodarem.
+func (m executeModel) start() {
+	go m.process()
+}
+
+// wait espera a execucao terminar depois que o TUI saiu (ex: segundo
+// Ctrl+C): cancela os modulos e descarta os eventos restantes ate o channel
+// fechar. Depois dela nenhum observer roda mais, entao quem chamou Run pode
+// ler os resultados guardados pelos observers sem corrida.
+func (m executeModel) wait() {
+	if m.ch == nil {
+		return
+	}
+	m.stop()
+	for range m.ch {
+	}
+}
+
 func (m executeModel) Update(msg tea.Msg) (executeModel, tea.Cmd) {
 	switch msg := msg.(type) {
-	case setRunningEvent:
-		m.states[msg.index].status = statusRunning
+	case orchestrator.Event:
+		m = m.handleEvent(msg)
 		return m, m.waitForEvent()
 
-	case logEvent:
-		m.allLogs = append(m.allLogs, msg)
-		return m, m.waitForEvent()
+	case tea.KeyMsg:
+		if msg.String() == "esc" && !m.done && !m.cancelling {
+			return m.cancel(), nil
+		}
+		return m, nil
 
-	case resultEvent:
-		r := msg.result
-		st := &m.states[msg.index]
+	case allDoneEvent:
+		m.stop()
+		// Resultados na ordem da lista (modulos paralelos terminam fora de ordem)
+		m.results = m.results[:0]
+		for _, st := range m.states {
+			if st.result != nil {
+				m.results = append(m.results, *st.result)
+			}
+		}
+		m.done = true
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// cancel interrompe a execucao: o comando em andamento e morto (ver
+// system.Real) e os modulos restantes nao iniciam.
+func (m executeModel) cancel() executeModel {
+	m.stop()
+	m.cancelling = true
+	m.allLogs = append(m.allLogs, logEvent{
+		level: orchestrator.LogWarn,
+		text:  "Cancelando... (Ctrl+C de novo fecha o TUI assim que o comando em andamento parar)",
+	})
+	return m
+}
+
+// handleEvent atualiza o estado do modulo e o log a partir de um evento do orchestrator.
+func (m executeModel) handleEvent(ev orchestrator.Event) executeModel {
+	i, ok := m.index[ev.Module.Name()]
+	if !ok {
+		return m
+	}
+	st := &m.states[i]
+
+	switch ev.Kind {
+	case orchestrator.ModuleStarted:
+		st.status = statusRunning
+
+	case orchestrator.LogLine:
+		text := ev.Text
+		if ev.Level == orchestrator.LogStep {
+			text = fmt.Sprintf("[%d/%d] %s", ev.Current, ev.Total, ev.Text)
+		}
+		m.allLogs = append(m.allLogs, logEvent{level: ev.Level, text: text})
+
+	case orchestrator.ModuleFinished:
+		r := ev.Result
+		st.result = &r
 
 		switch {
+		case r.Cancelled:
+			st.status = statusCancelled
+			st.message = r.Reason
 		case r.Skipped:
 			st.status = statusSkipped
 			st.message = r.Reason
+		case r.TimedOut:
+			st.status = statusTimedOut
+			st.message = r.Err.Error()
 		case r.Err != nil:
 			st.status = statusError
 			st.message = r.Err.Error()
 		case r.Applied:
 			st.status = statusDone
 			st.message = "aplicado"
+		case r.Reverted:
+			st.status = statusDone
+			st.message = "removido"
+		case m.action == actionRemove:
+			st.status = statusDone
+			st.message = "nada a remover"
 		default:
 			st.status = statusDone
 			st.message = "ja instalado"
 		}
-
-		m.results = append(m.results, r)
-		return m, m.waitForEvent()
-
-	case allDoneEvent:
-		m.done = true
-		return m, nil
-
-	case spinner.TickMsg:
-		var cmd tea.Cmd
-		m.spinner, cmd = m.spinner.Update(msg)
-		return m, cmd
 	}
 
-	return m, nil
+	return m
 }
 
 func (m executeModel) View() string {
@@ -218,7 +251,13 @@ func (m executeModel) View() string {
 
 	if !m.done {
 		left.WriteString(m.spinner.View())
-		left.WriteString(" Aplicando configuracoes...\n\n")
+		if m.cancelling {
+			left.WriteString(" Cancelando...\n\n")
+		} else if m.action == actionRemove {
+			left.WriteString(" Removendo modulos...\n\n")
+		} else {
+			left.WriteString(" Aplicando configuracoes...\n\n")
+		}
 	} else {
 		left.WriteString("Concluido.\n\n")
 	}
@@ -236,6 +275,12 @@ func (m executeModel) View() string {
 		case statusError:
 			icon = errorStyle.Render("✗")
 			line = fmt.Sprintf("%s %s", st.mod.Name(), errorStyle.Render("— "+st.message))
+		case statusTimedOut:
+			icon = errorStyle.Render("⏱")
+			line = fmt.Sprintf("%s %s", st.mod.Name(), errorStyle.Render("— "+st.message))
+		case statusCancelled:
+			icon = warningStyle.Render("■")
+			line = fmt.Sprintf("%s %s", st.mod.Name(), warningStyle.Render("— "+st.message))
 		case statusRunning:
 			icon = m.spinner.View()
 			line = st.mod.Name()
@@ -278,110 +323,36 @@ func (m executeModel) View() string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, rightPanel)
 }
 
-// processModules processa os modulos um a um, replicando a logica do
-// orchestrator.runOne (Guard → Check → Apply). Manter em sincronia com
-// orchestrator.go se a logica de execucao mudar.
-//
-// Nota: a goroutine nao e cancelavel. Em caso de Ctrl+C o processo encerra
-// e a goroutine morre junto — aceitavel para uma ferramenta CLI.
-func (m executeModel) processModules() tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		reporter := &channelReporter{ch: m.ch}
-
-		for i, st := range m.states {
-			mod := st.mod
-
-			// 1. Guard — decide antes de marcar como running
-			if guard, ok := mod.(module.Guard); ok {
-				shouldRun, reason := guard.ShouldRun(ctx, m.sys)
-				if !shouldRun {
-					m.ch <- logEvent{style: logWarn, text: fmt.Sprintf("%s: %s", mod.Name(), reason)}
-					m.ch <- resultEvent{
-						index: i,
-						result: orchestrator.Result{
-							Module:  mod,
-							Skipped: true,
-							Reason:  reason,
-							Status:  module.Status{Kind: module.Skipped, Message: reason},
-						},
-					}
-					continue
-				}
-			}
+// process executa os modulos pelo orchestrator (o mesmo pipeline do modo
+// headless) e repassa os eventos ao TUI pelo channel, fechado no fim.
+// Cancelar m.ctx interrompe o modulo em andamento; os seguintes terminam
+// como cancelados.
+func (m executeModel) process() {
+	defer close(m.ch)
 
-			// Marca como running so depois do guard passar
-			m.ch <- setRunningEvent{index: i}
-
-			// 2. Check
-			var checkStatus module.Status
-			if checker, ok := mod.(module.Checker); ok {
-				status, err := checker.Check(ctx, m.sys)
-				if err != nil {
-					reporter.Error(fmt.Sprintf("%s: erro ao verificar — %v", mod.Name(), err))
-					m.ch <- resultEvent{
-						index: i,
-						result: orchestrator.Result{
-							Module: mod,
-							Err:    err,
-						},
-					}
-					continue
-				}
-				checkStatus = status
-
-				if status.Kind == module.Installed {
-					msg := status.Message
-					if msg == "" {
-						msg = "ja instalado"
-					}
-					m.ch <- logEvent{style: logSuccess, text: fmt.Sprintf("%s: %s", mod.Name(), msg)}
-					m.ch <- resultEvent{
-						index: i,
-						result: orchestrator.Result{
-							Module: mod,
-							Status: status,
-						},
-					}
-					continue
-				}
-			}
+	modules := make([]module.Module, len(m.states))
+	for i, st := range m.states {
+		modules[i] = st.mod
+	}
 
-			// 3. Apply
-			if applier, ok := mod.(module.Applier); ok {
-				nr := &notingReporter{inner: reporter}
-				if err := applier.Apply(ctx, m.sys, nr); err != nil {
-					reporter.Error(fmt.Sprintf("%s: erro ao aplicar — %v", mod.Name(), err))
-					m.ch <- resultEvent{
-						index: i,
-						result: orchestrator.Result{
-							Module: mod,
-							Status: checkStatus,
-							Err:    err,
-						},
-					}
-					continue
-				}
-				m.ch <- resultEvent{
-					index: i,
-					result: orchestrator.Result{
-						Module:  mod,
-						Status:  checkStatus,
-						Applied: true,
-						Notes:   nr.notes,
-					},
-				}
-			} else {
-				// Modulo sem Apply — marca como done
-				m.ch <- resultEvent{
-					index: i,
-					result: orchestrator.Result{Module: mod},
-				}
-			}
-		}
+	orch := orchestrator.New(m.sys, nil)
+	orch.SetJobs(m.opts.Jobs)
+	orch.SetTimeout(m.opts.Timeout)
+	if m.opts.Logger != nil {
+		orch.SetLogger(m.opts.Logger)
+	}
+	orch.Deselect(m.deselected...)
+	for _, obs := range m.opts.Observers {
+		orch.Observe(obs)
+	}
+	orch.Observe(func(ev orchestrator.Event) {
+		m.ch <- ev
+	})
 
-		close(m.ch)
-		return nil
+	if m.action == actionRemove {
+		orch.Remove(m.ctx, modules)
+	} else {
+		orch.Run(m.ctx, modules)
 	}
 }
 
@@ -398,14 +369,14 @@ func (m executeModel) waitForEvent() tea.Cmd {
 
 // formatLog formata uma logEvent para exibicao.
 func (m executeModel) formatLog(ev logEvent) string {
-	switch ev.style {
-	case logSuccess:
+	switch ev.level {
+	case orchestrator.LogSuccess:
 		return successStyle.Render("[OK] " + ev.text)
-	case logWarn:
+	case orchestrator.LogWarn:
 		return warningStyle.Render("[WARN] " + ev.text)
-	case logError:
+	case orchestrator.LogError:
 		return errorStyle.Render("[ERRO] " + ev.text)
-	case logStep:
+	case orchestrator.LogStep:
 		return highlightStyle.Render(ev.text)
 	default:
 		return mutedStyle.Render(ev.text)
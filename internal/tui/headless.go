@@ -1,32 +1,44 @@
 package tui
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
 // HeadlessReporter implementa Reporter para modo headless (sem TUI).
 // Usa saida textual simples com prefixos.
-type HeadlessReporter struct{}
+type HeadlessReporter struct {
+	out io.Writer
+}
 
 // NewHeadlessReporter cria um reporter para modo headless.
 func NewHeadlessReporter() *HeadlessReporter {
-	return &HeadlessReporter{}
+	return &HeadlessReporter{out: os.Stdout}
+}
+
+// NewHeadlessReporterTo cria um reporter headless que escreve em w (ex:
+// stderr, quando o stdout esta reservado para --output json).
+func NewHeadlessReporterTo(w io.Writer) *HeadlessReporter {
+	return &HeadlessReporter{out: w}
 }
 
 func (r *HeadlessReporter) Info(msg string) {
-	fmt.Printf("  [INFO] %s\n", msg)
+	fmt.Fprintf(r.out, "  [INFO] %s\n", msg)
 }
 
 func (r *HeadlessReporter) Success(msg string) {
-	fmt.Printf("  [OK]   %s\n", msg)
+	fmt.Fprintf(r.out, "  [OK]   %s\n", msg)
 }
 
 func (r *HeadlessReporter) Warn(msg string) {
-	fmt.Printf("  [WARN] %s\n", msg)
+	fmt.Fprintf(r.out, "  [WARN] %s\n", msg)
 }
 
 func (r *HeadlessReporter) Error(msg string) {
-	fmt.Printf("  [ERRO] %s\n", msg)
+	fmt.Fprintf(r.out, "  [ERRO] %s\n", msg)
 }
 
 func (r *HeadlessReporter) Step(current, total int, msg string) {
-	fmt.Printf("  [%d/%d] %s\n", current, total, msg)
+	fmt.Fprintf(r.out, "  [%d/%d] %s\n", current, total, msg)
 }
@@ -22,6 +22,7 @@ type moduleConfirmModel struct {
 	done         bool
 	profile      profile.Profile
 	autoDetected bool
+	title        string // titulo customizado (ex: "Remover modulos")
 }
 
 func newModuleConfirmModel(modules []module.Module) moduleConfirmModel {
@@ -69,7 +70,11 @@ func (m moduleConfirmModel) View() string {
 		b.WriteString("\n\n")
 		b.WriteString("Modulos selecionados:\n\n")
 	} else {
-		b.WriteString(titleStyle.Render("Confirmar modulos"))
+		title := m.title
+		if title == "" {
+			title = "Confirmar modulos"
+		}
+		b.WriteString(titleStyle.Render(title))
 		b.WriteString("\n\n")
 	}
 
@@ -88,7 +93,12 @@ func (m moduleConfirmModel) View() string {
 		desc := mutedStyle.Render(fmt.Sprintf(" — %s", e.mod.Description()))
 		tags := mutedStyle.Render(fmt.Sprintf(" [%s]", strings.Join(e.mod.Tags(), ", ")))
 
-		b.WriteString(fmt.Sprintf("%s%s %s%s%s\n", cursor, check, name, desc, tags))
+		requires := ""
+		if deps := module.Requirements(e.mod); len(deps) > 0 {
+			requires = mutedStyle.Render(fmt.Sprintf(" (requer %s)", strings.Join(deps, ", ")))
+		}
+
+		b.WriteString(fmt.Sprintf("%s%s %s%s%s%s\n", cursor, check, name, desc, tags, requires))
 	}
 
 	b.WriteString("\n")
@@ -106,3 +116,15 @@ func (m moduleConfirmModel) selectedModules() []module.Module {
 	}
 	return result
 }
+
+// deselectedNames retorna os nomes dos modulos desmarcados pelo usuario.
+// Modulos que dependem deles serao pulados na execucao.
+func (m moduleConfirmModel) deselectedNames() []string {
+	var result []string
+	for _, e := range m.entries {
+		if !e.selected {
+			result = append(result, e.mod.Name())
+		}
+	}
+	return result
+}
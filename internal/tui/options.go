@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ale/blueprint/internal/module"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// optionField e um campo editavel de uma opcao de modulo.
+type optionField struct {
+	mod    module.Configurable
+	name   string // nome do modulo
+	option module.Option
+	input  textinput.Model
+}
+
+// optionsModel permite editar as opcoes dos modulos selecionados antes da
+// execucao. Os valores so sao aplicados (via module.SetOptions) no ENTER,
+// depois de todos validarem.
+type optionsModel struct {
+	fields []optionField
+	cursor int
+	errs   map[int]string // erro de validacao por campo
+	done   bool
+}
+
+// newOptionsModel cria um campo por opcao dos modulos configuraveis,
+// preenchido com o valor atual (padrao, config.toml ou --set).
+func newOptionsModel(modules []module.Module) optionsModel {
+	var fields []optionField
+	for _, m := range modules {
+		c, ok := m.(module.Configurable)
+		if !ok {
+			continue
+		}
+		values := c.OptionValues()
+		for _, opt := range c.OptionSchema() {
+			input := textinput.New()
+			input.Prompt = ""
+			input.CharLimit = 256
+			input.SetValue(fmt.Sprint(values[opt.Key]))
+			fields = append(fields, optionField{mod: c, name: m.Name(), option: opt, input: input})
+		}
+	}
+
+	m := optionsModel{fields: fields, errs: make(map[int]string)}
+	m.focus(0)
+	return m
+}
+
+// empty indica que nenhum modulo selecionado tem opcoes (a tela e pulada).
+func (m optionsModel) empty() bool { return len(m.fields) == 0 }
+
+func (m optionsModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *optionsModel) focus(i int) {
+	if len(m.fields) == 0 {
+		return
+	}
+	m.fields[m.cursor].input.Blur()
+	m.cursor = (i + len(m.fields)) % len(m.fields)
+	m.fields[m.cursor].input.Focus()
+}
+
+func (m optionsModel) Update(msg tea.Msg) (optionsModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "up", "shift+tab":
+			m.focus(m.cursor - 1)
+			return m, nil
+		case "down", "tab":
+			m.focus(m.cursor + 1)
+			return m, nil
+		case "enter":
+			if m.apply() {
+				m.done = true
+			}
+			return m, nil
+		case "esc":
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.fields[m.cursor].input, cmd = m.fields[m.cursor].input.Update(msg)
+	delete(m.errs, m.cursor)
+	return m, cmd
+}
+
+// apply valida todos os campos e, se nao houver erro, configura os modulos.
+func (m *optionsModel) apply() bool {
+	m.errs = make(map[int]string)
+
+	// Agrupa os valores por modulo, preservando a ordem dos campos
+	raw := make(map[module.Configurable]map[string]any)
+	var order []module.Configurable
+	for i, f := range m.fields {
+		if _, err := f.option.Parse(f.input.Value()); err != nil {
+			m.errs[i] = err.Error()
+			continue
+		}
+		if raw[f.mod] == nil {
+			raw[f.mod] = make(map[string]any)
+			order = append(order, f.mod)
+		}
+		raw[f.mod][f.option.Key] = f.input.Value()
+	}
+	if len(m.errs) > 0 {
+		m.focus(m.firstError())
+		return false
+	}
+
+	for _, c := range order {
+		if err := module.SetOptions(c, raw[c]); err != nil {
+			m.errs[m.cursor] = err.Error()
+			return false
+		}
+	}
+	return true
+}
+
+func (m optionsModel) firstError() int {
+	for i := range m.fields {
+		if _, ok := m.errs[i]; ok {
+			return i
+		}
+	}
+	return m.cursor
+}
+
+func (m optionsModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Opcoes dos modulos"))
+	b.WriteString("\n\n")
+
+	current := ""
+	for i, f := range m.fields {
+		if f.name != current {
+			if current != "" {
+				b.WriteString("\n")
+			}
+			b.WriteString(highlightStyle.Render(f.name))
+			b.WriteString("\n")
+			current = f.name
+		}
+
+		cursor := "  "
+		if i == m.cursor {
+			cursor = highlightStyle.Render("> ")
+		}
+
+		hint := f.option.Type.String()
+		if len(f.option.Choices) > 0 {
+			hint = strings.Join(f.option.Choices, "|")
+		}
+
+		b.WriteString(fmt.Sprintf("%s%s = %s %s\n", cursor, f.option.Key, f.input.View(), mutedStyle.Render("("+hint+")")))
+		if f.option.Description != "" {
+			b.WriteString(mutedStyle.Render("    " + f.option.Description))
+			b.WriteString("\n")
+		}
+		if err, ok := m.errs[i]; ok {
+			b.WriteString(errorStyle.Render("    " + err))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("TAB/setas para navegar, ENTER para confirmar, ESC para sair"))
+
+	return boxStyle.Render(b.String())
+}
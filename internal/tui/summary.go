@@ -1,36 +1,65 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/ale/blueprint/internal/history"
+	"github.com/ale/blueprint/internal/module"
 	"github.com/ale/blueprint/internal/orchestrator"
+	"github.com/ale/blueprint/internal/session"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // summaryModel mostra o resumo final da execucao.
 type summaryModel struct {
+	action    action
 	results   []orchestrator.Result
 	done      bool
 	hasErrors bool
+	cancelled bool   // execucao cancelada: o resumo e parcial
+	logPath   string // arquivo de log da execucao ("" se nao ha)
+	session   []session.Requirement
+	previous  []history.Run // fases anteriores de um apply retomado
 }
 
+// pendingMsg traz as acoes de sessao pendentes detectadas apos a execucao.
+type pendingMsg []module.SessionRequest
+
 func newSummaryModel(results []orchestrator.Result) summaryModel {
-	hasErrors := false
+	m := summaryModel{results: results, session: session.Collect(results, nil)}
 	for _, r := range results {
 		if r.Err != nil {
-			hasErrors = true
-			break
+			m.hasErrors = true
+		}
+		if r.Cancelled {
+			m.cancelled = true
 		}
 	}
-	return summaryModel{results: results, hasErrors: hasErrors}
+	return m
 }
 
 func (m summaryModel) Init() tea.Cmd {
 	return nil
 }
 
+// detectPending roda pending em background (ex: rpm-ostree status) para
+// nao travar a tela do resumo.
+func (m summaryModel) detectPending(ctx context.Context, pending func(context.Context) []module.SessionRequest) tea.Cmd {
+	if pending == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return pendingMsg(pending(ctx))
+	}
+}
+
 func (m summaryModel) Update(msg tea.Msg) (summaryModel, tea.Cmd) {
+	if reqs, ok := msg.(pendingMsg); ok {
+		m.session = session.Collect(m.results, reqs)
+		return m, nil
+	}
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch keyMsg.String() {
 		case "enter", "q", "esc":
@@ -43,17 +72,45 @@ func (m summaryModel) Update(msg tea.Msg) (summaryModel, tea.Cmd) {
 func (m summaryModel) View() string {
 	var b strings.Builder
 
-	if m.hasErrors {
+	if m.cancelled {
+		b.WriteString(titleStyle.Render("Cancelado (resumo parcial)"))
+	} else if m.hasErrors {
 		b.WriteString(titleStyle.Render("Concluido com erros"))
 	} else {
 		b.WriteString(titleStyle.Render("Concluido!"))
 	}
 	b.WriteString("\n\n")
 
+	// Apply retomado: resultados das fases anteriores, depois a atual
+	for i, run := range m.previous {
+		b.WriteString(highlightStyle.Render(fmt.Sprintf("Fase %d (%s):", i+1, run.ID)))
+		b.WriteString("\n")
+		for _, e := range run.Entries {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("  %s — %s", e.Module, e.Outcome())))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	if len(m.previous) > 0 {
+		b.WriteString(highlightStyle.Render(fmt.Sprintf("Fase %d:", len(m.previous)+1)))
+		b.WriteString("\n")
+	}
+
+	var deferred bool
 	for _, r := range m.results {
 		var icon, status string
 
 		switch {
+		case r.Cancelled:
+			icon = warningStyle.Render("[CANC]")
+			status = warningStyle.Render(r.Reason)
+		case r.TimedOut:
+			icon = errorStyle.Render("[TIME]")
+			status = errorStyle.Render(r.Err.Error())
+		case r.Deferred:
+			deferred = true
+			icon = warningStyle.Render("[ADIA]")
+			status = warningStyle.Render(r.Reason)
 		case r.Skipped:
 			icon = warningStyle.Render("[SKIP]")
 			status = mutedStyle.Render(r.Reason)
@@ -63,13 +120,27 @@ func (m summaryModel) View() string {
 		case r.Applied:
 			icon = successStyle.Render("[OK]  ")
 			status = successStyle.Render("aplicado")
+		case r.Reverted:
+			icon = successStyle.Render("[OK]  ")
+			status = successStyle.Render("removido")
+		case m.action == actionRemove:
+			icon = successStyle.Render("[OK]  ")
+			status = mutedStyle.Render("nada a remover")
 		default:
 			icon = successStyle.Render("[OK]  ")
 			status = mutedStyle.Render("ja instalado")
 		}
+		if n := len(r.Retries); n > 0 {
+			status += mutedStyle.Render(fmt.Sprintf(" (%d nova(s) tentativa(s))", n))
+		}
 
 		b.WriteString(fmt.Sprintf("  %s %s — %s\n", icon, r.Module.Name(), status))
 	}
+	if deferred {
+		b.WriteString("\n")
+		b.WriteString(warningStyle.Render("Os modulos adiados serao aplicados apos o reboot (blueprint apply --resume)."))
+		b.WriteString("\n")
+	}
 
 	// Notas pos-apply (instrucoes importantes para o usuario)
 	var allNotes []string
@@ -85,6 +156,25 @@ func (m summaryModel) View() string {
 		}
 	}
 
+	if len(m.session) > 0 {
+		b.WriteString("\n")
+		b.WriteString(highlightStyle.Render("Acoes de sessao pendentes:"))
+		b.WriteString("\n")
+		for _, r := range m.session {
+			b.WriteString(fmt.Sprintf("  %s\n", warningStyle.Render(r.Action.String())))
+			for _, src := range r.Sources {
+				b.WriteString(mutedStyle.Render("    " + src.String()))
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	if m.logPath != "" {
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("Log: " + m.logPath))
+		b.WriteString("\n")
+	}
+
 	b.WriteString("\n")
 	b.WriteString(mutedStyle.Render("Pressione ENTER ou q para sair"))
 